@@ -4,6 +4,7 @@
 package builder
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -18,13 +19,18 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/roadrunner-server/velox/v3"
 	"github.com/roadrunner-server/velox/v3/builder/templates"
+	"github.com/roadrunner-server/velox/v3/internal/telemetry"
 	"github.com/roadrunner-server/velox/v3/logger"
 	"github.com/roadrunner-server/velox/v3/plugin"
 )
 
+var tracer = otel.Tracer("github.com/roadrunner-server/velox/v3/builder")
+
 const (
 	executableName = "rr"
 	pluginsRelPath = "container/plugins.go"
@@ -51,15 +57,63 @@ type Builder struct {
 	excludes   []velox.Exclude
 	debug      bool
 	race       bool
+	tags       []string
 	rrVersion  string
 	goos       string
 	goarch     string
+
+	// Extra go build flags; see WithBuildFlags.
+	ldflagsVars []string
+	gcflags     []string
+	pgo         string
+	pgoProfile  []byte
+	// pgoDigest is the sha256 of the profile installed as default.pgo.
+	pgoDigest string
+	buildmode string
+	trimpath  bool
+
+	// cgo settings; see WithCGOConfig.
+	cgo        bool
+	static     bool
+	ctoolchain velox.CToolchain
+
+	// Go toolchain selection; see WithGoVersion. goBinary is resolved from
+	// goToolchainDir and goActual is what `go env GOVERSION` reported.
+	goVersion      string
+	goToolchainDir string
+	goBinary       string
+	goActual       string
+
+	// reproducible pins the build time and environment to sourceDate; see
+	// WithReproducible.
+	reproducible bool
+	sourceDate   time.Time
+
+	// serveSmoke extends the smoke test with `rr serve`; see
+	// WithServeSmokeTest.
+	serveSmoke bool
+	// execWrapper runs foreign-arch binaries; see WithExecWrapper.
+	execWrapper string
+
+	// buildTime is fixed once per Build so the ldflags value and the
+	// manifest agree.
+	buildTime string
+	// resolved maps plugin module names to the version tidy selected.
+	resolved map[string]string
+	// report accumulates stage timings and the outcome of Build.
+	report *Report
+	// smokeOutput is what the smoke test binaries printed.
+	smokeOutput string
+	// cpuTime adds up the CPU time of the go commands Build ran.
+	cpuTime time.Duration
+	// sandbox isolates the go commands; see WithSandbox.
+	sandbox *sandbox
 }
 
 // NewBuilder creates a Builder rooted at the directory containing the
 // downloaded RoadRunner source tree.
 func NewBuilder(rrTmpPath string, opts ...Option) *Builder {
-	b := &Builder{rrTempPath: rrTmpPath, log: logger.Discard()}
+	b := &Builder{rrTempPath: rrTmpPath, log: logger.Discard(), trimpath: true}
 	for _, opt := range opts {
 		opt(b)
 	}
@@ -68,9 +122,21 @@ func NewBuilder(rrTmpPath string, opts ...Option) *Builder {
 
 // Build orchestrates the full produce-binary pipeline. It returns the path to
 // the final binary in the configured output directory, or an error wrapping
-// the failing stage and (when available) the last 8 KB of stderr.
-func (b *Builder) Build(ctx context.Context, rrRef string) (string, error) {
-	if err := b.validateInputs(); err != nil {
+// the failing stage and (when available) the last 8 KB of stderr. A Manifest
+// describing the build is written next to the binary; Report describes the
+// run, successful or not.
+func (b *Builder) Build(ctx context.Context, rrRef string) (_ string, retErr error) {
+	ctx, span := tracer.Start(ctx, "Build", trace.WithAttributes(
+		telemetry.AttrRRRef.String(rrRef),
+		telemetry.AttrPluginCount.Int(len(b.plugins)),
+		telemetry.AttrTargetOS.String(cmp.Or(b.goos, goosFromRuntime())),
+		telemetry.AttrTargetArch.String(cmp.Or(b.goarch, goarchFromRuntime())),
+	))
+	defer func() { telemetry.End(span, retErr) }()
+
+	b.report = &Report{RRRef: rrRef}
+	b.cpuTime = 0
+	if err := b.stage(ctx, "validateInputs", b.validateInputs); err != nil {
 		return "", err
 	}
 
@@ -84,41 +150,65 @@ func (b *Builder) Build(ctx context.Context, rrRef string) (string, error) {
 	b.log.Info("RoadRunner major version", "ref", rrRef, "major", major)
 
 	plugin.ResolvePrefixCollisions(b.plugins)
+	b.buildTime = b.buildTimestampFor()
+	b.resolved = make(map[string]string, len(b.plugins))
 
 	defer b.cleanupOutputDir()
+	defer b.sandbox.removeDir()
 
-	if err := b.writePluginsGo(); err != nil {
-		return "", fmt.Errorf("writePluginsGo: %w", err)
-	}
-	if err := b.applyRequires(ctx); err != nil {
-		return "", fmt.Errorf("applyRequires: %w", err)
-	}
-	if err := b.applyReplaces(ctx); err != nil {
-		return "", fmt.Errorf("applyReplaces: %w", err)
+	if err := b.prepareModule(ctx); err != nil {
+		return "", err
 	}
-	if err := b.applyExcludes(ctx); err != nil {
-		return "", fmt.Errorf("applyExcludes: %w", err)
+	if err := b.stage(ctx, "verifyResolvedVersions", func() error { return b.verifyResolvedVersions(ctx) }); err != nil {
+		return "", err
 	}
-	if err := b.goModTidy(ctx); err != nil {
-		return "", fmt.Errorf("go mod tidy: %w", err)
+	if err := b.stage(ctx, "installPGOProfile", b.installPGOProfile); err != nil {
+		return "", err
 	}
-	if err := b.verifyResolvedVersions(ctx); err != nil {
-		return "", fmt.Errorf("verifyResolvedVersions: %w", err)
+	var builtPath, finalPath string
+	if err := b.stage(ctx, "compile", func() (err error) {
+		builtPath, err = b.compile(ctx)
+		return err
+	}); err != nil {
+		return "", err
 	}
-	builtPath, err := b.compile(ctx)
-	if err != nil {
-		return "", fmt.Errorf("compile: %w", err)
+	if err := b.stage(ctx, "relocate", func() (err error) {
+		finalPath, err = b.relocate(builtPath)
+		return err
+	}); err != nil {
+		return "", err
 	}
-	finalPath, err := b.relocate(builtPath)
-	if err != nil {
-		return "", fmt.Errorf("relocate: %w", err)
+	if err := b.stage(ctx, "writeManifest", func() error { return b.writeManifest(finalPath) }); err != nil {
+		return "", err
 	}
-	if err := b.smokeTest(ctx, finalPath); err != nil {
-		return "", fmt.Errorf("smokeTest: %w", err)
+	b.report.binary = finalPath
+	if err := b.stage(ctx, "smokeTest", func() error { return b.smokeTest(ctx, finalPath) }); err != nil {
+		return "", err
 	}
 	return finalPath, nil
 }
 
+// prepareModule is the tidy stage shared by Build and Resolve: check the go
+// toolchain, render plugins.go, apply requires/replaces/excludes, and run `go mod tidy`.
+func (b *Builder) prepareModule(ctx context.Context) error {
+	for _, s := range []struct {
+		name string
+		fn   func() error
+	}{
+		{"checkGoToolchain", func() error { return b.checkGoToolchain(ctx) }},
+		{"writePluginsGo", b.writePluginsGo},
+		{"applyRequires", func() error { return b.applyRequires(ctx) }},
+		{"applyReplaces", func() error { return b.applyReplaces(ctx) }},
+		{"applyExcludes", func() error { return b.applyExcludes(ctx) }},
+		{"go mod tidy", func() error { return b.goModTidy(ctx) }},
+	} {
+		if err := b.stage(ctx, s.name, s.fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (b *Builder) validateInputs() error {
 	if len(b.plugins) == 0 {
 		return errors.New("no plugins provided; use WithPlugins to add at least one")
@@ -132,6 +222,12 @@ func (b *Builder) validateInputs() error {
 	if strings.EqualFold(b.goos, "windows") {
 		return errors.New("velox v3 does not support Windows targets")
 	}
+	if err := b.checkCGO(); err != nil {
+		return err
+	}
+	if err := b.checkReproducible(); err != nil {
+		return err
+	}
 	return b.ensureOutputDir()
 }
 
@@ -192,14 +288,14 @@ func (b *Builder) writePluginsGo() error {
 // version), we surface an actionable error instead of building a binary that
 // silently uses a different plugin version than the user asked for.
 //
-// `tag = "latest"` is treated as "whatever tidy resolves" — no check.
+// `tag = "latest"` is treated as "whatever tidy resolves" — no check, but the
+// resolved version is still recorded for the build manifest.
 func (b *Builder) verifyResolvedVersions(ctx context.Context) error {
 	for _, p := range b.plugins {
-		if p.Tag() == "" || p.Tag() == "latest" {
+		if p.Tag() == "" {
 			continue
 		}
-		res, err := runCmd(ctx, b.log, b.rrTempPath, b.env(),
-			"go", "list", "-m", "-json", p.ModuleName())
+		res, err := b.goRun(ctx, "list", "-m", "-json", p.ModuleName())
 		if err != nil {
 			return fmt.Errorf("go list -m %s: %w", p.ModuleName(), err)
 		}
@@ -210,6 +306,10 @@ func (b *Builder) verifyResolvedVersions(ctx context.Context) error {
 		if err := json.Unmarshal(res.Stdout, &mod); err != nil {
 			return fmt.Errorf("parse go list output for %s: %w", p.ModuleName(), err)
 		}
+		b.resolved[p.ModuleName()] = mod.Version
+		if p.Tag() == "latest" {
+			continue
+		}
 		if mod.Version != "" && mod.Version != p.Tag() {
 			return fmt.Errorf(
 				"plugin %s resolved to %s (you requested %s); use a [[replaces]] entry to force this version",
@@ -228,15 +328,36 @@ func (b *Builder) verifyResolvedVersions(ctx context.Context) error {
 // earlier one, so the release-mode `-s -w` strip flags must be folded into
 // the same flag value as the version-injection symbols.
 func (b *Builder) compile(ctx context.Context) (string, error) {
-	args := []string{"build", "-v", "-trimpath"}
-	if b.debug {
-		args = append(args, "-gcflags", "all=-N -l", "-tags", "debug")
+	args := []string{"build", "-v"}
+	if b.trimpath {
+		args = append(args, "-trimpath")
+	}
+	if b.reproducible {
+		args = append(args, "-buildvcs=false")
+	}
+	if b.buildmode != "" {
+		args = append(args, "-buildmode="+b.buildmode)
+	}
+	if pgo := b.pgoFlag(); pgo != "" {
+		args = append(args, "-pgo="+pgo)
+	}
+	if gc := b.gcflagsValue(); gc != "" {
+		args = append(args, "-gcflags", gc)
+	}
+	if tags := b.buildTags(); len(tags) > 0 {
+		args = append(args, "-tags", strings.Join(tags, ","))
 	}
 	if b.race {
 		args = append(args, "-race")
 	}
 
-	ldParts := []string{fmt.Sprintf(ldflagsFmt, b.rrVersion, buildTimestamp())}
+	ldParts := []string{fmt.Sprintf(ldflagsFmt, b.rrVersion, b.buildTime)}
+	for _, v := range b.ldflagsVars {
+		ldParts = append(ldParts, "-X", v)
+	}
+	if b.static {
+		ldParts = append(ldParts, "-linkmode", "external", "-extldflags", "-static")
+	}
 	if !b.debug {
 		ldParts = append(ldParts, "-s", "-w")
 	}
@@ -245,12 +366,42 @@ func (b *Builder) compile(ctx context.Context) (string, error) {
 	outPath := filepath.Join(b.rrTempPath, executableName)
 	args = append(args, "-o", outPath, rrMainGo)
 
-	if _, err := runCmd(ctx, b.log, b.rrTempPath, b.env(), "go", args...); err != nil {
+	if _, err := b.goRunOffline(ctx, args...); err != nil {
 		return "", err
 	}
 	return outPath, nil
 }
 
+// gcflagsValue returns the single -gcflags value: the debug profile's -N -l
+// followed by the WithGcflags flags, all applied to every package.
+func (b *Builder) gcflagsValue() string {
+	var flags []string
+	if b.debug {
+		flags = append(flags, "-N", "-l")
+	}
+	flags = append(flags, b.gcflags...)
+	if len(flags) == 0 {
+		return ""
+	}
+	return "all=" + strings.Join(flags, " ")
+}
+
+// buildTags returns the deduplicated -tags list: "debug" for debug builds
+// followed by the WithTags tags in order. Like -ldflags, -tags must be passed
+// only once.
+func (b *Builder) buildTags() []string {
+	var tags []string
+	if b.debug {
+		tags = append(tags, "debug")
+	}
+	for _, t := range b.tags {
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
 func (b *Builder) relocate(srcBin string) (string, error) {
 	dst := filepath.Join(b.outputDir, executableName)
 	b.log.Info("moving binary", "from", srcBin, "to", dst)
@@ -260,31 +411,70 @@ func (b *Builder) relocate(srcBin string) (string, error) {
 	return dst, nil
 }
 
-// smokeTest invokes `./rr --version` on the freshly-built binary when the host
-// platform matches the target. Cross-compiled binaries are not exercised.
+// smokeTest invokes `./rr --version` on the freshly-built binary, followed by
+// serveSmokeTest when enabled. Foreign-arch Linux binaries run through the
+// exec wrapper or a binfmt_misc handler; see smokeArgv. The binary runs the
+// plugins' code, so it gets the sandbox when one is configured; a required
+// sandbox that is unavailable skips the smoke test.
 func (b *Builder) smokeTest(ctx context.Context, binPath string) error {
-	hostOS, hostArch := goosFromRuntime(), goarchFromRuntime()
-	if b.goos != "" && b.goos != hostOS {
-		b.log.Info("skipping smoke test (cross-compiled)",
-			"target_os", b.goos, "host_os", hostOS)
-		return nil
+	argv, err := b.smokeArgv(binPath)
+	if err != nil || argv == nil {
+		return err
 	}
-	if b.goarch != "" && b.goarch != hostArch {
-		b.log.Info("skipping smoke test (cross-compiled)",
-			"target_arch", b.goarch, "host_arch", hostArch)
+	if b.sandbox != nil && b.sandbox.cfg.Required && !b.sandbox.active() {
+		b.log.Warn("skipping smoke test (sandbox is required but unavailable)", "error", probeSandbox())
 		return nil
 	}
 
+	dir, err := os.MkdirTemp("", "velox-smoke-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
 	smokeCtx, cancel := context.WithTimeout(ctx, smokeTimeout)
 	defer cancel()
-	out, err := exec.CommandContext(smokeCtx, binPath, "--version").CombinedOutput()
+	cmd, cleanup, err := b.smokeCommand(smokeCtx, dir, argv, "--version")
 	if err != nil {
-		return fmt.Errorf("`%s --version` failed: %w\n%s", binPath, err, out)
+		return err
 	}
+	out, err := cmd.CombinedOutput()
+	cleanup()
+	if err != nil {
+		return fmt.Errorf("`%s --version` failed: %w\n%s", strings.Join(argv, " "), err, out)
+	}
+	b.smokeOutput = string(out)
 	b.log.Info("smoke test passed", "version", string(out))
+	if b.serveSmoke {
+		return b.serveSmokeTest(ctx, dir, argv)
+	}
 	return nil
 }
 
+// smokeCommand returns the command running argv with args in dir. It
+// inherits only smokeEnvKeys from the environment, so the server's
+// credentials stay out of the plugins' reach, and runs offline in the
+// sandbox when one is configured.
+func (b *Builder) smokeCommand(ctx context.Context, dir string, argv []string, args ...string) (*exec.Cmd, func(), error) {
+	cmd := exec.CommandContext(ctx, argv[0], append(argv[1:], args...)...)
+	cmd.Dir = dir
+	cmd.Env = []string{}
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if slices.Contains(smokeEnvKeys, key) || strings.HasPrefix(key, "QEMU_") {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	if b.sandbox == nil {
+		return cmd, func() {}, nil
+	}
+	cleanup, err := b.sandbox.prepare(cmd, b.log, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("smoke test: %w", err)
+	}
+	return cmd, cleanup, nil
+}
+
 // cleanupOutputDir removes leftover roadrunner-server* dirs in the output
 // directory so the next build starts from a clean slate.
 func (b *Builder) cleanupOutputDir() {
@@ -300,20 +490,42 @@ func (b *Builder) cleanupOutputDir() {
 
 // env composes the subprocess environment, inheriting from the parent (so
 // GOPROXY, GOPRIVATE, GOFLAGS, etc. are preserved) and overlaying our
-// target-platform / cgo / GOPATH settings.
+// target-platform / toolchain / cgo / GOPATH settings. With a sandbox only
+// sandboxEnvKeys are inherited, so the code being built cannot read the
+// server's credentials.
 func (b *Builder) env() []string {
 	env := slices.Clone(os.Environ())
+	if b.sandbox != nil {
+		env = slices.DeleteFunc(env, func(kv string) bool {
+			key, _, _ := strings.Cut(kv, "=")
+			return !slices.Contains(sandboxEnvKeys, key)
+		})
+	}
 	if b.goos != "" {
 		env = setKV(env, "GOOS", b.goos)
 	}
 	if b.goarch != "" {
 		env = setKV(env, "GOARCH", b.goarch)
 	}
-	if b.race {
+	if tc := b.goToolchainEnv(); tc != "" {
+		env = setKV(env, "GOTOOLCHAIN", tc)
+	}
+	if b.cgoEnabled() {
 		env = setKV(env, "CGO_ENABLED", "1")
+		for _, kv := range [][2]string{
+			{"CC", b.ctoolchain.CC},
+			{"CXX", b.ctoolchain.CXX},
+			{"CGO_CFLAGS", b.ctoolchain.CFlags},
+			{"CGO_LDFLAGS", b.ctoolchain.LDFlags},
+		} {
+			if kv[1] != "" {
+				env = setKV(env, kv[0], kv[1])
+			}
+		}
 	} else {
 		env = setKV(env, "CGO_ENABLED", "0")
 	}
+	env = b.reproducibleEnv(env)
 	if home, err := os.UserHomeDir(); err == nil && b.goos != "" && b.goarch != "" {
 		gopath := filepath.Join(home, "go", b.goos, b.goarch)
 		env = setKV(env, "GOPATH", gopath)
@@ -322,6 +534,38 @@ func (b *Builder) env() []string {
 	return env
 }
 
+// cgoEnabled reports whether the build needs cgo: explicitly enabled, or
+// implied by the race detector.
+func (b *Builder) cgoEnabled() bool { return b.cgo || b.race }
+
+// checkCGO fails before any go command runs when a cgo build cannot work:
+// static linking for a non-Linux target, a cross-compile without a cross C
+// compiler, or a configured compiler that is not installed.
+func (b *Builder) checkCGO() error {
+	if b.static && !b.cgoEnabled() {
+		return errors.New("static linking requires cgo")
+	}
+	if !b.cgoEnabled() {
+		return nil
+	}
+	hostOS, hostArch := goosFromRuntime(), goarchFromRuntime()
+	goos, goarch := cmp.Or(b.goos, hostOS), cmp.Or(b.goarch, hostArch)
+	if err := velox.CheckCGOTarget(goos, goarch, hostOS, hostArch, &b.ctoolchain, b.static); err != nil {
+		return err
+	}
+	for _, tool := range []string{b.ctoolchain.CC, b.ctoolchain.CXX} {
+		if tool == "" {
+			continue
+		}
+		// CC may carry arguments ("zig cc -target ..."); only the program
+		// itself has to be on PATH.
+		if _, err := exec.LookPath(strings.Fields(tool)[0]); err != nil {
+			return fmt.Errorf("cgo C compiler %q: %w", tool, err)
+		}
+	}
+	return nil
+}
+
 // setKV replaces (or appends) "KEY=value" in env.
 func setKV(env []string, key, value string) []string {
 	prefix := key + "="
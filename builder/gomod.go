@@ -23,6 +23,9 @@ type runResult struct {
 	Stdout []byte
 	// Stderr is the last stderrCaptureLimit bytes of stderr (older bytes dropped).
 	Stderr []byte
+	// CPUTime is the user plus system time of the process and the children
+	// it waited for, e.g. the compilers `go build` runs.
+	CPUTime time.Duration
 }
 
 // runCmd executes name with args in dir under env, honoring ctx for cancellation.
@@ -32,12 +35,15 @@ type runResult struct {
 // On ctx.Done(): SIGINT is sent immediately; if the process hasn't exited within
 // gracefulKillTimeout, it is killed.
 //
+// prepare, when non-nil, adjusts the command before it starts (the
+// sandbox) and returns a cleanup run once it has exited.
+//
 // name is parameterized (rather than hard-coded to "go") so tests can inject a
 // fake `go` script via PATH manipulation.
 //
 //nolint:unparam // name is intentionally pluggable for test fakes
 func runCmd(ctx context.Context, log *slog.Logger, dir string, env []string,
-	name string, args ...string,
+	prepare func(*exec.Cmd) (func(), error), name string, args ...string,
 ) (runResult, error) {
 	if log != nil {
 		log.Info("executing command",
@@ -58,6 +64,13 @@ func runCmd(ctx context.Context, log *slog.Logger, dir string, env []string,
 	} else {
 		cmd.Stderr = stderr
 	}
+	if prepare != nil {
+		cleanup, err := prepare(cmd)
+		if err != nil {
+			return runResult{}, err
+		}
+		defer cleanup()
+	}
 
 	if err := cmd.Start(); err != nil {
 		return runResult{}, fmt.Errorf("starting %s: %w", name, err)
@@ -68,10 +81,9 @@ func runCmd(ctx context.Context, log *slog.Logger, dir string, env []string,
 
 	select {
 	case err := <-doneCh:
-		res := runResult{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+		res := runResult{Stdout: stdout.Bytes(), Stderr: stderr.Bytes(), CPUTime: cpuTime(cmd)}
 		if err != nil {
-			return res, fmt.Errorf("%s failed: %w\n--- stderr (last %d bytes) ---\n%s",
-				name, err, len(res.Stderr), res.Stderr)
+			return res, &CmdError{Name: name, Err: err, Stderr: res.Stderr}
 		}
 		return res, nil
 	case <-ctx.Done():
@@ -83,10 +95,57 @@ func runCmd(ctx context.Context, log *slog.Logger, dir string, env []string,
 			_ = cmd.Process.Kill()
 			<-doneCh
 		}
-		return runResult{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}, ctx.Err()
+		return runResult{Stdout: stdout.Bytes(), Stderr: stderr.Bytes(), CPUTime: cpuTime(cmd)}, ctx.Err()
+	}
+}
+
+// cpuTime is the CPU time an exited command consumed.
+func cpuTime(cmd *exec.Cmd) time.Duration {
+	if cmd.ProcessState == nil {
+		return 0
+	}
+	return cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()
+}
+
+// goRun runs the go command with args inside b.rrTempPath, sandboxed when
+// configured, and adds its CPU time to the build's total.
+func (b *Builder) goRun(ctx context.Context, args ...string) (runResult, error) {
+	return b.runGo(ctx, false, args...)
+}
+
+// goRunOffline is goRun for `go build`, which compiles the modules being
+// built and may run their cgo toolchain: in the sandbox it gets no network
+// and a read-only module cache.
+func (b *Builder) goRunOffline(ctx context.Context, args ...string) (runResult, error) {
+	return b.runGo(ctx, true, args...)
+}
+
+func (b *Builder) runGo(ctx context.Context, offline bool, args ...string) (runResult, error) {
+	var prepare func(*exec.Cmd) (func(), error)
+	if b.sandbox != nil {
+		prepare = func(cmd *exec.Cmd) (func(), error) { return b.sandbox.prepare(cmd, b.log, offline) }
 	}
+	res, err := runCmd(ctx, b.log, b.rrTempPath, b.env(), prepare, b.goCmd(), args...)
+	b.cpuTime += res.CPUTime
+	return res, err
+}
+
+// CmdError is returned by a failed subprocess. Its message embeds the
+// captured stderr tail; Stderr keeps it for callers such as the build report.
+type CmdError struct {
+	Name string
+	Err  error
+	// Stderr is the last stderrCaptureLimit bytes of the command's stderr.
+	Stderr []byte
 }
 
+func (e *CmdError) Error() string {
+	return fmt.Sprintf("%s failed: %v\n--- stderr (last %d bytes) ---\n%s",
+		e.Name, e.Err, len(e.Stderr), e.Stderr)
+}
+
+func (e *CmdError) Unwrap() error { return e.Err }
+
 // ringBuffer keeps at most capacity bytes; older bytes are dropped on overflow.
 type ringBuffer struct {
 	mu       sync.Mutex
@@ -124,8 +183,7 @@ func (w *slogDebugWriter) Write(p []byte) (int, error) {
 
 // goModEdit runs `go mod edit args...` inside b.rrTempPath.
 func (b *Builder) goModEdit(ctx context.Context, args ...string) error {
-	_, err := runCmd(ctx, b.log, b.rrTempPath, b.env(),
-		"go", append([]string{"mod", "edit"}, args...)...)
+	_, err := b.goRun(ctx, append([]string{"mod", "edit"}, args...)...)
 	return err
 }
 
@@ -133,7 +191,7 @@ func (b *Builder) goModEdit(ctx context.Context, args ...string) error {
 // errors from replace directives that reference modules not yet present in the
 // module cache — important because we apply replaces before tidy.
 func (b *Builder) goModTidy(ctx context.Context) error {
-	_, err := runCmd(ctx, b.log, b.rrTempPath, b.env(), "go", "mod", "tidy", "-e")
+	_, err := b.goRun(ctx, "mod", "tidy", "-e")
 	return err
 }
 
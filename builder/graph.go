@@ -0,0 +1,69 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/roadrunner-server/velox/v3/internal/telemetry"
+	"github.com/roadrunner-server/velox/v3/plugin"
+)
+
+// Module is one entry of the resolved module graph, as reported by
+// `go list -m -json all`.
+type Module struct {
+	Path    string
+	Version string
+	Replace *Module
+	// Main is set for the RoadRunner module itself.
+	Main bool
+}
+
+// Resolve runs only the tidy stage of Build and returns the resulting module
+// graph, without compiling anything. It is used to preview the effect of a
+// config change (`vx diff`) and leaves the RR source tree modified in place.
+func (b *Builder) Resolve(ctx context.Context) (_ []Module, retErr error) {
+	ctx, span := tracer.Start(ctx, "Resolve", trace.WithAttributes(telemetry.AttrPluginCount.Int(len(b.plugins))))
+	defer func() { telemetry.End(span, retErr) }()
+
+	if len(b.plugins) == 0 {
+		return nil, errors.New("no plugins provided; use WithPlugins to add at least one")
+	}
+	if b.rrTempPath == "" {
+		return nil, errors.New("RR source path is empty")
+	}
+
+	plugin.ResolvePrefixCollisions(b.plugins)
+	defer b.sandbox.removeDir()
+	if err := b.prepareModule(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := b.goRun(ctx, "list", "-m", "-json", "all")
+	if err != nil {
+		return nil, fmt.Errorf("go list -m all: %w", err)
+	}
+	return parseModuleList(res.Stdout)
+}
+
+// parseModuleList decodes the stream of JSON objects printed by
+// `go list -m -json`.
+func parseModuleList(data []byte) ([]Module, error) {
+	var out []Module
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var m Module
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return out, nil
+			}
+			return nil, fmt.Errorf("parse go list output: %w", err)
+		}
+		out = append(out, m)
+	}
+}
@@ -0,0 +1,129 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// manifestSuffix is appended to the binary path to name its build manifest.
+const manifestSuffix = ".manifest.json"
+
+// Manifest records what a velox build was asked to produce. Build writes it
+// next to the binary so `vx inspect` can check a binary against its inputs,
+// including the values injected through ldflagsFmt, which cannot be read
+// back from stripped release binaries.
+type Manifest struct {
+	RRVersion string `json:"rr_version"`
+	BuildTime string `json:"build_time"`
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+	// GoVersion is the toolchain that compiled the binary and GoPinned the
+	// go_version it was pinned to, if any.
+	GoVersion string   `json:"go_version,omitempty"`
+	GoPinned  string   `json:"go_pinned,omitempty"`
+	Debug     bool     `json:"debug"`
+	Race      bool     `json:"race"`
+	Tags      []string `json:"tags,omitempty"`
+	CGO       bool     `json:"cgo,omitempty"`
+	Static    bool     `json:"static,omitempty"`
+	// Reproducible is set for builds made with WithReproducible.
+	Reproducible bool `json:"reproducible,omitempty"`
+	// Trimpath is nil in manifests written before it was configurable,
+	// when -trimpath was always on.
+	Trimpath  *bool  `json:"trimpath,omitempty"`
+	Buildmode string `json:"buildmode,omitempty"`
+	Gcflags   string `json:"gcflags,omitempty"`
+	// PGO is "auto", "off" or, for an installed profile, "sha256:<digest>".
+	PGO         string           `json:"pgo,omitempty"`
+	LdflagsVars []string         `json:"ldflags_vars,omitempty"`
+	Plugins     []ManifestPlugin `json:"plugins"`
+	Replaces    []ManifestRepl   `json:"replaces,omitempty"`
+	Excludes    []ManifestModule `json:"excludes,omitempty"`
+}
+
+// ManifestPlugin is a user plugin as requested and as resolved by tidy.
+// Resolved is empty when the resolved version could not be determined.
+type ManifestPlugin struct {
+	Module   string `json:"module"`
+	Tag      string `json:"tag"`
+	Resolved string `json:"resolved,omitempty"`
+}
+
+// ManifestRepl mirrors a go.mod replace directive.
+type ManifestRepl struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// ManifestModule is a module@version pair.
+type ManifestModule struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+}
+
+// ManifestPath returns where Build stores the manifest of the binary at binPath.
+func ManifestPath(binPath string) string { return binPath + manifestSuffix }
+
+// ReadManifest loads a manifest written by Build.
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// manifest describes the current build.
+func (b *Builder) manifest() *Manifest {
+	m := &Manifest{
+		RRVersion:    b.rrVersion,
+		BuildTime:    b.buildTime,
+		GOOS:         b.goos,
+		GOARCH:       b.goarch,
+		GoVersion:    b.goActual,
+		GoPinned:     b.goVersion,
+		Debug:        b.debug,
+		Race:         b.race,
+		Tags:         b.buildTags(),
+		CGO:          b.cgoEnabled(),
+		Reproducible: b.reproducible,
+		Static:       b.static,
+		Trimpath:     new(b.trimpath),
+		Buildmode:    b.buildmode,
+		Gcflags:      b.gcflagsValue(),
+		PGO:          b.pgoFlag(),
+		LdflagsVars:  b.ldflagsVars,
+		Plugins:      make([]ManifestPlugin, 0, len(b.plugins)),
+	}
+	if b.pgoDigest != "" {
+		m.PGO = "sha256:" + b.pgoDigest
+	}
+	for _, p := range b.plugins {
+		m.Plugins = append(m.Plugins, ManifestPlugin{
+			Module:   p.ModuleName(),
+			Tag:      p.Tag(),
+			Resolved: b.resolved[p.ModuleName()],
+		})
+	}
+	for _, r := range b.replaces {
+		m.Replaces = append(m.Replaces, ManifestRepl{Old: r.Old, New: r.New})
+	}
+	for _, e := range b.excludes {
+		m.Excludes = append(m.Excludes, ManifestModule{Module: e.Module, Version: e.Version})
+	}
+	return m
+}
+
+// writeManifest stores the manifest next to binPath.
+func (b *Builder) writeManifest(binPath string) error {
+	data, err := json.MarshalIndent(b.manifest(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ManifestPath(binPath), data, 0o600)
+}
@@ -1,7 +1,10 @@
 package builder
 
 import (
+	"cmp"
 	"log/slog"
+	"path/filepath"
+	"time"
 
 	"github.com/roadrunner-server/velox/v3"
 	"github.com/roadrunner-server/velox/v3/plugin"
@@ -56,6 +59,47 @@ func WithGOARCH(goarch string) Option {
 	return func(b *Builder) { b.goarch = goarch }
 }
 
+// WithGoVersion pins the Go release used for every go command, e.g.
+// "1.26.1". It is selected through GOTOOLCHAIN unless WithGoToolchainDir
+// provides it; either way Build checks it before tidy.
+func WithGoVersion(v string) Option {
+	return func(b *Builder) { b.goVersion = velox.GoToolchainName(v) }
+}
+
+// WithGoToolchainDir sets a directory of installed toolchains laid out as
+// <dir>/go<version>/bin/go. The pinned release is taken from there instead
+// of being downloaded through GOTOOLCHAIN.
+func WithGoToolchainDir(dir string) Option {
+	return func(b *Builder) { b.goToolchainDir = dir }
+}
+
+// WithReproducible builds reproducibly: the build time and
+// SOURCE_DATE_EPOCH come from sourceDate (the RR commit time) instead of the
+// clock, VCS stamping is off and -trimpath is required. The caller keeps the
+// source tree at a fixed path.
+func WithReproducible(sourceDate time.Time) Option {
+	return func(b *Builder) {
+		b.reproducible = true
+		b.sourceDate = sourceDate
+	}
+}
+
+// WithServeSmokeTest extends the post-build smoke test: besides
+// `rr --version`, start `rr serve` with a generated config for the
+// compiled-in plugins that need no external services, wait until they are
+// ready and shut it down gracefully.
+func WithServeSmokeTest(enabled bool) Option {
+	return func(b *Builder) { b.serveSmoke = enabled }
+}
+
+// WithExecWrapper sets the command template that runs a foreign-arch Linux
+// binary for the smoke test, e.g. "qemu-aarch64-static -L /usr/aarch64-linux-gnu".
+// velox.BinaryPlaceholder marks the binary's position; otherwise it is
+// appended. See velox.Smoke.
+func WithExecWrapper(tmpl string) Option {
+	return func(b *Builder) { b.execWrapper = tmpl }
+}
+
 // WithDebug toggles the debug build profile (no inlining, no optimization, debug tag).
 func WithDebug(debug bool) Option {
 	return func(b *Builder) { b.debug = debug }
@@ -65,3 +109,119 @@ func WithDebug(debug bool) Option {
 func WithRace(race bool) Option {
 	return func(b *Builder) { b.race = race }
 }
+
+// WithTags adds build tags passed to `go build -tags`, on top of the debug tag
+// a debug build adds.
+func WithTags(tags ...string) Option {
+	return func(b *Builder) { b.tags = append(b.tags, tags...) }
+}
+
+// WithLdflagsVars adds `-X importpath.name=value` assignments to the single
+// combined -ldflags value. Callers validate them with velox.ValidateLdflagsVar.
+func WithLdflagsVars(vars ...string) Option {
+	return func(b *Builder) { b.ldflagsVars = append(b.ldflagsVars, vars...) }
+}
+
+// WithGcflags adds compiler flags applied to all packages, merged with the
+// debug profile's -N -l into a single -gcflags=all=... value.
+func WithGcflags(flags ...string) Option {
+	return func(b *Builder) { b.gcflags = append(b.gcflags, flags...) }
+}
+
+// WithPGO sets `-pgo`: "auto", "off", or the path of a CPU profile, which
+// Build copies into the RR tree as cmd/rr/default.pgo. Relative paths are
+// resolved against the current working directory.
+func WithPGO(pgo string) Option {
+	return func(b *Builder) {
+		if pgo != "" && pgo != velox.PGOAuto && pgo != velox.PGOOff {
+			if abs, err := filepath.Abs(pgo); err == nil {
+				pgo = abs
+			}
+		}
+		b.pgo = pgo
+	}
+}
+
+// WithPGOProfile sets the content of the PGO profile directly, e.g. one
+// uploaded with a BuildRequest. It takes precedence over WithPGO.
+func WithPGOProfile(data []byte) Option {
+	return func(b *Builder) { b.pgoProfile = data }
+}
+
+// WithBuildmode sets `-buildmode` ("exe" or "pie").
+func WithBuildmode(mode string) Option {
+	return func(b *Builder) { b.buildmode = mode }
+}
+
+// WithTrimpath toggles `-trimpath`, which is on by default.
+func WithTrimpath(trimpath bool) Option {
+	return func(b *Builder) { b.trimpath = trimpath }
+}
+
+// WithBuildFlags applies a [build] config section: tags, -X vars, gcflags,
+// PGO, buildmode and trimpath. A nil section is a no-op.
+func WithBuildFlags(flags *velox.Build) Option {
+	return func(b *Builder) {
+		if flags == nil {
+			return
+		}
+		for _, opt := range []Option{
+			WithTags(flags.Tags...),
+			WithLdflagsVars(flags.Vars...),
+			WithGcflags(flags.Gcflags...),
+			WithPGO(flags.PGO),
+			WithBuildmode(flags.Buildmode),
+			WithTrimpath(flags.TrimpathEnabled()),
+		} {
+			opt(b)
+		}
+	}
+}
+
+// WithCGO enables cgo (CGO_ENABLED=1). The race detector enables it on its
+// own.
+func WithCGO(enabled bool) Option {
+	return func(b *Builder) { b.cgo = enabled }
+}
+
+// WithCToolchain sets CC, CXX, CGO_CFLAGS and CGO_LDFLAGS for cgo builds.
+// Empty fields leave the inherited environment alone. A nil toolchain is
+// ignored.
+func WithCToolchain(tc *velox.CToolchain) Option {
+	return func(b *Builder) {
+		if tc != nil {
+			b.ctoolchain = *tc
+		}
+	}
+}
+
+// WithStaticLink links cgo binaries statically
+// (-linkmode external -extldflags -static).
+func WithStaticLink(static bool) Option {
+	return func(b *Builder) { b.static = static }
+}
+
+// WithSandbox runs the go commands of the build in a sandbox configured by
+// a [sandbox] section; see velox.Sandbox. nil or disabled leaves them
+// unsandboxed. The smoke test runs the built binary in it too, offline.
+func WithSandbox(c *velox.Sandbox) Option {
+	return func(b *Builder) {
+		if c != nil && c.Enabled {
+			b.sandbox = &sandbox{cfg: *c}
+		}
+	}
+}
+
+// WithCGOConfig applies a [cgo] config section for the builder's target
+// platform. Apply it after WithGOOS / WithGOARCH.
+func WithCGOConfig(c *velox.CGO) Option {
+	return func(b *Builder) {
+		if c == nil {
+			return
+		}
+		b.cgo = c.Enabled
+		b.static = c.Static
+		goos, goarch := cmp.Or(b.goos, goosFromRuntime()), cmp.Or(b.goarch, goarchFromRuntime())
+		WithCToolchain(c.Toolchain(goos, goarch))(b)
+	}
+}
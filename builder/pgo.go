@@ -0,0 +1,72 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/pprof/profile"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+// pgoRelPath is where `go build -pgo=auto` looks for the main package's
+// profile.
+const pgoRelPath = "cmd/rr/default.pgo"
+
+// ValidatePGOProfile checks that data is a pprof profile the compiler can
+// use for PGO.
+func ValidatePGOProfile(data []byte) error {
+	p, err := profile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("parse pgo profile: %w", err)
+	}
+	if len(p.Sample) == 0 {
+		return fmt.Errorf("pgo profile has no samples")
+	}
+	return nil
+}
+
+// PGODigest returns the hex sha256 of a PGO profile, the form recorded in
+// the manifest and used in cache keys.
+func PGODigest(data []byte) string { return sha256Hex(data) }
+
+// installPGOProfile copies the configured profile (an uploaded one, or the
+// file named by WithPGO) into the RR tree as cmd/rr/default.pgo, so the
+// build picks it up with -pgo=auto. It is a no-op for the auto/off modes.
+func (b *Builder) installPGOProfile() error {
+	data := b.pgoProfile
+	if data == nil {
+		if b.pgo == "" || b.pgo == velox.PGOAuto || b.pgo == velox.PGOOff {
+			return nil
+		}
+		var err error
+		if data, err = os.ReadFile(b.pgo); err != nil {
+			return err
+		}
+	}
+	if err := ValidatePGOProfile(data); err != nil {
+		return err
+	}
+
+	dst := filepath.Join(b.rrTempPath, pgoRelPath)
+	if err := os.WriteFile(dst, data, 0o600); err != nil {
+		return err
+	}
+	b.pgoDigest = PGODigest(data)
+	b.log.Info("installed pgo profile", "path", dst, "sha256", b.pgoDigest)
+	return nil
+}
+
+// pgoFlag returns the -pgo value: auto once a profile is installed,
+// otherwise the configured mode.
+func (b *Builder) pgoFlag() string {
+	if b.pgoDigest != "" {
+		return velox.PGOAuto
+	}
+	if b.pgo == velox.PGOAuto || b.pgo == velox.PGOOff {
+		return b.pgo
+	}
+	return ""
+}
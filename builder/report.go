@@ -0,0 +1,117 @@
+package builder
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/roadrunner-server/velox/v3/internal/telemetry"
+)
+
+// Report describes one Build run for machine consumption (`vx build
+// --report`). Unlike the Manifest, it is produced for failed builds too.
+type Report struct {
+	Success   bool   `json:"success"`
+	RRRef     string `json:"rr_ref"`
+	Platform  string `json:"platform"`
+	GoVersion string `json:"go_version,omitempty"`
+	// Plugins carry the version tidy resolved, once known.
+	Plugins  []ManifestPlugin `json:"plugins"`
+	Replaces []ManifestRepl   `json:"replaces,omitempty"`
+	Excludes []ManifestModule `json:"excludes,omitempty"`
+	Stages   []StageTiming    `json:"stages"`
+	// DurationMS is the sum of all stage durations.
+	DurationMS int64 `json:"duration_ms"`
+	// CPUTimeMS is the CPU time of the go commands the build ran.
+	CPUTimeMS   int64          `json:"cpu_time_ms"`
+	Binary      *ReportBinary  `json:"binary,omitempty"`
+	SmokeOutput string         `json:"smoke_output,omitempty"`
+	Failure     *ReportFailure `json:"failure,omitempty"`
+
+	// binary is the final binary's path, recorded once it is in place.
+	binary string
+}
+
+// StageTiming is how long one Build stage took.
+type StageTiming struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// ReportBinary identifies the produced binary.
+type ReportBinary struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ReportFailure is the stage a failed build stopped at.
+type ReportFailure struct {
+	Stage string `json:"stage"`
+	Error string `json:"error"`
+	// Stderr is the captured stderr tail of the failing command, if the
+	// stage failed in a subprocess.
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// stage runs fn as the named Build stage in a child span of ctx: it records
+// the duration and, on failure, the stage in the report, and wraps the error
+// with the name.
+func (b *Builder) stage(ctx context.Context, name string, fn func() error) (err error) {
+	_, span := tracer.Start(ctx, name)
+	defer func() { telemetry.End(span, err) }()
+
+	start := time.Now()
+	err = fn()
+	if b.report == nil {
+		// Resolve runs stages without a report.
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+	b.report.Stages = append(b.report.Stages, StageTiming{Name: name, DurationMS: time.Since(start).Milliseconds()})
+	if err != nil {
+		err = fmt.Errorf("%s: %w", name, err)
+		f := &ReportFailure{Stage: name, Error: err.Error()}
+		if ce, ok := errors.AsType[*CmdError](err); ok {
+			f.Stderr = string(ce.Stderr)
+		}
+		b.report.Failure = f
+	}
+	return err
+}
+
+// Report returns the report of the last Build call, or nil before the
+// first one.
+func (b *Builder) Report() *Report {
+	if b.report == nil {
+		return nil
+	}
+	r := *b.report
+	m := b.manifest()
+	r.Success = r.Failure == nil
+	r.Platform = cmp.Or(b.goos, goosFromRuntime()) + "/" + cmp.Or(b.goarch, goarchFromRuntime())
+	r.GoVersion = b.goActual
+	r.Plugins, r.Replaces, r.Excludes = m.Plugins, m.Replaces, m.Excludes
+	for _, s := range r.Stages {
+		r.DurationMS += s.DurationMS
+	}
+	r.CPUTimeMS = b.cpuTime.Milliseconds()
+	r.SmokeOutput = b.smokeOutput
+	if r.binary != "" {
+		r.Binary = describeBinary(r.binary)
+	}
+	return &r
+}
+
+func describeBinary(path string) *ReportBinary {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &ReportBinary{Path: path}
+	}
+	return &ReportBinary{Path: path, Size: int64(len(data)), SHA256: sha256Hex(data)}
+}
@@ -0,0 +1,64 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roadrunner-server/velox/v3/plugin"
+)
+
+func TestReport_FailedStage(t *testing.T) {
+	b := NewBuilder(t.TempDir(),
+		WithPlugins(plugin.NewPlugin("github.com/roadrunner-server/logger/v5", "v5.0.2")),
+		WithOutputDir(filepath.Join(t.TempDir(), "out")),
+		WithGOOS("linux"),
+		WithGOARCH("arm64"),
+	)
+	assert.Nil(t, b.Report(), "no report before Build")
+
+	// The source dir has no go.mod, so the toolchain check fails.
+	_, err := b.Build(context.Background(), "v2025.1.2")
+	require.Error(t, err)
+
+	r := b.Report()
+	require.NotNil(t, r)
+	assert.False(t, r.Success)
+	assert.Equal(t, "v2025.1.2", r.RRRef)
+	assert.Equal(t, "linux/arm64", r.Platform)
+	require.NotNil(t, r.Failure)
+	assert.Equal(t, "checkGoToolchain", r.Failure.Stage)
+	assert.Equal(t, err.Error(), r.Failure.Error)
+	require.Len(t, r.Stages, 2)
+	assert.Equal(t, []string{"validateInputs", "checkGoToolchain"}, []string{r.Stages[0].Name, r.Stages[1].Name})
+	require.Len(t, r.Plugins, 1)
+	assert.Equal(t, "github.com/roadrunner-server/logger/v5", r.Plugins[0].Module)
+	assert.Nil(t, r.Binary)
+}
+
+func TestReport_CommandStderr(t *testing.T) {
+	b := &Builder{report: &Report{}}
+	err := b.stage(t.Context(), "go mod tidy", func() error {
+		return &CmdError{Name: "go", Err: errors.New("exit status 1"), Stderr: []byte("go: module not found")}
+	})
+	require.Error(t, err)
+	require.NotNil(t, b.report.Failure)
+	assert.Equal(t, "go mod tidy", b.report.Failure.Stage)
+	assert.Equal(t, "go: module not found", b.report.Failure.Stderr)
+}
+
+func TestRunCmd_CPUTime(t *testing.T) {
+	res, err := runCmd(t.Context(), nil, t.TempDir(), nil, nil,
+		"sh", "-c", "i=0; while [ $i -lt 200000 ]; do i=$((i+1)); done")
+	require.NoError(t, err)
+	assert.Positive(t, res.CPUTime, "the busy loop's CPU time is recorded")
+
+	res, err = runCmd(t.Context(), nil, t.TempDir(), nil, nil,
+		"sh", "-c", "i=0; while [ $i -lt 200000 ]; do i=$((i+1)); done; exit 1")
+	require.Error(t, err)
+	assert.Positive(t, res.CPUTime, "failed commands are charged too")
+}
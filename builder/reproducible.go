@@ -0,0 +1,152 @@
+package builder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"debug/elf"
+	"debug/macho"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// reproducibleEnv sets SOURCE_DATE_EPOCH for tools that honor it (C
+// compilers in cgo builds) when building reproducibly.
+func (b *Builder) reproducibleEnv(env []string) []string {
+	if !b.reproducible {
+		return env
+	}
+	return setKV(env, "SOURCE_DATE_EPOCH", strconv.FormatInt(b.sourceDate.Unix(), 10))
+}
+
+// checkReproducible rejects settings that would embed the build directory
+// in a binary that is supposed to be reproducible.
+func (b *Builder) checkReproducible() error {
+	if !b.reproducible {
+		return nil
+	}
+	if b.sourceDate.IsZero() {
+		return errors.New("reproducible build needs a source date")
+	}
+	if !b.trimpath {
+		return errors.New("reproducible builds require -trimpath")
+	}
+	return nil
+}
+
+// buildTimestampFor returns the RFC3339 build time: the source date in
+// reproducible mode, otherwise buildTimestamp.
+func (b *Builder) buildTimestampFor() string {
+	if b.reproducible {
+		return b.sourceDate.UTC().Format(time.RFC3339)
+	}
+	return buildTimestamp()
+}
+
+// Comparison is the result of CompareBinaries.
+type Comparison struct {
+	SHA256A string
+	SHA256B string
+	// Sections lists, for differing binaries of a known format (ELF or
+	// Mach-O), every section that differs and how.
+	Sections []string
+}
+
+// Equal reports whether both binaries are byte-for-byte identical.
+func (c *Comparison) Equal() bool { return c.SHA256A == c.SHA256B }
+
+// CompareBinaries hashes the binaries at a and b and, when they differ,
+// lists the sections that differ between them.
+func CompareBinaries(a, b string) (*Comparison, error) {
+	da, err := os.ReadFile(a)
+	if err != nil {
+		return nil, err
+	}
+	db, err := os.ReadFile(b)
+	if err != nil {
+		return nil, err
+	}
+	c := &Comparison{SHA256A: sha256Hex(da), SHA256B: sha256Hex(db)}
+	if c.Equal() {
+		return c, nil
+	}
+
+	sa, errA := sectionDigests(da)
+	sb, errB := sectionDigests(db)
+	if errA != nil || errB != nil {
+		// Unknown format: the digests are all there is to report.
+		return c, nil //nolint:nilerr
+	}
+	names := slices.AppendSeq(slices.Collect(maps.Keys(sa)), maps.Keys(sb))
+	slices.Sort(names)
+	names = slices.Compact(names)
+	for _, n := range names {
+		x, inA := sa[n]
+		y, inB := sb[n]
+		switch {
+		case !inB:
+			c.Sections = append(c.Sections, n+": only in first binary")
+		case !inA:
+			c.Sections = append(c.Sections, n+": only in second binary")
+		case x.size != y.size:
+			c.Sections = append(c.Sections, fmt.Sprintf("%s: size %d != %d", n, x.size, y.size))
+		case x.digest != y.digest:
+			c.Sections = append(c.Sections, n+": content differs")
+		}
+	}
+	if len(c.Sections) == 0 {
+		// Same sections, so the headers or padding between them differ.
+		c.Sections = []string{"headers: content differs"}
+	}
+	return c, nil
+}
+
+type sectionDigest struct {
+	size   uint64
+	digest string
+}
+
+// sectionDigests returns the size and sha256 of every section of an ELF or
+// Mach-O binary, keyed by section name (segment,section for Mach-O).
+func sectionDigests(data []byte) (map[string]sectionDigest, error) {
+	out := map[string]sectionDigest{}
+	if f, err := elf.NewFile(bytes.NewReader(data)); err == nil {
+		for _, s := range f.Sections {
+			if s.Type == elf.SHT_NULL {
+				continue
+			}
+			d := sectionDigest{size: s.Size}
+			if s.Type != elf.SHT_NOBITS {
+				d.digest = readerDigest(s.Open())
+			}
+			out[s.Name] = d
+		}
+		return out, nil
+	}
+	if f, err := macho.NewFile(bytes.NewReader(data)); err == nil {
+		for _, s := range f.Sections {
+			out[s.Seg+","+s.Name] = sectionDigest{size: s.Size, digest: readerDigest(s.Open())}
+		}
+		return out, nil
+	}
+	return nil, errors.New("not an ELF or Mach-O binary")
+}
+
+func readerDigest(r io.Reader) string {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "unreadable: " + err.Error()
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package builder
+
+import (
+	"bytes"
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// elfFixture copies the running test binary, an ELF file on Linux, into
+// two files for CompareBinaries.
+func elfFixture(t *testing.T) (a, b string, data []byte) {
+	t.Helper()
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	data, err = os.ReadFile(exe)
+	require.NoError(t, err)
+	if _, err := elf.NewFile(bytes.NewReader(data)); err != nil {
+		t.Skipf("the test binary is not ELF: %v", err)
+	}
+	dir := t.TempDir()
+	a, b = filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	require.NoError(t, os.WriteFile(a, data, 0o600))
+	require.NoError(t, os.WriteFile(b, data, 0o600))
+	return a, b, data
+}
+
+func TestCompareBinaries(t *testing.T) {
+	a, b, data := elfFixture(t)
+
+	c, err := CompareBinaries(a, b)
+	require.NoError(t, err)
+	assert.True(t, c.Equal())
+	assert.Empty(t, c.Sections)
+
+	f, err := elf.NewFile(bytes.NewReader(data))
+	require.NoError(t, err)
+	rodata := f.Section(".rodata")
+	require.NotNil(t, rodata)
+	changed := append([]byte(nil), data...)
+	changed[rodata.Offset] ^= 0xff
+	require.NoError(t, os.WriteFile(b, changed, 0o600))
+
+	c, err = CompareBinaries(a, b)
+	require.NoError(t, err)
+	assert.False(t, c.Equal())
+	assert.NotEqual(t, c.SHA256A, c.SHA256B)
+	assert.Equal(t, []string{".rodata: content differs"}, c.Sections)
+}
+
+func TestSectionDigests_UnknownFormat(t *testing.T) {
+	_, err := sectionDigests([]byte("#!/bin/sh\necho rr\n"))
+	require.EqualError(t, err, "not an ELF or Mach-O binary")
+
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	require.NoError(t, os.WriteFile(a, []byte("one"), 0o600))
+	require.NoError(t, os.WriteFile(b, []byte("two"), 0o600))
+	c, err := CompareBinaries(a, b)
+	require.NoError(t, err)
+	assert.False(t, c.Equal())
+	assert.Empty(t, c.Sections, "only the digests are reported for unknown formats")
+}
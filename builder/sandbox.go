@@ -0,0 +1,178 @@
+package builder
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+// sandboxEnv carries the sandboxSpec to the re-executed binary. Its
+// presence turns the process into the sandbox init (see sandbox_linux.go)
+// before main runs.
+const sandboxEnv = "VELOX_SANDBOX_INIT"
+
+// sandboxExitCode is the exit status of a sandbox init that failed to set
+// up; its stderr says why.
+const sandboxExitCode = 126
+
+// sandboxEnvKeys are the environment variables sandboxed go commands
+// inherit: the go command's own settings, the C toolchain's, and what
+// module downloads need to reach a proxy.
+var sandboxEnvKeys = []string{
+	"PATH", "HOME", "USER", "LANG", "LC_ALL", "TZ", "TMPDIR", "SOURCE_DATE_EPOCH",
+	"GOROOT", "GOPATH", "GOBIN", "GOCACHE", "GOMODCACHE", "GOTMPDIR", "GOENV", "GOFLAGS",
+	"GOTOOLCHAIN", "GOEXPERIMENT", "GODEBUG", "GOTELEMETRY", "GOFIPS140",
+	"GO386", "GOAMD64", "GOARM", "GOARM64", "GOMIPS", "GOMIPS64", "GOPPC64", "GORISCV64", "GOWASM",
+	"GOPROXY", "GOPRIVATE", "GONOPROXY", "GONOSUMDB", "GOSUMDB", "GOINSECURE", "GOVCS", "GOAUTH",
+	"CC", "CXX", "AR", "PKG_CONFIG", "PKG_CONFIG_PATH",
+	"CGO_CFLAGS", "CGO_CPPFLAGS", "CGO_CXXFLAGS", "CGO_LDFLAGS",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+}
+
+// sandboxSpec is what the sandbox init needs to set up and run a command.
+type sandboxSpec struct {
+	// Path and Args are the command to exec once the sandbox is in place.
+	Path string   `json:"path"`
+	Args []string `json:"args"`
+	// Writable stay writable; the rest of the file system is read-only.
+	Writable []string `json:"writable"`
+	// Private holds the private GOCACHE and temp dir, on a tmpfs of
+	// TmpfsMB when that is set.
+	Private string `json:"private"`
+	TmpfsMB int    `json:"tmpfs_mb,omitempty"`
+	// Resource limits applied with setrlimit.
+	CPUSeconds     int `json:"cpu_seconds,omitempty"`
+	AddressSpaceMB int `json:"address_space_mb,omitempty"`
+	FileSizeMB     int `json:"file_size_mb,omitempty"`
+	// Probe sets the sandbox up and exits instead of running a command.
+	Probe bool `json:"probe,omitempty"`
+}
+
+// sandbox runs the go commands of a Builder isolated from the host; see
+// WithSandbox.
+type sandbox struct {
+	cfg velox.Sandbox
+	// dir is the private GOCACHE/temp root, created on first use.
+	dir      string
+	warnOnce sync.Once
+}
+
+// prepare rewrites cmd to run in the sandbox. offline commands also get no
+// network and a read-only module cache. Where the sandbox is unavailable
+// cmd is left alone, or prepare fails when the sandbox is required.
+func (s *sandbox) prepare(cmd *exec.Cmd, log *slog.Logger, offline bool) (cleanup func(), err error) {
+	cleanup = func() {}
+	if err := probeSandbox(); err != nil {
+		if s.cfg.Required {
+			return nil, fmt.Errorf("sandbox is required but unavailable: %w", err)
+		}
+		s.warnOnce.Do(func() { log.Warn("sandbox unavailable; running go commands unsandboxed", "error", err) })
+		return cleanup, nil
+	}
+	if cmd.Err != nil {
+		// The command was not found; Start reports it.
+		return cleanup, nil
+	}
+	if s.dir == "" {
+		if s.dir, err = os.MkdirTemp("", "velox-sandbox-"); err != nil {
+			return nil, fmt.Errorf("sandbox: %w", err)
+		}
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	spec := sandboxSpec{
+		Path:       cmd.Path,
+		Args:       cmd.Args,
+		Writable:   []string{cmd.Dir, s.dir},
+		Private:    s.dir,
+		TmpfsMB:    s.cfg.DiskMB,
+		CPUSeconds: s.cfg.CPUSeconds,
+		FileSizeMB: s.cfg.DiskMB,
+	}
+	if !offline {
+		for _, dir := range moduleCacheDirs(env) {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return nil, fmt.Errorf("sandbox: %w", err)
+			}
+			spec.Writable = append(spec.Writable, dir)
+		}
+	}
+	env = setKV(env, "GOCACHE", filepath.Join(s.dir, "gocache"))
+	env = setKV(env, "GOTMPDIR", filepath.Join(s.dir, "tmp"))
+	env = setKV(env, "TMPDIR", filepath.Join(s.dir, "tmp"))
+	if offline {
+		// Fail fast with a clear error instead of a DNS timeout.
+		env = setKV(env, "GOPROXY", "off")
+	}
+
+	var cg *cgroup
+	if s.cfg.CgroupParent != "" {
+		if cg, err = newCgroup(s.cfg); err != nil {
+			if s.cfg.Required {
+				return nil, fmt.Errorf("sandbox: %w", err)
+			}
+			log.Warn("sandbox cgroup unavailable; cpus and processes are not limited", "error", err)
+			cg = nil
+		}
+	}
+	if cg != nil {
+		cleanup = cg.remove
+	} else {
+		spec.AddressSpaceMB = s.cfg.MemoryMB
+	}
+	if err := isolate(cmd, env, spec, cg, offline); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("sandbox: %w", err)
+	}
+	return cleanup, nil
+}
+
+// active reports whether commands prepared by s run sandboxed.
+func (s *sandbox) active() bool {
+	return s != nil && probeSandbox() == nil
+}
+
+// removeDir deletes the private dir of the finished Build.
+func (s *sandbox) removeDir() {
+	if s == nil || s.dir == "" {
+		return
+	}
+	_ = os.RemoveAll(s.dir)
+	s.dir = ""
+}
+
+// moduleCacheDirs are where the downloading go commands write: the module
+// cache, downloaded toolchains included, and the checksum database cache.
+func moduleCacheDirs(env []string) []string {
+	gopath, _, _ := strings.Cut(lookupKV(env, "GOPATH"), string(os.PathListSeparator))
+	if gopath == "" {
+		home, _ := os.UserHomeDir()
+		gopath = filepath.Join(home, "go")
+	}
+	return []string{
+		cmp.Or(lookupKV(env, "GOMODCACHE"), filepath.Join(gopath, "pkg", "mod")),
+		filepath.Join(gopath, "pkg", "sumdb"),
+	}
+}
+
+// lookupKV returns the value of key in env, the last assignment winning.
+func lookupKV(env []string, key string) string {
+	value := ""
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, key+"="); ok {
+			value = v
+		}
+	}
+	return value
+}
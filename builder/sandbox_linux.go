@@ -0,0 +1,248 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+// init turns a binary re-executed by the sandbox into the sandbox init: it
+// runs in the fresh namespaces, sets up the read-only view and the limits,
+// drops its capabilities and execs the real command. It never returns.
+func init() {
+	data, ok := os.LookupEnv(sandboxEnv)
+	if !ok {
+		return
+	}
+	// Capabilities are per thread: drop them on the thread that execs.
+	runtime.LockOSThread()
+	if err := sandboxInit(data); err != nil {
+		fmt.Fprintln(os.Stderr, "velox sandbox:", err)
+		os.Exit(sandboxExitCode)
+	}
+	os.Exit(0)
+}
+
+func sandboxInit(data string) error {
+	var s sandboxSpec
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return err
+	}
+
+	// Nothing below may propagate back to the host's mount table.
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("making mounts private: %w", err)
+	}
+	if s.TmpfsMB > 0 {
+		if err := unix.Mount("tmpfs", s.Private, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=0700,size="+strconv.Itoa(s.TmpfsMB)+"m"); err != nil {
+			return fmt.Errorf("mounting tmpfs on %s: %w", s.Private, err)
+		}
+	}
+	for _, dir := range []string{"gocache", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(s.Private, dir), 0o700); err != nil {
+			return err
+		}
+	}
+	// Bind the writable dirs onto themselves so they are mounts of their
+	// own, make everything read-only, then lift that for the binds.
+	for _, dir := range s.Writable {
+		if err := unix.Mount(dir, dir, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("binding %s: %w", dir, err)
+		}
+	}
+	if err := unix.MountSetattr(unix.AT_FDCWD, "/", unix.AT_RECURSIVE, &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY}); err != nil {
+		return fmt.Errorf("making / read-only: %w", err)
+	}
+	for _, dir := range s.Writable {
+		rw := &unix.MountAttr{Attr_clr: unix.MOUNT_ATTR_RDONLY}
+		if err := unix.MountSetattr(unix.AT_FDCWD, dir, unix.AT_RECURSIVE, rw); err != nil {
+			// A mount below dir that is read-only on the host stays so.
+			if err := unix.MountSetattr(unix.AT_FDCWD, dir, 0, rw); err != nil {
+				return fmt.Errorf("making %s writable: %w", dir, err)
+			}
+		}
+	}
+	// The working directory was entered before the binds; enter it again
+	// so it resolves to the writable mount on top.
+	if wd, err := os.Getwd(); err == nil {
+		if err := os.Chdir(wd); err != nil {
+			return err
+		}
+	}
+	// A /proc of the new pid namespace hides the host's processes. Where
+	// /proc is masked (nested containers) the kernel refuses; keep the old.
+	_ = unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "")
+
+	if s.Probe {
+		return nil
+	}
+
+	for _, l := range []struct {
+		resource int
+		value    uint64
+	}{
+		{unix.RLIMIT_CPU, uint64(s.CPUSeconds)},
+		{unix.RLIMIT_AS, uint64(s.AddressSpaceMB) << 20},
+		{unix.RLIMIT_FSIZE, uint64(s.FileSizeMB) << 20},
+	} {
+		if l.value == 0 {
+			continue
+		}
+		if err := unix.Setrlimit(l.resource, &unix.Rlimit{Cur: l.value, Max: l.value}); err != nil {
+			return fmt.Errorf("setrlimit: %w", err)
+		}
+	}
+	if err := dropCapabilities(); err != nil {
+		return err
+	}
+
+	env := make([]string, 0, len(os.Environ()))
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, sandboxEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	return syscall.Exec(s.Path, s.Args, env)
+}
+
+// dropCapabilities leaves the command root of its user namespace but
+// without capabilities, so it cannot undo the read-only mounts.
+func dropCapabilities() error {
+	for c := 0; ; c++ {
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0); err != nil {
+			if errors.Is(err, unix.EINVAL) {
+				break // past the last capability
+			}
+			return fmt.Errorf("dropping capabilities: %w", err)
+		}
+	}
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil {
+		return fmt.Errorf("clearing ambient capabilities: %w", err)
+	}
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var caps [2]unix.CapUserData
+	if err := unix.Capget(&hdr, &caps[0]); err != nil {
+		return fmt.Errorf("capget: %w", err)
+	}
+	caps[0].Inheritable, caps[1].Inheritable = 0, 0
+	if err := unix.Capset(&hdr, &caps[0]); err != nil {
+		return fmt.Errorf("capset: %w", err)
+	}
+	return unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0)
+}
+
+// isolate makes cmd re-execute this binary as the sandbox init in new
+// namespaces, and in cg when it is set.
+func isolate(cmd *exec.Cmd, env []string, spec sandboxSpec, cg *cgroup, offline bool) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	cmd.Path = "/proc/self/exe"
+	cmd.Args = append([]string{"velox-sandbox"}, spec.Args...)
+	cmd.Env = setKV(env, sandboxEnv, string(data))
+	cmd.SysProcAttr = sysProcAttr(offline)
+	if cg != nil {
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = cg.fd
+	}
+	return nil
+}
+
+// sysProcAttr clones the command into new user, mount and pid namespaces
+// (and a network one when offline) as root of the user namespace, which
+// maps to the caller's uid and gid.
+func sysProcAttr(offline bool) *syscall.SysProcAttr {
+	flags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID)
+	if offline {
+		flags |= syscall.CLONE_NEWNET
+	}
+	return &syscall.SysProcAttr{
+		Cloneflags:  flags,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+	}
+}
+
+// probeSandbox sets a sandbox up once to find out whether this host
+// permits it (unprivileged user namespaces, mount_setattr).
+var probeSandbox = sync.OnceValue(func() error {
+	dir, err := os.MkdirTemp("", "velox-sandbox-probe-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	data, err := json.Marshal(sandboxSpec{Writable: []string{dir}, Private: dir, Probe: true})
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("/proc/self/exe")
+	cmd.Args = []string{"velox-sandbox-probe"}
+	cmd.Env = append(os.Environ(), sandboxEnv+"="+string(data))
+	cmd.SysProcAttr = sysProcAttr(true)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if out = bytes.TrimSpace(out); len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+})
+
+// cgroup is the cgroup v2 a sandboxed command is started in.
+type cgroup struct {
+	dir string
+	fd  int
+}
+
+// newCgroup creates a child of cfg.CgroupParent with the configured
+// limits. The parent must be delegated to velox with the cpu, memory and
+// pids controllers enabled in its cgroup.subtree_control.
+func newCgroup(cfg velox.Sandbox) (*cgroup, error) {
+	dir, err := os.MkdirTemp(cfg.CgroupParent, "velox-")
+	if err != nil {
+		return nil, fmt.Errorf("creating cgroup: %w", err)
+	}
+	for _, l := range []struct {
+		file  string
+		value int
+		text  string
+	}{
+		{"memory.max", cfg.MemoryMB, strconv.Itoa(cfg.MemoryMB << 20)},
+		{"cpu.max", cfg.CPUs, strconv.Itoa(cfg.CPUs*100000) + " 100000"},
+		{"pids.max", cfg.Processes, strconv.Itoa(cfg.Processes)},
+	} {
+		if l.value == 0 {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, l.file), []byte(l.text), 0); err != nil {
+			_ = os.Remove(dir)
+			return nil, fmt.Errorf("setting %s: %w", l.file, err)
+		}
+	}
+	fd, err := unix.Open(dir, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		_ = os.Remove(dir)
+		return nil, fmt.Errorf("opening cgroup: %w", err)
+	}
+	return &cgroup{dir: dir, fd: fd}, nil
+}
+
+// remove deletes the cgroup once its command has exited.
+func (c *cgroup) remove() {
+	_ = unix.Close(c.fd)
+	_ = os.Remove(c.dir)
+}
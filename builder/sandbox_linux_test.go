@@ -0,0 +1,101 @@
+package builder
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roadrunner-server/velox/v3"
+	"github.com/roadrunner-server/velox/v3/logger"
+)
+
+func TestSandbox(t *testing.T) {
+	if err := probeSandbox(); err != nil {
+		t.Skipf("sandbox unavailable here: %v", err)
+	}
+	work, outside := t.TempDir(), t.TempDir()
+	gopath := t.TempDir()
+	env := append(os.Environ(), "GOPATH="+gopath, "GOMODCACHE=")
+	s := &sandbox{cfg: velox.Sandbox{Enabled: true, DiskMB: 64}}
+	t.Cleanup(s.removeDir)
+
+	run := func(offline bool, script string) (string, error) {
+		t.Helper()
+		prepare := func(cmd *exec.Cmd) (func(), error) { return s.prepare(cmd, logger.Discard(), offline) }
+		res, err := runCmd(t.Context(), nil, work, env, prepare, "sh", "-c", script)
+		return strings.TrimSpace(string(res.Stdout)), err
+	}
+
+	out, err := run(false, `echo ok > built && echo $$ && echo "$GOCACHE" && grep CapEff /proc/self/status`)
+	require.NoError(t, err)
+	lines := strings.Split(out, "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "1", lines[0], "the command is pid 1 of its own pid namespace")
+	assert.Equal(t, filepath.Join(s.dir, "gocache"), lines[1])
+	assert.Regexp(t, `CapEff:\s+0+$`, lines[2], "capabilities are dropped")
+	assert.FileExists(t, filepath.Join(work, "built"), "the work dir is writable")
+
+	_, err = run(false, `echo x > `+filepath.Join(outside, "escape"))
+	require.Error(t, err, "the rest of the file system is read-only")
+	assert.NoFileExists(t, filepath.Join(outside, "escape"))
+
+	_, err = run(false, `touch `+filepath.Join(gopath, "pkg", "mod", "fetched"))
+	require.NoError(t, err, "downloading commands may write the module cache")
+	_, err = run(true, `touch `+filepath.Join(gopath, "pkg", "mod", "built"))
+	require.Error(t, err, "offline commands see a read-only module cache")
+
+	out, err = run(true, `tail -n +3 /proc/net/dev | cut -d: -f1 | tr -d ' '; echo "$GOPROXY"`)
+	require.NoError(t, err)
+	assert.Equal(t, "lo\noff", out, "offline commands get a network namespace with loopback only")
+
+	_, err = run(false, `head -c 70000000 /dev/zero > "$TMPDIR/big"`)
+	require.Error(t, err, "disk_mb caps the private tmpfs")
+}
+
+func TestSmokeTest_Sandboxed(t *testing.T) {
+	if err := probeSandbox(); err != nil {
+		t.Skipf("sandbox unavailable here: %v", err)
+	}
+	t.Setenv("GITHUB_TOKEN", "secret")
+	b := NewBuilder("", WithSandbox(&velox.Sandbox{Enabled: true, Required: true}))
+	t.Cleanup(b.sandbox.removeDir)
+	rr := fakeRR(t, `echo "token=$GITHUB_TOKEN pid=$$"; tail -n +3 /proc/net/dev | cut -d: -f1 | tr -d ' '`)
+	require.NoError(t, b.smokeTest(t.Context(), rr))
+	assert.Equal(t, "token= pid=1\nlo\n", b.smokeOutput, "the binary runs offline in the sandbox, without the server's secrets")
+}
+
+func TestSmokeTest_SkippedWithoutRequiredSandbox(t *testing.T) {
+	old := probeSandbox
+	probeSandbox = func() error { return errors.New("user namespaces are not permitted") }
+	t.Cleanup(func() { probeSandbox = old })
+
+	rr := fakeRR(t, `echo ran; exit 1`)
+	b := NewBuilder("", WithSandbox(&velox.Sandbox{Enabled: true, Required: true}))
+	require.NoError(t, b.smokeTest(t.Context(), rr), "the smoke test is skipped instead of run unsandboxed")
+	assert.Empty(t, b.smokeOutput)
+
+	b = NewBuilder("", WithSandbox(&velox.Sandbox{Enabled: true}))
+	require.Error(t, b.smokeTest(t.Context(), rr), "an optional sandbox falls back to running it unsandboxed")
+}
+
+func TestEnv_SandboxDropsSecrets(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "secret")
+	t.Setenv("VELOX_SERVER_AUTH_TOKENS_CI_TOKEN", "secret")
+	t.Setenv("GOPROXY", "https://proxy.example.com")
+
+	env := NewBuilder("").env()
+	assert.Equal(t, "secret", lookupKV(env, "GITHUB_TOKEN"), "unsandboxed builds inherit everything")
+
+	env = NewBuilder("", WithSandbox(&velox.Sandbox{Enabled: true}), WithGOOS("linux"), WithGOARCH("arm64")).env()
+	assert.Empty(t, lookupKV(env, "GITHUB_TOKEN"))
+	assert.Empty(t, lookupKV(env, "VELOX_SERVER_AUTH_TOKENS_CI_TOKEN"))
+	assert.Equal(t, "https://proxy.example.com", lookupKV(env, "GOPROXY"))
+	assert.NotEmpty(t, lookupKV(env, "PATH"))
+	assert.Equal(t, "arm64", lookupKV(env, "GOARCH"), "velox's own settings still apply")
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package builder
+
+import (
+	"errors"
+	"os/exec"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+var errSandboxUnsupported = errors.New("sandboxing requires Linux namespaces")
+
+func probeSandbox() error { return errSandboxUnsupported }
+
+type cgroup struct{}
+
+func newCgroup(velox.Sandbox) (*cgroup, error) { return nil, errSandboxUnsupported }
+
+func (*cgroup) remove() {}
+
+func isolate(*exec.Cmd, []string, sandboxSpec, *cgroup, bool) error { return errSandboxUnsupported }
@@ -0,0 +1,340 @@
+package builder
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+const (
+	serveReadyTimeout = 30 * time.Second
+	serveStopTimeout  = 10 * time.Second
+	// serveGracePeriod is how long rr must stay up when none of its
+	// compiled-in plugins exposes an endpoint to probe.
+	serveGracePeriod = 3 * time.Second
+	smokeConfigName  = ".rr.yaml"
+	smokeOutputLimit = 8 * 1024
+)
+
+// binfmtDir is where the kernel lists binfmt_misc handlers; a variable so
+// tests can point it elsewhere.
+var binfmtDir = "/proc/sys/fs/binfmt_misc"
+
+// smokeEnvKeys are the environment variables the smoke test passes on to
+// the built binary, along with the QEMU_* ones an exec wrapper may need.
+var smokeEnvKeys = []string{"PATH", "HOME", "USER", "LANG", "LC_ALL", "TZ", "TMPDIR"}
+
+// qemuArch maps GOARCH to the architecture names qemu-user uses for its
+// binaries and binfmt_misc handlers.
+var qemuArch = map[string]string{
+	"386":      "i386",
+	"amd64":    "x86_64",
+	"arm":      "arm",
+	"arm64":    "aarch64",
+	"loong64":  "loongarch64",
+	"mips64le": "mips64el",
+	"ppc64le":  "ppc64le",
+	"riscv64":  "riscv64",
+	"s390x":    "s390x",
+}
+
+// smokeArgv returns the command line prefix that runs binPath on this host:
+// the binary itself for native builds and for foreign-arch Linux binaries the
+// kernel runs through binfmt_misc, or the configured exec wrapper. It returns
+// nil, after logging why, when the binary cannot be run here. A configured
+// wrapper that is not installed is an error.
+func (b *Builder) smokeArgv(binPath string) ([]string, error) {
+	hostOS, hostArch := goosFromRuntime(), goarchFromRuntime()
+	goos, goarch := cmp.Or(b.goos, hostOS), cmp.Or(b.goarch, hostArch)
+	if goos == hostOS && goarch == hostArch {
+		return []string{binPath}, nil
+	}
+	if goos != "linux" || hostOS != "linux" {
+		b.log.Info("skipping smoke test (cross-compiled)", "target", goos+"/"+goarch, "host", hostOS+"/"+hostArch)
+		return nil, nil
+	}
+
+	if b.execWrapper != "" {
+		argv := wrapperArgv(b.execWrapper, binPath)
+		if _, err := exec.LookPath(argv[0]); err != nil {
+			return nil, fmt.Errorf("smoke test exec wrapper for linux/%s: %w", goarch, err)
+		}
+		b.log.Info("running smoke test through exec wrapper", "wrapper", argv[0], "target", goos+"/"+goarch)
+		return argv, nil
+	}
+	if handler, ok := binfmtHandler(goarch); ok {
+		b.log.Info("running smoke test through binfmt_misc", "handler", handler, "target", goos+"/"+goarch)
+		return []string{binPath}, nil
+	}
+	b.log.Warn("skipping smoke test: cannot run foreign-arch binary",
+		"target", goos+"/"+goarch, "host", hostOS+"/"+hostArch,
+		"reason", fmt.Sprintf("no exec wrapper in [smoke.exec_wrappers] for %s and no enabled binfmt_misc handler in %s (install qemu-user-static or register one with binfmt)",
+			velox.PlatformKey(goos, goarch), binfmtDir))
+	return nil, nil
+}
+
+// wrapperArgv expands an exec wrapper template for binPath.
+func wrapperArgv(tmpl, binPath string) []string {
+	argv := strings.Fields(tmpl)
+	for i, a := range argv {
+		if strings.Contains(a, velox.BinaryPlaceholder) {
+			argv[i] = strings.ReplaceAll(a, velox.BinaryPlaceholder, binPath)
+			return argv
+		}
+	}
+	return append(argv, binPath)
+}
+
+// binfmtHandler reports whether binfmt_misc is enabled and has an enabled
+// qemu handler (as registered by qemu-user-static or tonistiigi/binfmt) for
+// goarch, and returns its name.
+func binfmtHandler(goarch string) (string, bool) {
+	arch, ok := qemuArch[goarch]
+	if !ok || !binfmtEnabled(filepath.Join(binfmtDir, "status")) {
+		return "", false
+	}
+	name := "qemu-" + arch
+	return name, binfmtEnabled(filepath.Join(binfmtDir, name))
+}
+
+// binfmtEnabled reports whether a binfmt_misc status or handler file starts
+// with "enabled".
+func binfmtEnabled(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.HasPrefix(string(data), "enabled")
+}
+
+// serveSection is the .rr.yaml section of a plugin that starts without any
+// external service. listen formats its address from a free local port;
+// probe reports whether the plugin answers there.
+type serveSection struct {
+	key    string
+	listen func(addr string) any
+	probe  func(ctx context.Context, addr string) bool
+}
+
+// serveSections maps plugin module paths (without the /vN suffix) to the
+// config the extended smoke test enables them with. Plugins that need a
+// worker command or an external service (http, jobs drivers, kv backends...)
+// are left unconfigured, which makes RoadRunner skip them.
+var serveSections = map[string]serveSection{
+	"github.com/roadrunner-server/rpc": {
+		key:    "rpc",
+		listen: func(addr string) any { return map[string]any{"listen": "tcp://" + addr} },
+		probe:  probeTCP,
+	},
+	"github.com/roadrunner-server/status": {
+		key:    "status",
+		listen: func(addr string) any { return map[string]any{"address": addr} },
+		probe:  probeHTTP("/health"),
+	},
+	"github.com/roadrunner-server/metrics": {
+		key:    "metrics",
+		listen: func(addr string) any { return map[string]any{"address": addr} },
+		probe:  probeHTTP("/metrics"),
+	},
+	"github.com/roadrunner-server/logger": {
+		key:    "logs",
+		listen: func(string) any { return map[string]any{"mode": "development", "level": "debug"} },
+	},
+}
+
+// serveProbe is a started plugin endpoint the smoke test waits for.
+type serveProbe struct {
+	plugin string
+	addr   string
+	probe  func(ctx context.Context, addr string) bool
+}
+
+// smokeConfig renders the .rr.yaml for the extended smoke test and returns
+// the endpoints that signal readiness.
+func (b *Builder) smokeConfig() ([]byte, []serveProbe, error) {
+	cfg := map[string]any{"version": "3"}
+	var probes []serveProbe
+	for _, p := range b.plugins {
+		s, ok := serveSections[trimMajor(p.ModuleName())]
+		if !ok {
+			continue
+		}
+		addr := ""
+		if s.probe != nil {
+			var err error
+			if addr, err = freeAddr(); err != nil {
+				return nil, nil, err
+			}
+			probes = append(probes, serveProbe{plugin: s.key, addr: addr, probe: s.probe})
+		}
+		cfg[s.key] = s.listen(addr)
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, probes, nil
+}
+
+// serveSmokeTest starts `rr serve` in dir with a generated config enabling the
+// compiled-in plugins that need no external services, waits until every
+// probed endpoint answers (or, without any, until rr has stayed up for
+// serveGracePeriod), and stops it with SIGINT. A failure carries rr's output.
+func (b *Builder) serveSmokeTest(ctx context.Context, dir string, argv []string) error {
+	data, probes, err := b.smokeConfig()
+	if err != nil {
+		return fmt.Errorf("generate %s: %w", smokeConfigName, err)
+	}
+	cfgPath := filepath.Join(dir, smokeConfigName)
+	if err := os.WriteFile(cfgPath, data, 0o600); err != nil {
+		return err
+	}
+	if b.sandbox.active() {
+		// rr listens in the sandbox's network namespace, out of the
+		// probes' reach: it only has to stay up.
+		probes = nil
+	}
+
+	output := newRingBuffer(smokeOutputLimit)
+	cmd, cleanup, err := b.smokeCommand(ctx, dir, argv, "serve", "-c", cfgPath, "-w", dir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start `rr serve`: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("`rr serve` %s\n--- %s ---\n%s\n--- output (last %d bytes) ---\n%s",
+			fmt.Sprintf(format, args...), smokeConfigName, data, smokeOutputLimit, output.Bytes())
+	}
+
+	if err := waitReady(ctx, probes, exited); err != nil {
+		if !errors.Is(err, errServeExited) {
+			_ = cmd.Process.Kill()
+			<-exited
+		}
+		return fail("%v", err)
+	}
+
+	_ = cmd.Process.Signal(syscall.SIGINT)
+	select {
+	case err := <-exited:
+		if err != nil {
+			return fail("did not shut down cleanly: %v", err)
+		}
+	case <-time.After(serveStopTimeout):
+		_ = cmd.Process.Kill()
+		<-exited
+		return fail("did not stop within %s of SIGINT", serveStopTimeout)
+	}
+	b.smokeOutput += "\n--- rr serve ---\n" + string(output.Bytes())
+	b.log.Info("serve smoke test passed", "probed", len(probes))
+	return nil
+}
+
+// errServeExited reports that rr exited before it became ready; waitReady
+// has consumed its exit status by then.
+var errServeExited = errors.New("exited during startup")
+
+// waitReady polls every probe until it answers. rr exiting first, or
+// serveReadyTimeout passing, is an error.
+func waitReady(ctx context.Context, probes []serveProbe, exited <-chan error) error {
+	ctx, cancel := context.WithTimeout(ctx, serveReadyTimeout)
+	defer cancel()
+
+	var grace <-chan time.Time
+	if len(probes) == 0 {
+		grace = time.After(serveGracePeriod)
+	}
+	tick := time.NewTicker(200 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case err := <-exited:
+			return fmt.Errorf("%w (%v)", errServeExited, err)
+		case <-grace:
+			return nil
+		case <-ctx.Done():
+			var pending []string
+			for _, p := range probes {
+				pending = append(pending, p.plugin+" ("+p.addr+")")
+			}
+			return fmt.Errorf("not ready after %s; waiting for %s", serveReadyTimeout, strings.Join(pending, ", "))
+		case <-tick.C:
+			remaining := probes[:0]
+			for _, p := range probes {
+				if !p.probe(ctx, p.addr) {
+					remaining = append(remaining, p)
+				}
+			}
+			probes = remaining
+			if len(probes) == 0 && grace == nil {
+				return nil
+			}
+		}
+	}
+}
+
+func probeTCP(ctx context.Context, addr string) bool {
+	conn, err := (&net.Dialer{Timeout: time.Second}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// probeHTTP returns a probe that succeeds once path answers at all; the
+// status code is irrelevant, only that the plugin is serving.
+func probeHTTP(path string) func(ctx context.Context, addr string) bool {
+	return func(ctx context.Context, addr string) bool {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+		if err != nil {
+			return false
+		}
+		resp, err := (&http.Client{Timeout: time.Second}).Do(req)
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return true
+	}
+}
+
+// freeAddr returns a loopback address with a port that was free a moment ago.
+func freeAddr() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = l.Close() }()
+	return l.Addr().String(), nil
+}
+
+// trimMajor strips a /vN major version suffix from a module path.
+func trimMajor(module string) string {
+	i := strings.LastIndex(module, "/v")
+	if i < 0 {
+		return module
+	}
+	if rest := module[i+2:]; rest != "" && strings.Trim(rest, "0123456789") == "" {
+		return module[:i]
+	}
+	return module
+}
@@ -0,0 +1,90 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/roadrunner-server/velox/v3/plugin"
+)
+
+func TestWrapperArgv(t *testing.T) {
+	assert.Equal(t, []string{"qemu-aarch64-static", "-L", "/usr/aarch64-linux-gnu", "/out/rr"},
+		wrapperArgv("qemu-aarch64-static -L /usr/aarch64-linux-gnu", "/out/rr"))
+	assert.Equal(t, []string{"docker", "run", "-v", "/out/rr:/rr", "img", "/rr"},
+		wrapperArgv("docker run -v {binary}:/rr img /rr", "/out/rr"))
+}
+
+func TestBinfmtHandler(t *testing.T) {
+	dir := t.TempDir()
+	old := binfmtDir
+	binfmtDir = dir
+	t.Cleanup(func() { binfmtDir = old })
+
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	_, ok := binfmtHandler("arm64")
+	assert.False(t, ok, "no binfmt_misc at all")
+
+	write("status", "enabled\n")
+	write("qemu-aarch64", "enabled\ninterpreter /usr/bin/qemu-aarch64-static\n")
+	write("qemu-riscv64", "disabled\ninterpreter /usr/bin/qemu-riscv64-static\n")
+
+	name, ok := binfmtHandler("arm64")
+	assert.True(t, ok)
+	assert.Equal(t, "qemu-aarch64", name)
+	_, ok = binfmtHandler("riscv64")
+	assert.False(t, ok, "handler disabled")
+	_, ok = binfmtHandler("s390x")
+	assert.False(t, ok, "handler not registered")
+
+	write("status", "disabled\n")
+	_, ok = binfmtHandler("arm64")
+	assert.False(t, ok, "binfmt_misc disabled")
+}
+
+func TestSmokeConfig(t *testing.T) {
+	b := NewBuilder("", WithPlugins(
+		plugin.NewPlugin("github.com/roadrunner-server/rpc/v5", "v5.0.0"),
+		plugin.NewPlugin("github.com/roadrunner-server/logger/v5", "v5.0.0"),
+		plugin.NewPlugin("github.com/roadrunner-server/http/v5", "v5.0.0"),
+	))
+	data, probes, err := b.smokeConfig()
+	require.NoError(t, err)
+
+	var cfg map[string]any
+	require.NoError(t, yaml.Unmarshal(data, &cfg))
+	assert.Equal(t, "3", cfg["version"])
+	assert.Contains(t, cfg, "rpc")
+	assert.Contains(t, cfg, "logs")
+	assert.NotContains(t, cfg, "http", "http needs workers and is left unconfigured")
+
+	require.Len(t, probes, 1)
+	assert.Equal(t, "rpc", probes[0].plugin)
+	assert.Equal(t, map[string]any{"listen": "tcp://" + probes[0].addr}, cfg["rpc"])
+
+	assert.Equal(t, "github.com/roadrunner-server/rpc", trimMajor("github.com/roadrunner-server/rpc/v5"))
+	assert.Equal(t, "github.com/acme/vendor", trimMajor("github.com/acme/vendor"))
+}
+
+// fakeRR writes an executable shell script standing in for a built rr.
+func fakeRR(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rr")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o700))
+	return path
+}
+
+func TestSmokeTest_StripsEnvironment(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "secret")
+	t.Setenv("QEMU_LD_PREFIX", "/usr/aarch64-linux-gnu")
+	b := NewBuilder("")
+	require.NoError(t, b.smokeTest(t.Context(), fakeRR(t, `echo "token=$GITHUB_TOKEN qemu=$QEMU_LD_PREFIX"`)))
+	assert.Equal(t, "token= qemu=/usr/aarch64-linux-gnu\n", b.smokeOutput)
+}
@@ -91,6 +91,15 @@ var (
 	resetterLineRe = regexp.MustCompile(`(?m)^\s*(github\.com/roadrunner-server/resetter/v\d+)\s+`)
 )
 
+// bundledModuleRe matches the informer/resetter module paths that the
+// template registers on its own, independent of the user plugin set.
+var bundledModuleRe = regexp.MustCompile(`^github\.com/roadrunner-server/(informer|resetter)/v\d+$`)
+
+// IsBundledModule reports whether modulePath is one of the plugins that
+// PluginsTemplate always registers (informer, resetter). Importers use it to
+// avoid listing them twice in a generated velox.toml.
+func IsBundledModule(modulePath string) bool { return bundledModuleRe.MatchString(modulePath) }
+
 // ParseUpstreamModules extracts the full informer and resetter module paths
 // from the bytes of an upstream RoadRunner go.mod. The paths include the /vN
 // major-version suffix so the generated import is bit-exact with what the RR
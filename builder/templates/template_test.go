@@ -95,3 +95,10 @@ require github.com/roadrunner-server/resetter/v6 v6.1.0
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "informer")
 }
+
+func TestIsBundledModule(t *testing.T) {
+	require.True(t, templates.IsBundledModule("github.com/roadrunner-server/informer/v6"))
+	require.True(t, templates.IsBundledModule("github.com/roadrunner-server/resetter/v5"))
+	require.False(t, templates.IsBundledModule("github.com/roadrunner-server/informer"))
+	require.False(t, templates.IsBundledModule("github.com/roadrunner-server/http/v6"))
+}
@@ -0,0 +1,99 @@
+package builder
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/version"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// goCmd returns the go command every stage runs: the pinned toolchain from
+// the local toolchain directory when one was resolved, otherwise "go" from
+// PATH.
+func (b *Builder) goCmd() string {
+	return cmp.Or(b.goBinary, "go")
+}
+
+// goToolchainEnv returns the GOTOOLCHAIN value for the subprocess
+// environment, or "" to inherit the caller's. A pin without a local
+// toolchain directory selects (and, on first use, downloads) exactly that
+// release; a toolchain from the directory must not switch itself.
+func (b *Builder) goToolchainEnv() string {
+	switch {
+	case b.goVersion == "":
+		return ""
+	case b.goBinary != "":
+		return "local"
+	default:
+		return b.goVersion
+	}
+}
+
+// checkGoToolchain runs before tidy. It checks a go_version pin against the
+// go and toolchain directives of RR's go.mod, resolves the pinned toolchain
+// from the local toolchain directory, and then asks the go command which
+// release it actually runs, so a wrong or too old toolchain is reported here
+// instead of as a tidy or compile failure.
+func (b *Builder) checkGoToolchain(ctx context.Context) error {
+	data, err := os.ReadFile(filepath.Join(b.rrTempPath, goModFile))
+	if err != nil {
+		return fmt.Errorf("read upstream go.mod: %w", err)
+	}
+	mf, err := modfile.ParseLax(goModFile, data, nil)
+	if err != nil {
+		return fmt.Errorf("parse upstream go.mod: %w", err)
+	}
+	var required, preferred string
+	if mf.Go != nil {
+		required = "go" + mf.Go.Version
+	}
+	if mf.Toolchain != nil {
+		preferred = mf.Toolchain.Name
+	}
+
+	if b.goVersion != "" {
+		if required != "" && version.Compare(b.goVersion, required) < 0 {
+			return fmt.Errorf("go_version %s is older than the go %s RoadRunner's go.mod requires",
+				strings.TrimPrefix(b.goVersion, "go"), mf.Go.Version)
+		}
+		if preferred != "" && version.Compare(b.goVersion, preferred) < 0 {
+			b.log.Warn("go_version is older than the toolchain RoadRunner's go.mod suggests; building with the pin",
+				"go_version", b.goVersion, "toolchain", preferred)
+		}
+		if b.goToolchainDir != "" {
+			bin := filepath.Join(b.goToolchainDir, b.goVersion, "bin", "go")
+			if _, err := os.Stat(bin); err != nil {
+				return fmt.Errorf("go_version %s is not installed in go_toolchain_dir: %w",
+					strings.TrimPrefix(b.goVersion, "go"), err)
+			}
+			b.goBinary = bin
+		}
+	}
+
+	res, err := b.goRun(ctx, "env", "GOVERSION")
+	if err != nil {
+		return fmt.Errorf("go env GOVERSION: %w", err)
+	}
+	// GOVERSION may carry a " X:experiment" suffix.
+	actual := ""
+	if f := strings.Fields(string(res.Stdout)); len(f) > 0 {
+		actual = f[0]
+	}
+	b.goActual = actual
+
+	switch {
+	case b.goVersion != "" && actual != b.goVersion:
+		return fmt.Errorf("go toolchain mismatch: go_version pins %s but %s reports %s",
+			b.goVersion, b.goCmd(), actual)
+	case required != "" && version.IsValid(actual) && version.Compare(actual, required) < 0:
+		return fmt.Errorf("RoadRunner's go.mod requires go %s or newer, but the go toolchain is %s; set go_version or upgrade go",
+			mf.Go.Version, actual)
+	}
+	b.log.Info("go toolchain", "version", actual, "go.mod", required, "toolchain", preferred)
+	return nil
+}
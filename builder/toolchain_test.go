@@ -0,0 +1,72 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolchainBuilder returns a Builder whose RR source requires go 1.24.
+func toolchainBuilder(t *testing.T, opts ...Option) *Builder {
+	t.Helper()
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, goModFile),
+		[]byte("module github.com/roadrunner-server/roadrunner/v2025\n\ngo 1.24\n\ntoolchain go1.25.0\n"), 0o600))
+	return NewBuilder(src, opts...)
+}
+
+// fakeGo installs a go command in dir/bin that reports version.
+func fakeGo(t *testing.T, dir, version string) string {
+	t.Helper()
+	bin := filepath.Join(dir, "bin")
+	require.NoError(t, os.MkdirAll(bin, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "go"), []byte("#!/bin/sh\necho "+version+"\n"), 0o700))
+	return bin
+}
+
+func TestCheckGoToolchain(t *testing.T) {
+	t.Setenv("PATH", fakeGo(t, t.TempDir(), "go1.26.1")+string(os.PathListSeparator)+os.Getenv("PATH"))
+	b := toolchainBuilder(t)
+	require.NoError(t, b.checkGoToolchain(t.Context()))
+	assert.Equal(t, "go1.26.1", b.goActual)
+
+	dir := t.TempDir()
+	fakeGo(t, filepath.Join(dir, "go1.26.1"), "go1.26.1")
+	b = toolchainBuilder(t, WithGoVersion("1.26.1"), WithGoToolchainDir(dir))
+	require.NoError(t, b.checkGoToolchain(t.Context()))
+	assert.Equal(t, filepath.Join(dir, "go1.26.1", "bin", "go"), b.goCmd(), "the pin comes from go_toolchain_dir")
+}
+
+func TestCheckGoToolchain_Missing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	err := toolchainBuilder(t).checkGoToolchain(t.Context())
+	require.ErrorContains(t, err, "go env GOVERSION")
+}
+
+func TestCheckGoToolchain_OlderThanGoVersion(t *testing.T) {
+	// The pinned toolchain in go_toolchain_dir reports an older release.
+	dir := t.TempDir()
+	fakeGo(t, filepath.Join(dir, "go1.26.1"), "go1.25.3")
+	err := toolchainBuilder(t, WithGoVersion("1.26.1"), WithGoToolchainDir(dir)).checkGoToolchain(t.Context())
+	require.ErrorContains(t, err, "go toolchain mismatch: go_version pins go1.26.1 but")
+	require.ErrorContains(t, err, "reports go1.25.3")
+
+	// A pin older than RR's go.mod requires is rejected before any go command runs.
+	err = toolchainBuilder(t, WithGoVersion("1.23.4")).checkGoToolchain(t.Context())
+	require.EqualError(t, err, "go_version 1.23.4 is older than the go 1.24 RoadRunner's go.mod requires")
+
+	// Without a pin, the toolchain must still satisfy go.mod.
+	t.Setenv("PATH", fakeGo(t, t.TempDir(), "go1.23.4"))
+	err = toolchainBuilder(t).checkGoToolchain(t.Context())
+	require.ErrorContains(t, err, "RoadRunner's go.mod requires go 1.24 or newer, but the go toolchain is go1.23.4")
+}
+
+func TestCheckGoToolchain_NotAToolchainDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a toolchain"), 0o600))
+	err := toolchainBuilder(t, WithGoVersion("1.26.1"), WithGoToolchainDir(dir)).checkGoToolchain(t.Context())
+	require.ErrorContains(t, err, "go_version 1.26.1 is not installed in go_toolchain_dir")
+}
@@ -0,0 +1,138 @@
+package velox
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// PGO modes understood by `go build -pgo` besides a profile path.
+const (
+	PGOAuto = "auto"
+	PGOOff  = "off"
+)
+
+// reservedVars are the -X variables velox itself sets through ldflagsFmt.
+var reservedVars = []string{
+	"github.com/roadrunner-server/roadrunner/v3/internal/meta.version",
+	"github.com/roadrunner-server/roadrunner/v3/internal/meta.buildTime",
+}
+
+var (
+	// varName matches the importpath.name part of `-X importpath.name=value`.
+	varName = regexp.MustCompile(`^[A-Za-z0-9_.~/-]+\.[A-Za-z_][A-Za-z0-9_]*$`)
+	// buildTag matches a single `go build -tags` entry.
+	buildTag = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+)
+
+// Build is the [build] section: extra flags for `go build`. The builder owns
+// -ldflags and -gcflags and passes each exactly once, so these are merged
+// into its own values rather than passed through verbatim.
+type Build struct {
+	// Tags are extra build tags; a debug build adds "debug" on its own.
+	Tags []string `mapstructure:"tags"`
+	// Vars are `-X importpath.name=value` assignments folded into -ldflags.
+	Vars []string `mapstructure:"vars"`
+	// Gcflags are compiler flags applied to all packages (-gcflags=all=...).
+	Gcflags []string `mapstructure:"gcflags"`
+	// PGO is "auto", "off" or the path of a CPU profile.
+	PGO string `mapstructure:"pgo"`
+	// Buildmode is "exe" (default) or "pie".
+	Buildmode string `mapstructure:"buildmode"`
+	// Trimpath removes file system paths from the binary. Defaults to true.
+	Trimpath *bool `mapstructure:"trimpath"`
+}
+
+// TrimpathEnabled reports whether -trimpath should be passed.
+func (b *Build) TrimpathEnabled() bool {
+	return b == nil || b.Trimpath == nil || *b.Trimpath
+}
+
+// Validate rejects values that would have to be passed as separate flags or
+// could split the combined -ldflags / -gcflags argument.
+func (b *Build) Validate() error {
+	if b == nil {
+		return nil
+	}
+	for _, t := range b.Tags {
+		if err := ValidateBuildTag(t); err != nil {
+			return fmt.Errorf("build.tags: %w", err)
+		}
+	}
+	for _, v := range b.Vars {
+		if err := ValidateLdflagsVar(v); err != nil {
+			return fmt.Errorf("build.vars: %w", err)
+		}
+	}
+	for _, f := range b.Gcflags {
+		if err := ValidateGcflag(f); err != nil {
+			return fmt.Errorf("build.gcflags: %w", err)
+		}
+	}
+	if err := ValidateBuildmode(b.Buildmode); err != nil {
+		return fmt.Errorf("build.buildmode: %w", err)
+	}
+	if strings.ContainsAny(b.PGO, " \t\n") {
+		return fmt.Errorf("build.pgo: %q must not contain whitespace", b.PGO)
+	}
+	return nil
+}
+
+// ValidateBuildTag checks a single build tag.
+func ValidateBuildTag(tag string) error {
+	if !buildTag.MatchString(tag) {
+		return fmt.Errorf("invalid build tag %q", tag)
+	}
+	return nil
+}
+
+// ValidateLdflagsVar checks an `importpath.name=value` -X assignment. Values
+// must not contain whitespace or quotes: -ldflags is one space-separated
+// argument, so either would split or reopen it. velox's own meta variables
+// cannot be overridden.
+func ValidateLdflagsVar(v string) error {
+	name, value, ok := strings.Cut(v, "=")
+	switch {
+	case !ok:
+		return fmt.Errorf("%q: want importpath.name=value", v)
+	case !varName.MatchString(name):
+		return fmt.Errorf("%q: %q is not an importpath.name", v, name)
+	case slices.Contains(reservedVars, name):
+		return fmt.Errorf("%q: %s is set by velox", v, name)
+	case strings.ContainsAny(value, " \t\n'\""):
+		return fmt.Errorf("%q: value must not contain whitespace or quotes", v)
+	}
+	return nil
+}
+
+// ValidateGcflag checks a single compiler flag such as "-B".
+func ValidateGcflag(f string) error {
+	if !strings.HasPrefix(f, "-") || strings.ContainsAny(f, " \t\n'\"") {
+		return fmt.Errorf("invalid compiler flag %q: want a single -flag without whitespace or quotes", f)
+	}
+	return nil
+}
+
+// RemoteGcflags are the compiler flags a build server accepts from its
+// callers. Others can name files (-cpuprofile, -trace, ...) the compiler
+// writes on the server.
+var RemoteGcflags = []string{"-B", "-C", "-l", "-N", "-d=checkptr"}
+
+// ValidateRemoteGcflag checks a compiler flag sent to a build server
+// against RemoteGcflags.
+func ValidateRemoteGcflag(f string) error {
+	if !slices.Contains(RemoteGcflags, f) {
+		return fmt.Errorf("compiler flag %q is not allowed; want one of %s", f, strings.Join(RemoteGcflags, ", "))
+	}
+	return nil
+}
+
+// ValidateBuildmode accepts the build modes that produce an executable.
+func ValidateBuildmode(mode string) error {
+	switch mode {
+	case "", "exe", "default", "pie":
+		return nil
+	}
+	return fmt.Errorf("unsupported build mode %q (want exe or pie)", mode)
+}
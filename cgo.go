@@ -0,0 +1,86 @@
+package velox
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// CGO is the [cgo] section. velox builds with CGO_ENABLED=0 unless it is
+// enabled here (or the race detector is on).
+//
+//	[cgo]
+//	enabled = true
+//	static = true
+//
+//	[cgo.toolchains.linux_arm64]
+//	cc = "aarch64-linux-gnu-gcc"
+//	cxx = "aarch64-linux-gnu-g++"
+type CGO struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Static links the C parts statically
+	// (-linkmode external -extldflags -static). Linux targets only.
+	Static bool `mapstructure:"static"`
+	// Toolchains maps "<goos>_<goarch>" (e.g. linux_arm64) to the C
+	// toolchain for that target. Native builds fall back to the host's
+	// default compiler when their platform is not listed.
+	Toolchains map[string]*CToolchain `mapstructure:"toolchains"`
+}
+
+// CToolchain is the C toolchain cgo uses for one target platform.
+type CToolchain struct {
+	CC      string `mapstructure:"cc"`
+	CXX     string `mapstructure:"cxx"`
+	CFlags  string `mapstructure:"cflags"`
+	LDFlags string `mapstructure:"ldflags"`
+}
+
+// PlatformKey returns the Toolchains key for a target platform.
+func PlatformKey(goos, goarch string) string {
+	return strings.ToLower(goos + "_" + goarch)
+}
+
+// Toolchain returns the toolchain configured for goos/goarch, or nil.
+func (c *CGO) Toolchain(goos, goarch string) *CToolchain {
+	if c == nil {
+		return nil
+	}
+	return c.Toolchains[PlatformKey(goos, goarch)]
+}
+
+// Validate checks the section on its own; platform-dependent checks happen
+// in CheckCGOTarget once the target is known.
+func (c *CGO) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Static && !c.Enabled {
+		return errors.New("cgo.static requires cgo.enabled")
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.Toolchains)) {
+		if tc := c.Toolchains[key]; tc != nil && tc.CC == "" && tc.CXX != "" {
+			return fmt.Errorf("cgo.toolchains.%s: cxx is set but cc is not", key)
+		}
+	}
+	return nil
+}
+
+// CheckCGOTarget reports whether a cgo build (cgo enabled or the race
+// detector on) for goos/goarch on hostOS/hostArch can work with tc: a
+// cross-compile needs an explicit cross C compiler, and static linking is
+// only supported for Linux.
+func CheckCGOTarget(goos, goarch, hostOS, hostArch string, tc *CToolchain, static bool) error {
+	if static && goos != "linux" {
+		return fmt.Errorf("static cgo linking is only supported for linux targets, not %s", goos)
+	}
+	if goos == hostOS && goarch == hostArch {
+		return nil
+	}
+	if tc == nil || tc.CC == "" {
+		return fmt.Errorf("cgo cross-compilation from %s/%s to %s/%s needs a C cross compiler: set cc in [cgo.toolchains.%s]",
+			hostOS, hostArch, goos, goarch, PlatformKey(goos, goarch))
+	}
+	return nil
+}
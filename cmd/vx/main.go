@@ -7,17 +7,35 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/roadrunner-server/velox/v3/internal/cli"
+	"github.com/roadrunner-server/velox/v3/internal/telemetry"
+	"github.com/roadrunner-server/velox/v3/internal/version"
 )
 
+// tracingFlushTimeout bounds how long vx waits for pending spans on exit.
+const tracingFlushTimeout = 5 * time.Second
+
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 
+	shutdownTracing, err := telemetry.Setup(ctx, version.Version())
+	if err != nil {
+		_, _ = color.New(color.FgHiRed, color.Bold).Fprintln(os.Stderr, "tracing: "+err.Error())
+		os.Exit(1)
+	}
+
 	cmd := cli.NewCommand(filepath.Base(os.Args[0]))
-	err := cmd.ExecuteContext(ctx)
+	err = cmd.ExecuteContext(ctx)
 	stop() // release the signal handler explicitly; os.Exit below would skip defers
+
+	// Flush spans before exiting, also (and especially) for failed builds.
+	flushCtx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	_ = shutdownTracing(flushCtx)
+	cancel()
+
 	if err != nil {
 		_, _ = color.New(color.FgHiRed, color.Bold).Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
@@ -3,8 +3,8 @@ package velox
 import (
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 )
@@ -22,6 +22,9 @@ const (
 	V3 = "v3"
 )
 
+// errWindows rejects Windows targets, which the RR v2025+ line does not ship.
+var errWindows = errors.New("velox v3 does not support Windows targets")
+
 type Config struct {
 	// Roadrunner holds the ref (tag, branch, or SHA) under the "ref" key.
 	Roadrunner map[string]string `mapstructure:"roadrunner"`
@@ -39,6 +42,24 @@ type Config struct {
 	Replaces []Replace `mapstructure:"replaces"`
 	// Excludes is an optional list of go.mod exclude directives applied before tidy.
 	Excludes []Exclude `mapstructure:"excludes"`
+	// Build holds extra `go build` flags: tags, -X vars, gcflags, PGO, buildmode, trimpath.
+	Build *Build `mapstructure:"build"`
+	// CGO enables cgo and configures per-platform C toolchains.
+	CGO *CGO `mapstructure:"cgo"`
+	// Smoke configures exec wrappers for smoke-testing foreign-arch binaries.
+	Smoke *Smoke `mapstructure:"smoke"`
+	// Profiles are named build variants; see Profile and Config.Select.
+	Profiles map[string]*Profile `mapstructure:"profiles"`
+	// GoVersion pins the Go release used for builds (e.g. "1.26.1"). It is
+	// selected through GOTOOLCHAIN unless GoToolchainDir provides it.
+	GoVersion string `mapstructure:"go_version"`
+	// GoToolchainDir holds locally installed toolchains, one per release in
+	// <dir>/go<version> (the layout of golang.org/dl's ~/sdk).
+	GoToolchainDir string `mapstructure:"go_toolchain_dir"`
+	// Server configures `vx server`: authentication and per-principal policies.
+	Server *Server `mapstructure:"server"`
+	// Sandbox isolates the go commands of a build from the host.
+	Sandbox *Sandbox `mapstructure:"sandbox"`
 }
 
 type Debug struct {
@@ -78,9 +99,10 @@ type Exclude struct {
 	Version string `mapstructure:"version"`
 }
 
-// IsLocalPath reports whether s denotes a local filesystem path (./, ../, or absolute).
+// IsLocalPath reports whether s denotes a local filesystem path (., .., ./, ../, or
+// absolute), matching what the go command treats as a directory replacement.
 func IsLocalPath(s string) bool {
-	return strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../") || filepath.IsAbs(s)
+	return s == "." || s == ".." || strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../") || filepath.IsAbs(s)
 }
 
 func (r Replace) Validate() error {
@@ -101,9 +123,11 @@ func (e Exclude) Validate() error {
 }
 
 // Validate validates the configuration, applies defaults, and expands ${ENV} in
-// the GitHub token. The Roadrunner ref defaults to "master", TargetPlatform to
+// every string field. The Roadrunner ref defaults to "master", TargetPlatform to
 // runtime GOOS/GOARCH, log to debug/development, GitHub base URL to github.com.
 func (c *Config) Validate() error {
+	expandEnv(reflect.ValueOf(c).Elem())
+
 	if c.Roadrunner == nil {
 		c.Roadrunner = map[string]string{}
 	}
@@ -115,15 +139,12 @@ func (c *Config) Validate() error {
 		c.TargetPlatform = &TargetPlatform{OS: runtime.GOOS, Arch: runtime.GOARCH}
 	}
 	if strings.EqualFold(c.TargetPlatform.OS, "windows") {
-		return errors.New("velox v3 does not support Windows targets")
+		return errWindows
 	}
 
 	if c.GitHub == nil {
 		c.GitHub = &GitHub{}
 	}
-	if c.GitHub.Token != nil {
-		c.GitHub.Token.Token = os.ExpandEnv(c.GitHub.Token.Token)
-	}
 	if c.GitHub.BaseURL == "" {
 		c.GitHub.BaseURL = defaultGitHubBaseURL
 	}
@@ -159,6 +180,31 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if err := ValidateGoVersion(c.GoVersion); err != nil {
+		return fmt.Errorf("go_version: %w", err)
+	}
+	if c.GoToolchainDir != "" && c.GoVersion == "" {
+		return errors.New("go_toolchain_dir requires go_version")
+	}
+	if err := c.Build.Validate(); err != nil {
+		return err
+	}
+	if err := c.CGO.Validate(); err != nil {
+		return err
+	}
+	if err := c.Smoke.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Validate(); err != nil {
+		return err
+	}
+	if err := c.Sandbox.Validate(); err != nil {
+		return err
+	}
+	if err := c.validateProfiles(); err != nil {
+		return err
+	}
+
 	if len(c.Log) == 0 {
 		c.Log = map[string]string{LogLevelKey: "debug", LogModeKey: "development"}
 	}
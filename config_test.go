@@ -1,7 +1,14 @@
 package velox
 
 import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -174,6 +181,9 @@ func TestIsLocalPath(t *testing.T) {
 		"./foo":                 true,
 		"../foo":                true,
 		"/abs/path":             true,
+		".":                     true,
+		"..":                    true,
+		".hidden":               false,
 		"github.com/foo":        false,
 		"github.com/foo@v1.0.0": false,
 	}
@@ -185,3 +195,854 @@ func TestIsLocalPath(t *testing.T) {
 		})
 	}
 }
+
+func TestParseConfig(t *testing.T) {
+	const data = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+`
+	cfg, err := ParseConfig([]byte(data), "configs/velox.toml")
+	require.NoError(t, err)
+	assert.Equal(t, "v2025.1.2", cfg.Roadrunner[ref])
+	assert.Equal(t, "github.com/roadrunner-server/logger/v5", cfg.Plugins["logger"].ModuleName)
+
+	_, err = ParseConfig([]byte(`[roadrunner]`), "velox.toml")
+	require.Error(t, err, "validation must run on parsed configs")
+}
+
+func TestParseConfigStrict(t *testing.T) {
+	const base = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+`
+	tests := []struct {
+		name    string
+		extra   string
+		wantErr string
+	}{
+		{name: "clean", extra: ""},
+		{name: "unknown top-level table", extra: "\n[plugin.http]\ntag = \"v5.0.0\"\n", wantErr: "plugin"},
+		{name: "typo in plugin field", extra: "\n[plugins.http]\ntag = \"v5.0.0\"\nmodule_name = \"github.com/roadrunner-server/http/v5\"\nmodul_name = \"x\"\n", wantErr: "plugins.http.modul_name"},
+		{name: "unknown github key", extra: "\n[github.token]\ntoken = \"t\"\ntokn = \"t\"\n", wantErr: "github.token.tokn"},
+		{name: "unknown exclude key", extra: "\n[[excludes]]\nmodule = \"example.com/a\"\nversion = \"v1.0.0\"\nreason = \"bad\"\n", wantErr: "excludes[0].reason"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(base + tt.extra)
+
+			_, err := ParseConfig(data, "velox.toml")
+			require.NoError(t, err, "unknown keys are ignored by default")
+
+			_, err = ParseConfig(data, "velox.toml", WithStrict(true))
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	require.NoError(t, err)
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	props := schema["properties"].(map[string]any)
+	for _, key := range []string{"roadrunner", "debug", "log", "target_platform", "github", "plugins", "replaces", "excludes"} {
+		assert.Contains(t, props, key)
+	}
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	plugin := props["plugins"].(map[string]any)["additionalProperties"].(map[string]any)
+	assert.ElementsMatch(t, []any{"module_name", "tag"}, plugin["required"])
+	assert.Equal(t, false, plugin["additionalProperties"])
+
+	replace := props["replaces"].(map[string]any)["items"].(map[string]any)
+	assert.ElementsMatch(t, []any{"old", "new"}, replace["required"])
+}
+
+func TestConfigFormats(t *testing.T) {
+	docs := map[string]string{
+		"velox.toml": `
+[roadrunner]
+ref = "v2025.1.2"
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+`,
+		"velox.yaml": `
+roadrunner:
+  ref: v2025.1.2
+plugins:
+  logger:
+    tag: v5.0.2
+    module_name: github.com/roadrunner-server/logger/v5
+`,
+		"velox.json": `{
+  "roadrunner": {"ref": "v2025.1.2"},
+  "plugins": {"logger": {"tag": "v5.0.2", "module_name": "github.com/roadrunner-server/logger/v5"}}
+}`,
+	}
+	for name, data := range docs {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(data), name, WithStrict(true))
+			require.NoError(t, err)
+			assert.Equal(t, "v2025.1.2", cfg.Roadrunner[ref])
+			assert.Equal(t, "v5.0.2", cfg.Plugins["logger"].Tag)
+		})
+	}
+
+	_, err := ParseConfig([]byte(docs["velox.toml"]), "velox.ini")
+	require.ErrorContains(t, err, "unsupported config format")
+}
+
+func TestExtends(t *testing.T) {
+	const base = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[debug]
+enabled = true
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+
+[plugins.amqp]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/amqp/v5"
+
+[[replaces]]
+old = "github.com/a/b"
+new = "github.com/a/b@v1.0.0"
+
+[[replaces]]
+old = "github.com/c/d"
+new = "github.com/c/d@v1.0.0"
+
+[[excludes]]
+module = "github.com/x/y"
+version = "v1.0.0"
+`
+	tests := []struct {
+		name    string
+		file    string
+		overlay string
+		wantErr string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "override add and remove",
+			file: "prod.yaml",
+			overlay: `
+extends: base.toml
+debug:
+  enabled: false
+remove:
+  plugins: [amqp]
+  excludes: [github.com/x/y@v1.0.0]
+plugins:
+  logger:
+    tag: v5.1.0
+  otel:
+    tag: v5.0.0
+    module_name: github.com/roadrunner-server/otel/v5
+replaces:
+  - old: github.com/a/b
+    new: ../b
+  - old: github.com/e/f
+    new: github.com/e/f@v1.0.0
+`,
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "v2025.1.2", cfg.Roadrunner[ref])
+				assert.False(t, cfg.Debug.Enabled)
+				require.Len(t, cfg.Plugins, 2)
+				assert.Equal(t, "v5.1.0", cfg.Plugins["logger"].Tag)
+				assert.Equal(t, "github.com/roadrunner-server/logger/v5", cfg.Plugins["logger"].ModuleName)
+				assert.Contains(t, cfg.Plugins, "otel")
+				assert.Equal(t, []Replace{
+					{Old: "github.com/a/b", New: "../b"},
+					{Old: "github.com/c/d", New: "github.com/c/d@v1.0.0"},
+					{Old: "github.com/e/f", New: "github.com/e/f@v1.0.0"},
+				}, cfg.Replaces)
+				assert.Empty(t, cfg.Excludes)
+			},
+		},
+		{
+			name: "remove replace and add exclude",
+			file: "dev.toml",
+			overlay: `
+extends = ["base.toml"]
+
+[remove]
+replaces = ["github.com/c/d"]
+
+[[excludes]]
+module = "github.com/x/y"
+version = "v1.0.0"
+
+[[excludes]]
+module = "github.com/x/y"
+version = "v1.1.0"
+`,
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []Replace{{Old: "github.com/a/b", New: "github.com/a/b@v1.0.0"}}, cfg.Replaces)
+				assert.Equal(t, []Exclude{
+					{Module: "github.com/x/y", Version: "v1.0.0"},
+					{Module: "github.com/x/y", Version: "v1.1.0"},
+				}, cfg.Excludes)
+			},
+		},
+		{
+			name:    "remove unknown plugin",
+			file:    "bad.toml",
+			overlay: "extends = \"base.toml\"\n[remove]\nplugins = [\"htp\"]\n",
+			wantErr: `plugin "htp" is not defined`,
+		},
+		{
+			name:    "unknown remove key",
+			file:    "bad.toml",
+			overlay: "extends = \"base.toml\"\n[remove]\nplugin = [\"amqp\"]\n",
+			wantErr: "plugin",
+		},
+		{
+			name:    "cycle",
+			file:    "loop.json",
+			overlay: `{"extends": "loop.json"}`,
+			wantErr: "extends cycle",
+		},
+		{
+			name:    "missing base",
+			file:    "orphan.toml",
+			overlay: `extends = "nope.toml"`,
+			wantErr: "nope.toml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "base.toml"), []byte(base), 0o600))
+			path := filepath.Join(dir, tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.overlay), 0o600))
+
+			cfg, err := LoadConfig(path, WithStrict(true))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	const data = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+
+[plugins.http_proxy]
+tag = "v1.0.0"
+module_name = "github.com/example/http-proxy"
+`
+	t.Setenv("VELOX_ROADRUNNER_REF", "v2025.2.0")
+	t.Setenv("VELOX_DEBUG_ENABLED", "true")
+	t.Setenv("VELOX_TARGET_PLATFORM_ARCH", "arm64")
+	t.Setenv("VELOX_PLUGINS_LOGGER_TAG", "v5.0.3")
+	t.Setenv("VELOX_PLUGINS_HTTP_PROXY_TAG", "v1.1.0")
+
+	cfg, err := ParseConfig([]byte(data), "velox.toml",
+		WithEnvPrefix("VELOX"),
+		WithSet("plugins.logger.tag", "v5.0.4"),
+		WithSet("plugins.http.module_name", "github.com/roadrunner-server/http/v5"),
+		WithSet("plugins.http.tag", "v5.2.0"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "v2025.2.0", cfg.Roadrunner[ref])
+	assert.True(t, cfg.Debug.Enabled)
+	assert.Equal(t, "arm64", cfg.TargetPlatform.Arch)
+	assert.Equal(t, "v5.0.4", cfg.Plugins["logger"].Tag, "--set wins over the environment")
+	assert.Equal(t, "v1.1.0", cfg.Plugins["http_proxy"].Tag)
+	assert.Equal(t, "v5.2.0", cfg.Plugins["http"].Tag)
+
+	cfg, err = ParseConfig([]byte(data), "velox.toml")
+	require.NoError(t, err)
+	assert.Equal(t, "v2025.1.2", cfg.Roadrunner[ref], "environment is only read with WithEnvPrefix")
+
+	for key, wantErr := range map[string]string{
+		"replaces":        "lists cannot be overridden",
+		"plugins.logger":  "not a scalar",
+		"plugin.http.tag": "unknown key",
+		"debug.enabled.x": "not a table",
+	} {
+		_, err := ParseConfig([]byte(data), "velox.toml", WithSet(key, "x"))
+		require.ErrorContains(t, err, wantErr, key)
+	}
+}
+
+func TestEnvKey(t *testing.T) {
+	tests := map[string]string{
+		"ROADRUNNER_REF":         "roadrunner.ref",
+		"TARGET_PLATFORM_OS":     "target_platform.os",
+		"GITHUB_TOKEN_TOKEN":     "github.token.token",
+		"GITHUB_BASE_URL":        "github.base_url",
+		"PLUGINS_HTTP_TAG":       "plugins.http.tag",
+		"PLUGINS_HTTP_PROXY_TAG": "plugins.http_proxy.tag",
+		"PLUGINS_A_MODULE_NAME":  "plugins.a.module_name",
+		"DEBUG":                  "",
+		"REPLACES":               "",
+		"PLUGINS_TAG":            "",
+	}
+	for env, want := range tests {
+		got, ok := envKey(reflect.TypeFor[Config](), env)
+		assert.Equal(t, want != "", ok, env)
+		assert.Equal(t, want, got, env)
+	}
+}
+
+func TestExpandEnvEverywhere(t *testing.T) {
+	t.Setenv("RR_REF", "v2025.1.2")
+	t.Setenv("LOGGER_TAG", "v5.0.2")
+	t.Setenv("FORK", "github.com/me/fork")
+
+	c := &Config{
+		Roadrunner: map[string]string{ref: "${RR_REF}"},
+		Plugins: map[string]*Plugin{
+			"logger": {Tag: "${LOGGER_TAG}", ModuleName: "github.com/roadrunner-server/logger/v5"},
+		},
+		Replaces: []Replace{{Old: "github.com/a/b", New: "${FORK}@v1.0.0"}},
+	}
+	require.NoError(t, c.Validate())
+	assert.Equal(t, "v2025.1.2", c.Roadrunner[ref])
+	assert.Equal(t, "v5.0.2", c.Plugins["logger"].Tag)
+	assert.Equal(t, "github.com/me/fork@v1.0.0", c.Replaces[0].New)
+}
+
+func TestParsePluginSpec(t *testing.T) {
+	name, p, err := ParsePluginSpec("HTTP=github.com/roadrunner-server/http/v5@v5.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, "http", name)
+	assert.Equal(t, &Plugin{ModuleName: "github.com/roadrunner-server/http/v5", Tag: "v5.2.0"}, p)
+
+	_, p, err = ParsePluginSpec("http=github.com/roadrunner-server/http/v5")
+	require.NoError(t, err)
+	assert.Equal(t, "latest", p.Tag)
+
+	for _, bad := range []string{"http", "=github.com/x", "http=", "http=github.com/x@"} {
+		_, _, err := ParsePluginSpec(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	const data = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[target_platform]
+os = "linux"
+arch = "amd64"
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+
+[plugins.http]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/http/v5"
+
+[plugins.amqp]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/amqp/v5"
+
+[profiles.minimal]
+plugins = ["logger", "http"]
+
+[profiles.full]
+disable = ["amqp"]
+debug = true
+race = true
+tags = ["otel", "nats"]
+
+[profiles.full.target_platform]
+arch = "arm64"
+`
+	cfg, err := ParseConfig([]byte(data), "velox.toml", WithStrict(true))
+	require.NoError(t, err)
+
+	tests := []struct {
+		profile string
+		plugins []string
+		check   func(t *testing.T, s *Selection)
+		wantErr string
+	}{
+		{profile: "", plugins: []string{"amqp", "http", "logger"}, check: func(t *testing.T, s *Selection) {
+			assert.False(t, s.Debug)
+			assert.False(t, s.Race)
+			assert.Empty(t, s.Tags)
+		}},
+		{profile: "minimal", plugins: []string{"http", "logger"}},
+		{profile: "FULL", plugins: []string{"http", "logger"}, check: func(t *testing.T, s *Selection) {
+			assert.True(t, s.Debug)
+			assert.True(t, s.Race)
+			assert.Equal(t, []string{"otel", "nats"}, s.Tags)
+			assert.Equal(t, TargetPlatform{OS: "linux", Arch: "arm64"}, s.TargetPlatform)
+		}},
+		{profile: "nope", wantErr: `unknown profile "nope" (defined: full, minimal)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			s, err := cfg.Select(tt.profile)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.plugins, slices.Sorted(maps.Keys(s.Plugins)))
+			if tt.check != nil {
+				tt.check(t, s)
+			}
+		})
+	}
+
+	bad := strings.Replace(data, `plugins = ["logger", "http"]`, `plugins = ["logger", "htp"]`, 1)
+	_, err = ParseConfig([]byte(bad), "velox.toml")
+	require.ErrorContains(t, err, `profile "minimal": plugin "htp" is not defined`)
+}
+
+func TestBuildValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   *Build
+		wantErr string
+	}{
+		{name: "nil", build: nil},
+		{name: "valid", build: &Build{
+			Tags:      []string{"otel", "nats"},
+			Vars:      []string{"main.env=prod", "github.com/acme/cfg/version.SHA=0a1b2c3"},
+			Gcflags:   []string{"-B"},
+			PGO:       PGOAuto,
+			Buildmode: "pie",
+		}},
+		{name: "tag with comma", build: &Build{Tags: []string{"a,b"}}, wantErr: "build.tags"},
+		{name: "var without value", build: &Build{Vars: []string{"main.env"}}, wantErr: "want importpath.name=value"},
+		{name: "var with space", build: &Build{Vars: []string{"main.env=prod -s"}}, wantErr: "whitespace"},
+		{name: "var with quote", build: &Build{Vars: []string{"main.env='x"}}, wantErr: "quotes"},
+		{name: "var without package", build: &Build{Vars: []string{"env=prod"}}, wantErr: "not an importpath.name"},
+		{
+			name:    "reserved var",
+			build:   &Build{Vars: []string{"github.com/roadrunner-server/roadrunner/v3/internal/meta.version=x"}},
+			wantErr: "set by velox",
+		},
+		{name: "gcflag without dash", build: &Build{Gcflags: []string{"B"}}, wantErr: "build.gcflags"},
+		{name: "gcflag with space", build: &Build{Gcflags: []string{"-N -l"}}, wantErr: "build.gcflags"},
+		{name: "buildmode", build: &Build{Buildmode: "c-shared"}, wantErr: "unsupported build mode"},
+		{name: "pgo with space", build: &Build{PGO: "my profile.pprof"}, wantErr: "build.pgo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.build.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	assert.True(t, (*Build)(nil).TrimpathEnabled())
+	assert.True(t, (&Build{}).TrimpathEnabled())
+	off := false
+	assert.False(t, (&Build{Trimpath: &off}).TrimpathEnabled())
+}
+
+func TestCGO(t *testing.T) {
+	const data = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+
+[cgo]
+enabled = true
+static = true
+
+[cgo.toolchains.linux_arm64]
+cc = "aarch64-linux-gnu-gcc"
+cxx = "aarch64-linux-gnu-g++"
+cflags = "-O2"
+`
+	cfg, err := ParseConfig([]byte(data), "velox.toml", WithStrict(true))
+	require.NoError(t, err)
+	tc := cfg.CGO.Toolchain("linux", "arm64")
+	require.NotNil(t, tc)
+	assert.Equal(t, CToolchain{CC: "aarch64-linux-gnu-gcc", CXX: "aarch64-linux-gnu-g++", CFlags: "-O2"}, *tc)
+	assert.Nil(t, cfg.CGO.Toolchain("darwin", "arm64"))
+	assert.Nil(t, (*CGO)(nil).Toolchain("linux", "arm64"))
+
+	require.ErrorContains(t, (&CGO{Static: true}).Validate(), "requires cgo.enabled")
+	require.ErrorContains(t, (&CGO{Enabled: true, Toolchains: map[string]*CToolchain{
+		"linux_arm64": {CXX: "g++"},
+	}}).Validate(), "cgo.toolchains.linux_arm64")
+
+	cross := &CToolchain{CC: "aarch64-linux-gnu-gcc"}
+	tests := []struct {
+		name          string
+		goos, goarch  string
+		tc            *CToolchain
+		static        bool
+		wantErrSubstr string
+	}{
+		{name: "native", goos: "linux", goarch: "amd64"},
+		{name: "native static", goos: "linux", goarch: "amd64", static: true},
+		{name: "cross with toolchain", goos: "linux", goarch: "arm64", tc: cross, static: true},
+		{name: "cross without toolchain", goos: "linux", goarch: "arm64", wantErrSubstr: "[cgo.toolchains.linux_arm64]"},
+		{name: "cross with empty cc", goos: "linux", goarch: "arm64", tc: &CToolchain{}, wantErrSubstr: "needs a C cross compiler"},
+		{name: "static darwin", goos: "darwin", goarch: "arm64", tc: cross, static: true, wantErrSubstr: "only supported for linux"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCGOTarget(tt.goos, tt.goarch, "linux", "amd64", tt.tc, tt.static)
+			if tt.wantErrSubstr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErrSubstr)
+		})
+	}
+}
+
+func TestGoVersion(t *testing.T) {
+	tests := []struct {
+		in            string
+		wantErrSubstr string
+	}{
+		{in: ""},
+		{in: "1.26.1"},
+		{in: "go1.26.1"},
+		{in: "1.27rc1"},
+		{in: "1.26", wantErrSubstr: "language version"},
+		{in: "1.26.x", wantErrSubstr: "invalid go version"},
+		{in: "latest", wantErrSubstr: "invalid go version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			err := ValidateGoVersion(tt.in)
+			if tt.wantErrSubstr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErrSubstr)
+		})
+	}
+
+	assert.Equal(t, "go1.26.1", GoToolchainName("1.26.1"))
+	assert.Equal(t, "go1.26.1", GoToolchainName("go1.26.1"))
+	assert.Empty(t, GoToolchainName(""))
+
+	const base = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+`
+	cfg, err := ParseConfig([]byte(`go_version = "1.26.1"
+go_toolchain_dir = "/opt/go"
+`+base), "velox.toml", WithStrict(true))
+	require.NoError(t, err)
+	assert.Equal(t, "1.26.1", cfg.GoVersion)
+	assert.Equal(t, "/opt/go", cfg.GoToolchainDir)
+
+	_, err = ParseConfig([]byte(`go_toolchain_dir = "/opt/go"
+`+base), "velox.toml")
+	require.ErrorContains(t, err, "go_toolchain_dir requires go_version")
+}
+
+func TestSmoke(t *testing.T) {
+	const data = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+
+[smoke.exec_wrappers]
+linux_arm64 = "qemu-aarch64-static -L /usr/aarch64-linux-gnu"
+`
+	cfg, err := ParseConfig([]byte(data), "velox.toml", WithStrict(true))
+	require.NoError(t, err)
+	assert.Equal(t, "qemu-aarch64-static -L /usr/aarch64-linux-gnu", cfg.Smoke.ExecWrapper("linux", "arm64"))
+	assert.Empty(t, cfg.Smoke.ExecWrapper("linux", "riscv64"))
+	assert.Empty(t, (*Smoke)(nil).ExecWrapper("linux", "arm64"))
+
+	require.ErrorContains(t, (&Smoke{ExecWrappers: map[string]string{"darwin_arm64": "x"}}).Validate(), "only linux targets")
+	require.ErrorContains(t, (&Smoke{ExecWrappers: map[string]string{"linux_arm64": " "}}).Validate(), "empty command")
+}
+
+func TestCheckConfigData(t *testing.T) {
+	const data = `[roadrunner]
+ref = "v2025.1.2"
+
+[plugin.http]
+tag = "v5.0.0"
+
+[plugins.http]
+tag = "5.0"
+module_name = "github.com/roadrunner-server/http/v5"
+
+[plugins.http2]
+tag = "v6.0.0"
+modul_name = "x"
+module_name = "github.com/roadrunner-server/http/v5"
+
+[plugins.bad]
+tag = "latest"
+module_name = "not a module"
+
+[[replaces]]
+old = "github.com/foo/bar"
+new = "../bar"
+
+[[replaces]]
+old = "github.com/foo/baz"
+new = "github.com/me/baz"
+`
+	ds, err := CheckConfigData([]byte(data), "velox.toml")
+	require.NoError(t, err)
+	require.True(t, ds.HasErrors())
+
+	type want struct {
+		line int
+		sev  Severity
+		key  string
+		msg  string
+	}
+	cases := []want{
+		{4, SeverityWarning, "plugin", "unknown key"},
+		{8, SeverityError, "plugins.http.tag", "not a semantic version"},
+		{12, SeverityError, "plugins.http2.tag", "should be v5"},
+		{13, SeverityWarning, "plugins.http2.modul_name", "unknown key"},
+		{14, SeverityError, "plugins.http2.module_name", `already used by plugin "http"`},
+		{18, SeverityError, "plugins.bad.module_name", "malformed module path"},
+		{26, SeverityError, "replaces[1].new", "needs an @version"},
+	}
+	require.Len(t, ds, len(cases), "%v", ds)
+	for i, tc := range cases {
+		t.Run(tc.key, func(t *testing.T) {
+			assert.Equal(t, tc.line, ds[i].Line)
+			assert.Equal(t, tc.sev, ds[i].Severity)
+			assert.Equal(t, tc.key, ds[i].Key)
+			assert.Contains(t, ds[i].Message, tc.msg)
+		})
+	}
+}
+
+func TestCheckReplacesInGraph(t *testing.T) {
+	c := &Config{Replaces: []Replace{
+		{Old: "github.com/in/graph@v1.0.0", New: "../a"},
+		{Old: "github.com/not/there", New: "../b"},
+	}}
+	ds := c.CheckReplacesInGraph([]string{"github.com/in/graph"})
+	require.Len(t, ds, 1)
+	assert.Equal(t, "replaces[1].old", ds[0].Key)
+	assert.Equal(t, SeverityWarning, ds[0].Severity)
+}
+
+func TestIsVersion(t *testing.T) {
+	cases := map[string]bool{
+		"v1.2.3":                             true,
+		"v1.2.3-rc.1":                        true,
+		"v2.0.0+incompatible":                true,
+		"v0.0.0-20240101000000-abcdef123456": true,
+		"v1.2":                               false,
+		"1.2.3":                              false,
+		"master":                             false,
+	}
+	for v, want := range cases {
+		t.Run(v, func(t *testing.T) {
+			assert.Equal(t, want, isVersion(v))
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	t.Setenv("CI_BUILD_TOKEN", "s3cret")
+	const data = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+
+[server.auth]
+anonymous_policy = "public"
+
+[server.auth.tokens.ci]
+token = "${CI_BUILD_TOKEN}"
+policy = "ci"
+
+[server.auth.jwt]
+jwks_file = "/etc/velox/jwks.json"
+
+[server.policies.ci]
+rr_versions = ["v2025.*"]
+platforms = ["linux/*"]
+
+[server.policies.ci.limits]
+max_concurrent_builds = 4
+
+[server.policies.public]
+rr_versions = ["*"]
+
+[server.policies.admin]
+view_all_builds = true
+
+[server.limits]
+requests_per_minute = 30
+daily_cpu_seconds = 3600
+
+[server.history]
+path = "/var/lib/velox/history.db"
+max_age_days = 90
+`
+	cfg, err := ParseConfig([]byte(data), "velox.toml", WithStrict(true), WithEnvPrefix("VELOX"))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Server.Auth)
+	assert.Equal(t, "s3cret", cfg.Server.Auth.Tokens["ci"].Token)
+	assert.Equal(t, "sub", cfg.Server.Auth.JWT.PrincipalClaim)
+	assert.Equal(t, "velox_policy", cfg.Server.Auth.JWT.PolicyClaim)
+	assert.Equal(t, []string{"linux/*"}, cfg.Server.Policies["ci"].Platforms)
+	require.NotNil(t, cfg.Server.Policies["public"])
+	assert.Equal(t, 30, cfg.Server.Limits.Burst, "burst defaults to requests_per_minute")
+	assert.Equal(t, 4, cfg.Server.Policies["ci"].Limits.MaxConcurrentBuilds)
+	assert.True(t, cfg.Server.Policies["admin"].ViewAllBuilds)
+	assert.Equal(t, 90, cfg.Server.History.MaxAgeDays)
+
+	t.Setenv("VELOX_CI_TOKEN", "s3cret")
+	_, err = ParseConfig([]byte(data), "velox.toml", WithStrict(true), WithEnvPrefix("VELOX"))
+	require.ErrorContains(t, err, "environment variable VELOX_CI_TOKEN does not match any config key",
+		"token variables belong outside the VELOX_ prefix")
+
+	require.ErrorContains(t, (&Server{Limits: &Limits{Burst: 5}}).Validate(), "server.limits: burst requires requests_per_minute")
+	require.ErrorContains(t, (&Server{Policies: map[string]*Policy{"ci": {Limits: &Limits{DailyCPUSeconds: -1}}}}).Validate(),
+		"server.policies.ci.limits: limits must not be negative")
+	require.ErrorContains(t, (&Server{History: &History{MaxRecords: -1}}).Validate(), "server.history")
+
+	policies := map[string]*Policy{"ci": {}}
+	cases := map[string]struct {
+		auth *Auth
+		want string
+	}{
+		"unknown policy": {&Auth{Tokens: map[string]*AuthToken{"a": {Token: "x", Policy: "nope"}}}, `server.auth.tokens.a: unknown policy "nope"`},
+		"empty token":    {&Auth{Tokens: map[string]*AuthToken{"a": {Policy: "ci"}}}, "token is required"},
+		"shared token": {&Auth{Tokens: map[string]*AuthToken{
+			"a": {Token: "x", Policy: "ci"}, "b": {Token: "x", Policy: "ci"},
+		}}, "server.auth.tokens.b: same token as server.auth.tokens.a"},
+		"shared common name": {&Auth{ClientCerts: map[string]*AuthClientCert{
+			"a": {CommonName: "ci", Policy: "ci"}, "b": {CommonName: "ci", Policy: "ci"},
+		}}, "same common_name"},
+		"jwks required":      {&Auth{JWT: &AuthJWT{}}, "jwks_file is required"},
+		"anonymous policy":   {&Auth{AnonymousPolicy: "nope"}, "server.auth.anonymous_policy"},
+		"jwt default policy": {&Auth{JWT: &AuthJWT{JWKSFile: "k", DefaultPolicy: "nope"}}, "server.auth.jwt.default_policy"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorContains(t, (&Server{Auth: tc.auth, Policies: policies}).Validate(), tc.want)
+		})
+	}
+
+	require.NoError(t, (&ServerTLS{CertFile: "c", KeyFile: "k", ClientCAFile: "ca", ClientAuth: ClientAuthRequire, MinVersion: "1.3"}).Validate())
+	require.ErrorContains(t, (&ServerTLS{CertFile: "c"}).Validate(), "set together")
+	require.ErrorContains(t, (&ServerTLS{ClientCAFile: "ca"}).Validate(), "client_ca_file requires")
+	require.ErrorContains(t, (&ServerTLS{CertFile: "c", KeyFile: "k", MinVersion: "1.1"}).Validate(), "min_version")
+	require.ErrorContains(t, (&ServerTLS{CertFile: "c", KeyFile: "k", ClientCAFile: "ca", ClientAuth: "any"}).Validate(), "client_auth")
+	require.ErrorContains(t, (&ServerTLS{CertFile: "c", KeyFile: "k", ClientAuth: ClientAuthRequire}).Validate(),
+		"client_auth requires client_ca_file", "mandatory mTLS without a CA would accept any client")
+	assert.False(t, (*ServerTLS)(nil).Enabled())
+}
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"v2025.*", "v2025.1.2", true},
+		{"v2025.*", "v2024.1.2", false},
+		{"master", "master", true},
+		{"github.com/acme/*", "github.com/acme/x/v2@v2.0.0", true},
+		{"github.com/acme/*", "github.com/acme", false},
+		{"linux/*", "linux/arm64", true},
+		{"*/amd64", "darwin/amd64", true},
+		{"*/amd64", "darwin/arm64", false},
+		{"a*b*c", "aXbYc", true},
+		{"a*b*c", "aXcYb", false},
+		{"ab*ba", "aba", false},
+		{"*", "", true},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, MatchGlob(tc.pattern, tc.s), "%q ~ %q", tc.pattern, tc.s)
+	}
+	assert.True(t, MatchAnyGlob([]string{"x", "linux/*"}, "linux/amd64"))
+	assert.False(t, MatchAnyGlob(nil, "linux/amd64"))
+}
+
+func TestSandbox(t *testing.T) {
+	const data = `
+[roadrunner]
+ref = "v2025.1.2"
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+
+[sandbox]
+enabled = true
+memory_mb = 4096
+disk_mb = 2048
+cgroup_parent = "/sys/fs/cgroup/velox.slice"
+`
+	cfg, err := ParseConfig([]byte(data), "velox.toml", WithStrict(true))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Sandbox)
+	assert.True(t, cfg.Sandbox.Enabled)
+	assert.Equal(t, 4096, cfg.Sandbox.MemoryMB)
+
+	require.ErrorContains(t, (&Sandbox{DiskMB: -1}).Validate(), "must not be negative")
+	require.ErrorContains(t, (&Sandbox{CgroupParent: "velox.slice"}).Validate(), "absolute path")
+}
+
+func TestGlobListPermits(t *testing.T) {
+	var none *GlobList
+	assert.True(t, none.Permits("github.com/any/module"))
+
+	g := &GlobList{Allow: []string{"github.com/roadrunner-server/*"}, Deny: []string{"*/rpc/*"}}
+	assert.True(t, g.Permits("github.com/roadrunner-server/http/v6"))
+	assert.False(t, g.Permits("github.com/roadrunner-server/rpc/v6"), "deny wins over allow")
+	assert.False(t, g.Permits("github.com/acme/plugin"), "allow-list admits only matches")
+
+	denyOnly := &GlobList{Deny: []string{"github.com/evil/*"}}
+	assert.True(t, denyOnly.Permits("github.com/acme/plugin"))
+	assert.False(t, denyOnly.Permits("github.com/evil/plugin"))
+}
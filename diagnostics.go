@@ -0,0 +1,298 @@
+package velox
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const latestTag = "latest"
+
+// Severity classifies a Diagnostic.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single problem found by CheckConfig.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	// Key is the dotted config path, e.g. "plugins.http.tag" or "replaces[1].new".
+	Key string `json:"key"`
+	// Line is the 1-based line in the source file, or 0 when unknown.
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// Diagnostics is the full result of CheckConfig, in source order.
+type Diagnostics []Diagnostic
+
+// HasErrors reports whether any diagnostic has SeverityError.
+func (ds Diagnostics) HasErrors() bool {
+	return slices.ContainsFunc(ds, func(d Diagnostic) bool { return d.Severity == SeverityError })
+}
+
+// CheckConfig reads the config at path and reports every problem, instead of
+// stopping at the first one like Validate. The returned error is only set
+// when the file cannot be read or parsed at all.
+func CheckConfig(path string) (Diagnostics, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return CheckConfigData(data, path)
+}
+
+// CheckConfigData is CheckConfig for in-memory data; name selects the format
+// by extension. Configs using `extends` are checked after merging; line
+// numbers are only available for TOML and only point into data itself.
+func CheckConfigData(data []byte, name string) (Diagnostics, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := mergeLayers(data, name, []string{abs})
+	if err != nil {
+		return nil, err
+	}
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+
+	var md mapstructure.Metadata
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg, func(dc *mapstructure.DecoderConfig) { dc.Metadata = &md }); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", name, err)
+	}
+
+	ds := cfg.Check()
+	for _, key := range md.Unused {
+		ds = append(ds, Diagnostic{
+			Severity: SeverityWarning,
+			Key:      normalizeKey(key),
+			Message:  "unknown key; it is ignored",
+		})
+	}
+
+	return LocateDiagnostics(ds, data, name), nil
+}
+
+// LocateDiagnostics fills in Line for every diagnostic from the source data
+// (TOML only) and sorts them by line; diagnostics without a line go last.
+func LocateDiagnostics(ds Diagnostics, data []byte, name string) Diagnostics {
+	lines := map[string]int{}
+	if strings.EqualFold(filepath.Ext(name), ".toml") {
+		lines = tomlKeyLines(data)
+	}
+	for i := range ds {
+		ds[i].Line = lineFor(lines, ds[i].Key)
+	}
+	slices.SortStableFunc(ds, func(a, b Diagnostic) int {
+		switch {
+		case a.Line == b.Line:
+			return 0
+		case a.Line == 0:
+			return 1
+		case b.Line == 0:
+			return -1
+		}
+		return a.Line - b.Line
+	})
+	return ds
+}
+
+// Check reports every problem with an already decoded config. Unlike
+// Validate it does not apply defaults and never stops early.
+func (c *Config) Check() Diagnostics {
+	var ds Diagnostics
+	add := func(sev Severity, key, format string, args ...any) {
+		ds = append(ds, Diagnostic{Severity: sev, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Roadrunner[ref] == "" {
+		add(SeverityWarning, "roadrunner.ref", "not set; defaults to %q", defaultBranch)
+	}
+	if c.TargetPlatform != nil && strings.EqualFold(c.TargetPlatform.OS, "windows") {
+		add(SeverityError, "target_platform.os", "velox v3 does not support Windows targets")
+	}
+
+	if len(c.Plugins) == 0 {
+		add(SeverityError, "plugins", "plugins configuration is required")
+	}
+	byModule := map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(c.Plugins)) {
+		p := c.Plugins[name]
+		key := "plugins." + name
+		if p == nil {
+			add(SeverityError, key, "plugin %q is empty", name)
+			continue
+		}
+		switch {
+		case p.ModuleName == "":
+			add(SeverityError, key+".module_name", "module name is required")
+		default:
+			if err := module.CheckPath(p.ModuleName); err != nil {
+				add(SeverityError, key+".module_name", "%v", err)
+			}
+			if other, dup := byModule[p.ModuleName]; dup {
+				add(SeverityError, key+".module_name", "module %s is already used by plugin %q", p.ModuleName, other)
+			} else {
+				byModule[p.ModuleName] = name
+			}
+		}
+		switch {
+		case p.Tag == "":
+			add(SeverityError, key+".tag", "tag is required")
+		case p.Tag == latestTag:
+		case !isVersion(p.Tag):
+			add(SeverityError, key+".tag", "tag %q is not a semantic version, pseudo-version or %q", p.Tag, latestTag)
+		case p.ModuleName != "":
+			if _, major, ok := module.SplitPathVersion(p.ModuleName); ok {
+				if err := module.CheckPathMajor(p.Tag, major); err != nil {
+					add(SeverityError, key+".tag", "%v", err)
+				}
+			}
+		}
+	}
+
+	seen := map[string]struct{}{}
+	for i, r := range c.Replaces {
+		key := fmt.Sprintf("replaces[%d]", i)
+		if err := r.Validate(); err != nil {
+			add(SeverityError, key, "%v", err)
+			continue
+		}
+		if _, dup := seen[r.Old]; dup {
+			add(SeverityError, key+".old", "duplicate old %q", r.Old)
+		}
+		seen[r.Old] = struct{}{}
+		if err := checkModuleVersion(r.Old, false); err != nil {
+			add(SeverityError, key+".old", "%v", err)
+		}
+		if !IsLocalPath(r.New) {
+			if err := checkModuleVersion(r.New, true); err != nil {
+				add(SeverityError, key+".new", "%v", err)
+			}
+		}
+	}
+
+	if err := ValidateGoVersion(c.GoVersion); err != nil {
+		add(SeverityError, "go_version", "%v", err)
+	}
+	if c.GoToolchainDir != "" && c.GoVersion == "" {
+		add(SeverityError, "go_toolchain_dir", "set without go_version")
+	}
+	if err := c.Build.Validate(); err != nil {
+		add(SeverityError, "build", "%v", err)
+	}
+	if err := c.CGO.Validate(); err != nil {
+		add(SeverityError, "cgo", "%v", err)
+	}
+	if err := c.Smoke.Validate(); err != nil {
+		add(SeverityError, "smoke", "%v", err)
+	}
+	if err := c.Server.Validate(); err != nil {
+		add(SeverityError, "server", "%v", err)
+	}
+	if err := c.Sandbox.Validate(); err != nil {
+		add(SeverityError, "sandbox", "%v", err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		p := c.Profiles[name]
+		if p == nil {
+			continue
+		}
+		key := "profiles." + name
+		for _, n := range p.Plugins {
+			if _, ok := c.Plugins[strings.ToLower(n)]; !ok {
+				add(SeverityError, key+".plugins", "plugin %q is not defined under [plugins]", n)
+			}
+		}
+		for _, n := range p.Disable {
+			if _, ok := c.Plugins[strings.ToLower(n)]; !ok {
+				add(SeverityError, key+".disable", "plugin %q is not defined under [plugins]", n)
+			}
+		}
+		if p.TargetPlatform != nil && strings.EqualFold(p.TargetPlatform.OS, "windows") {
+			add(SeverityError, key+".target_platform.os", "velox v3 does not support Windows targets")
+		}
+	}
+
+	for i, e := range c.Excludes {
+		key := fmt.Sprintf("excludes[%d]", i)
+		if err := e.Validate(); err != nil {
+			add(SeverityError, key, "%v", err)
+			continue
+		}
+		if err := module.CheckPath(e.Module); err != nil {
+			add(SeverityError, key+".module", "%v", err)
+		}
+		if !isVersion(e.Version) {
+			add(SeverityError, key+".version", "%q is not a semantic version or pseudo-version", e.Version)
+		}
+	}
+	return ds
+}
+
+// CheckReplacesInGraph flags replaces whose old module is absent from the
+// resolved module graph: such directives are silently ignored by go.
+func (c *Config) CheckReplacesInGraph(graph []string) Diagnostics {
+	var ds Diagnostics
+	for i, r := range c.Replaces {
+		old, _, _ := strings.Cut(r.Old, "@")
+		if !slices.Contains(graph, old) {
+			ds = append(ds, Diagnostic{
+				Severity: SeverityWarning,
+				Key:      fmt.Sprintf("replaces[%d].old", i),
+				Message:  fmt.Sprintf("module %s is not in the resolved module graph; the replace has no effect", old),
+			})
+		}
+	}
+	return ds
+}
+
+// isVersion accepts canonical semantic versions (including pseudo-versions
+// and +incompatible), the forms go.mod accepts verbatim.
+func isVersion(v string) bool {
+	if !semver.IsValid(v) {
+		return false
+	}
+	return semver.Canonical(v) == v || module.IsPseudoVersion(v) || strings.HasSuffix(v, "+incompatible")
+}
+
+// checkModuleVersion validates "module" or "module@version".
+func checkModuleVersion(s string, versionRequired bool) error {
+	path, version, hasVersion := strings.Cut(s, "@")
+	if err := module.CheckPath(path); err != nil {
+		return err
+	}
+	switch {
+	case hasVersion && !isVersion(version):
+		return fmt.Errorf("%q is not a semantic version or pseudo-version", version)
+	case !hasVersion && versionRequired:
+		return fmt.Errorf("%s: a non-local replacement needs an @version", path)
+	}
+	return nil
+}
+
+// mapIndex matches a mapstructure map-key segment such as "[http]".
+var mapIndex = regexp.MustCompile(`\[([^\]0-9][^\]]*)\]`)
+
+// normalizeKey rewrites mapstructure's "plugins[http].tag" as
+// "plugins.http.tag"; slice indexes ("replaces[0]") are kept.
+func normalizeKey(key string) string {
+	return strings.ToLower(mapIndex.ReplaceAllString(key, ".$1"))
+}
@@ -0,0 +1,140 @@
+package velox
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// EncodeTOML writes c to w in velox.toml form. Sections are emitted in the
+// Config field order (roadrunner first, plugins last) rather than
+// alphabetically, so generated files read like the hand-written sample.
+// Zero-valued fields are omitted.
+func (c *Config) EncodeTOML(w io.Writer) error {
+	first := true
+	for _, section := range c.sections() {
+		if !first {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		first = false
+		enc := toml.NewEncoder(w)
+		enc.SetIndentTables(false)
+		if err := enc.Encode(section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sections splits c into one single-key map per non-empty top-level field,
+// preserving the struct declaration order.
+func (c *Config) sections() []map[string]any {
+	rv := reflect.ValueOf(c).Elem()
+	rt := rv.Type()
+	out := make([]map[string]any, 0, rt.NumField())
+	for i := range rt.NumField() {
+		key := fieldKey(rt.Field(i))
+		if key == "" {
+			continue
+		}
+		if v, ok := toPlain(rv.Field(i)); ok {
+			out = append(out, map[string]any{key: v})
+		}
+	}
+	return out
+}
+
+// fieldKey returns the mapstructure key for f, or "" for fields that are not
+// part of the on-disk representation.
+func fieldKey(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	tag, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+	if tag == "-" {
+		return ""
+	}
+	if tag == "" {
+		return strings.ToLower(f.Name)
+	}
+	return tag
+}
+
+// toPlain converts v into maps, slices and scalars keyed by mapstructure tags.
+// The boolean result is false for zero values, which the encoders omit.
+func toPlain(v reflect.Value) (any, bool) {
+	switch v.Kind() { //nolint:exhaustive // remaining kinds are scalars handled by default
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return toPlain(v.Elem())
+	case reflect.Struct:
+		out := map[string]any{}
+		for i := range v.NumField() {
+			key := fieldKey(v.Type().Field(i))
+			if key == "" {
+				continue
+			}
+			if fv, ok := toPlain(v.Field(i)); ok {
+				out[key] = fv
+			}
+		}
+		return out, len(out) > 0
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil, false
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if mv, ok := toPlain(iter.Value()); ok {
+				out[iter.Key().String()] = mv
+			}
+		}
+		return out, len(out) > 0
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return nil, false
+		}
+		out := make([]any, 0, v.Len())
+		for i := range v.Len() {
+			if ev, ok := toPlain(v.Index(i)); ok {
+				out = append(out, ev)
+			}
+		}
+		return out, len(out) > 0
+	default:
+		if v.IsZero() {
+			return nil, false
+		}
+		return v.Interface(), true
+	}
+}
+
+// EncodeJSON writes c to w as indented JSON, with the same keys as
+// velox.toml. Zero-valued fields are omitted.
+func (c *Config) EncodeJSON(w io.Writer) error {
+	v, _ := toPlain(reflect.ValueOf(c).Elem())
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// EncodeYAML writes c to w as YAML, with the same keys as velox.toml.
+// Zero-valued fields are omitted.
+func (c *Config) EncodeYAML(w io.Writer) error {
+	v, _ := toPlain(reflect.ValueOf(c).Elem())
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
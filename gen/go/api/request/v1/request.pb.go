@@ -10,6 +10,7 @@ import (
 	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -86,7 +87,9 @@ type BuildRequest struct {
 	ForceRebuild bool   `protobuf:"varint,3,opt,name=force_rebuild,json=forceRebuild,proto3" json:"force_rebuild,omitempty"`
 	// target platform is required and must be a valid platform. Used to specify the platform for the build (host platform used by default)
 	TargetPlatform *Platform `protobuf:"bytes,4,opt,name=target_platform,json=targetPlatform,proto3" json:"target_platform,omitempty"`
-	// plugins are required and represent the list of plugins to be used for the build
+	// plugins represent the list of plugins to be used for the build. Required
+	// unless a profile is selected; then they are added to (or override, by
+	// module name) the profile's plugins
 	Plugins []*Plugin `protobuf:"bytes,5,rep,name=plugins,proto3" json:"plugins,omitempty"`
 	// replaces is an optional list of go.mod replace directives to apply before tidy
 	Replaces []*Replace `protobuf:"bytes,6,rep,name=replaces,proto3" json:"replaces,omitempty"`
@@ -95,7 +98,21 @@ type BuildRequest struct {
 	// race enables the race detector in the produced binary (forces CGO_ENABLED=1)
 	Race bool `protobuf:"varint,8,opt,name=race,proto3" json:"race,omitempty"`
 	// debug enables debug build flags (disables optimization/inlining, adds DWARF)
-	Debug         bool `protobuf:"varint,9,opt,name=debug,proto3" json:"debug,omitempty"`
+	Debug bool `protobuf:"varint,9,opt,name=debug,proto3" json:"debug,omitempty"`
+	// profile selects a named profile from the server's profiles config. The
+	// profile supplies plugins, debug/race, target platform and build tags;
+	// fields set in the request take precedence
+	Profile string `protobuf:"bytes,10,opt,name=profile,proto3" json:"profile,omitempty"`
+	// tags are extra build tags passed to `go build -tags`
+	Tags []string `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	// flags are extra `go build` flags, mirroring the [build] config section
+	Flags *BuildFlags `protobuf:"bytes,12,opt,name=flags,proto3" json:"flags,omitempty"`
+	// pgo_profile is a pprof CPU profile installed as cmd/rr/default.pgo for a
+	// profile-guided build. Its sha256 is part of the cache key
+	PgoProfile []byte `protobuf:"bytes,13,opt,name=pgo_profile,json=pgoProfile,proto3" json:"pgo_profile,omitempty"`
+	// go_version pins the Go release used for the build, e.g. "1.26.1". When
+	// empty, the server's configured go_version (if any) applies
+	GoVersion     string `protobuf:"bytes,14,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -193,6 +210,143 @@ func (x *BuildRequest) GetDebug() bool {
 	return false
 }
 
+func (x *BuildRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *BuildRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *BuildRequest) GetFlags() *BuildFlags {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+func (x *BuildRequest) GetPgoProfile() []byte {
+	if x != nil {
+		return x.PgoProfile
+	}
+	return nil
+}
+
+func (x *BuildRequest) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+type BuildFlags struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ldflags_vars are `-X importpath.name=value` assignments folded into the single -ldflags value.
+	// Values must not contain whitespace or quotes
+	LdflagsVars []string `protobuf:"bytes,1,rep,name=ldflags_vars,json=ldflagsVars,proto3" json:"ldflags_vars,omitempty"`
+	// gcflags are compiler flags applied to all packages. Only switches that
+	// cannot make the compiler write files on the server are accepted
+	Gcflags []string `protobuf:"bytes,2,rep,name=gcflags,proto3" json:"gcflags,omitempty"`
+	// pgo is "auto" or "off"; profile paths on the server host are not accepted
+	Pgo string `protobuf:"bytes,3,opt,name=pgo,proto3" json:"pgo,omitempty"`
+	// buildmode is "exe" (default) or "pie"
+	Buildmode string `protobuf:"bytes,4,opt,name=buildmode,proto3" json:"buildmode,omitempty"`
+	// trimpath removes file system paths from the binary; defaults to true
+	Trimpath *bool `protobuf:"varint,5,opt,name=trimpath,proto3,oneof" json:"trimpath,omitempty"`
+	// cgo enables cgo. C toolchains for cross-compiling are configured on the
+	// server, never taken from the request
+	Cgo bool `protobuf:"varint,6,opt,name=cgo,proto3" json:"cgo,omitempty"`
+	// static links cgo binaries statically (-linkmode external -extldflags -static); linux only
+	Static        bool `protobuf:"varint,7,opt,name=static,proto3" json:"static,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuildFlags) Reset() {
+	*x = BuildFlags{}
+	mi := &file_api_request_v1_request_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildFlags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildFlags) ProtoMessage() {}
+
+func (x *BuildFlags) ProtoReflect() protoreflect.Message {
+	mi := &file_api_request_v1_request_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildFlags.ProtoReflect.Descriptor instead.
+func (*BuildFlags) Descriptor() ([]byte, []int) {
+	return file_api_request_v1_request_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BuildFlags) GetLdflagsVars() []string {
+	if x != nil {
+		return x.LdflagsVars
+	}
+	return nil
+}
+
+func (x *BuildFlags) GetGcflags() []string {
+	if x != nil {
+		return x.Gcflags
+	}
+	return nil
+}
+
+func (x *BuildFlags) GetPgo() string {
+	if x != nil {
+		return x.Pgo
+	}
+	return ""
+}
+
+func (x *BuildFlags) GetBuildmode() string {
+	if x != nil {
+		return x.Buildmode
+	}
+	return ""
+}
+
+func (x *BuildFlags) GetTrimpath() bool {
+	if x != nil && x.Trimpath != nil {
+		return *x.Trimpath
+	}
+	return false
+}
+
+func (x *BuildFlags) GetCgo() bool {
+	if x != nil {
+		return x.Cgo
+	}
+	return false
+}
+
+func (x *BuildFlags) GetStatic() bool {
+	if x != nil {
+		return x.Static
+	}
+	return false
+}
+
 type Plugin struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// module name in a Go module format, for example: "github.com/roadrunner-server/velox" or "github.com/roadrunner-server/velox/v2"
@@ -205,7 +359,7 @@ type Plugin struct {
 
 func (x *Plugin) Reset() {
 	*x = Plugin{}
-	mi := &file_api_request_v1_request_proto_msgTypes[2]
+	mi := &file_api_request_v1_request_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -217,7 +371,7 @@ func (x *Plugin) String() string {
 func (*Plugin) ProtoMessage() {}
 
 func (x *Plugin) ProtoReflect() protoreflect.Message {
-	mi := &file_api_request_v1_request_proto_msgTypes[2]
+	mi := &file_api_request_v1_request_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -230,7 +384,7 @@ func (x *Plugin) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Plugin.ProtoReflect.Descriptor instead.
 func (*Plugin) Descriptor() ([]byte, []int) {
-	return file_api_request_v1_request_proto_rawDescGZIP(), []int{2}
+	return file_api_request_v1_request_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Plugin) GetModuleName() string {
@@ -260,7 +414,7 @@ type Replace struct {
 
 func (x *Replace) Reset() {
 	*x = Replace{}
-	mi := &file_api_request_v1_request_proto_msgTypes[3]
+	mi := &file_api_request_v1_request_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -272,7 +426,7 @@ func (x *Replace) String() string {
 func (*Replace) ProtoMessage() {}
 
 func (x *Replace) ProtoReflect() protoreflect.Message {
-	mi := &file_api_request_v1_request_proto_msgTypes[3]
+	mi := &file_api_request_v1_request_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -285,7 +439,7 @@ func (x *Replace) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Replace.ProtoReflect.Descriptor instead.
 func (*Replace) Descriptor() ([]byte, []int) {
-	return file_api_request_v1_request_proto_rawDescGZIP(), []int{3}
+	return file_api_request_v1_request_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Replace) GetNew() string {
@@ -314,7 +468,7 @@ type Exclude struct {
 
 func (x *Exclude) Reset() {
 	*x = Exclude{}
-	mi := &file_api_request_v1_request_proto_msgTypes[4]
+	mi := &file_api_request_v1_request_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -326,7 +480,7 @@ func (x *Exclude) String() string {
 func (*Exclude) ProtoMessage() {}
 
 func (x *Exclude) ProtoReflect() protoreflect.Message {
-	mi := &file_api_request_v1_request_proto_msgTypes[4]
+	mi := &file_api_request_v1_request_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -339,7 +493,7 @@ func (x *Exclude) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Exclude.ProtoReflect.Descriptor instead.
 func (*Exclude) Descriptor() ([]byte, []int) {
-	return file_api_request_v1_request_proto_rawDescGZIP(), []int{4}
+	return file_api_request_v1_request_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Exclude) GetModule() string {
@@ -356,14 +510,248 @@ func (x *Exclude) GetVersion() string {
 	return ""
 }
 
+// InfoRequest asks the server to describe itself; it has no fields.
+type InfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InfoRequest) Reset() {
+	*x = InfoRequest{}
+	mi := &file_api_request_v1_request_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfoRequest) ProtoMessage() {}
+
+func (x *InfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_request_v1_request_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfoRequest.ProtoReflect.Descriptor instead.
+func (*InfoRequest) Descriptor() ([]byte, []int) {
+	return file_api_request_v1_request_proto_rawDescGZIP(), []int{6}
+}
+
+type ListBuildsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page_size is the maximum number of builds returned, 50 when unset
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// The filters below are ANDed; unset ones match every build.
+	// principal is "<auth_method>:<name>", as in BuildRecord.principal
+	Principal string `protobuf:"bytes,3,opt,name=principal,proto3" json:"principal,omitempty"`
+	// outcome is "success", "cached" or an error code, e.g. "permission_denied"
+	Outcome   string `protobuf:"bytes,4,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	RrVersion string `protobuf:"bytes,5,opt,name=rr_version,json=rrVersion,proto3" json:"rr_version,omitempty"`
+	// hash is the build's cache key
+	Hash string `protobuf:"bytes,6,opt,name=hash,proto3" json:"hash,omitempty"`
+	// since and until bound the start time of the builds
+	Since         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=since,proto3" json:"since,omitempty"`
+	Until         *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBuildsRequest) Reset() {
+	*x = ListBuildsRequest{}
+	mi := &file_api_request_v1_request_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBuildsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBuildsRequest) ProtoMessage() {}
+
+func (x *ListBuildsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_request_v1_request_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBuildsRequest.ProtoReflect.Descriptor instead.
+func (*ListBuildsRequest) Descriptor() ([]byte, []int) {
+	return file_api_request_v1_request_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListBuildsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListBuildsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListBuildsRequest) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *ListBuildsRequest) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *ListBuildsRequest) GetRrVersion() string {
+	if x != nil {
+		return x.RrVersion
+	}
+	return ""
+}
+
+func (x *ListBuildsRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *ListBuildsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ListBuildsRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+type GetBuildRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBuildRequest) Reset() {
+	*x = GetBuildRequest{}
+	mi := &file_api_request_v1_request_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBuildRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBuildRequest) ProtoMessage() {}
+
+func (x *GetBuildRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_request_v1_request_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBuildRequest.ProtoReflect.Descriptor instead.
+func (*GetBuildRequest) Descriptor() ([]byte, []int) {
+	return file_api_request_v1_request_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetBuildRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DefaultPluginsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// rr_version selects the RoadRunner release whose bundled plugins are
+	// returned; the server's configured ref (or master) when empty
+	RrVersion     string `protobuf:"bytes,1,opt,name=rr_version,json=rrVersion,proto3" json:"rr_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DefaultPluginsRequest) Reset() {
+	*x = DefaultPluginsRequest{}
+	mi := &file_api_request_v1_request_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DefaultPluginsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DefaultPluginsRequest) ProtoMessage() {}
+
+func (x *DefaultPluginsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_request_v1_request_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DefaultPluginsRequest.ProtoReflect.Descriptor instead.
+func (*DefaultPluginsRequest) Descriptor() ([]byte, []int) {
+	return file_api_request_v1_request_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DefaultPluginsRequest) GetRrVersion() string {
+	if x != nil {
+		return x.RrVersion
+	}
+	return ""
+}
+
 var File_api_request_v1_request_proto protoreflect.FileDescriptor
 
 const file_api_request_v1_request_proto_rawDesc = "" +
 	"\n" +
-	"\x1capi/request/v1/request.proto\x12\x0eapi.request.v1\x1a\x1bbuf/validate/validate.proto\".\n" +
+	"\x1capi/request/v1/request.proto\x12\x0eapi.request.v1\x1a\x1bbuf/validate/validate.proto\x1a\x1fgoogle/protobuf/timestamp.proto\".\n" +
 	"\bPlatform\x12\x0e\n" +
 	"\x02os\x18\x01 \x01(\tR\x02os\x12\x12\n" +
-	"\x04arch\x18\x02 \x01(\tR\x04arch\"\x82\x05\n" +
+	"\x04arch\x18\x02 \x01(\tR\x04arch\"\xf5\a\n" +
 	"\fBuildRequest\x12*\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tB\v\xbaH\b\xc8\x01\x00r\x03\xb0\x01\x01R\trequestId\x12\xff\x01\n" +
@@ -371,12 +759,32 @@ const file_api_request_v1_request_proto_rawDesc = "" +
 	"rr_version\x18\x02 \x01(\tB\xdf\x01\xbaH\xdb\x01\xba\x01\xd4\x01\n" +
 	"\x11rr_version.format\x12~rr_version must be a semantic version starting with 'v' (e.g., v2025.1.0), 'master', or a git commit SHA (7-40 hex characters)\x1a?this.matches('^(v\\\\d+\\\\.\\\\d+\\\\.\\\\d+.*|master|[a-f0-9]{7,40})$')\xc8\x01\x01R\trrVersion\x12+\n" +
 	"\rforce_rebuild\x18\x03 \x01(\bB\x06\xbaH\x03\xc8\x01\x00R\fforceRebuild\x12I\n" +
-	"\x0ftarget_platform\x18\x04 \x01(\v2\x18.api.request.v1.PlatformB\x06\xbaH\x03\xc8\x01\x00R\x0etargetPlatform\x128\n" +
-	"\aplugins\x18\x05 \x03(\v2\x16.api.request.v1.PluginB\x06\xbaH\x03\xc8\x01\x01R\aplugins\x123\n" +
+	"\x0ftarget_platform\x18\x04 \x01(\v2\x18.api.request.v1.PlatformB\x06\xbaH\x03\xc8\x01\x00R\x0etargetPlatform\x120\n" +
+	"\aplugins\x18\x05 \x03(\v2\x16.api.request.v1.PluginR\aplugins\x123\n" +
 	"\breplaces\x18\x06 \x03(\v2\x17.api.request.v1.ReplaceR\breplaces\x123\n" +
 	"\bexcludes\x18\a \x03(\v2\x17.api.request.v1.ExcludeR\bexcludes\x12\x12\n" +
 	"\x04race\x18\b \x01(\bR\x04race\x12\x14\n" +
-	"\x05debug\x18\t \x01(\bR\x05debug\"K\n" +
+	"\x05debug\x18\t \x01(\bR\x05debug\x12\x18\n" +
+	"\aprofile\x18\n" +
+	" \x01(\tR\aprofile\x120\n" +
+	"\x04tags\x18\v \x03(\tB\x1c\xbaH\x19\x92\x01\x16\"\x14r\x122\x10^[A-Za-z0-9_.]+$R\x04tags\x120\n" +
+	"\x05flags\x18\f \x01(\v2\x1a.api.request.v1.BuildFlagsR\x05flags\x12+\n" +
+	"\vpgo_profile\x18\r \x01(\fB\n" +
+	"\xbaH\az\x05\x18\x80\x80\x80\x10R\n" +
+	"pgoProfile\x12T\n" +
+	"\n" +
+	"go_version\x18\x0e \x01(\tB5\xbaH2r02.^((go)?1\\.[0-9]+(\\.[0-9]+|(rc|beta)[0-9]+)?)?$R\tgoVersion:x\xbaHu\x1as\n" +
+	"\x10plugins.required\x121plugins are required unless a profile is selected\x1a,size(this.plugins) > 0 || this.profile != ''\"\xec\x02\n" +
+	"\n" +
+	"BuildFlags\x12c\n" +
+	"\fldflags_vars\x18\x01 \x03(\tB@\xbaH=\x92\x01:\"8r624^[A-Za-z0-9_.~/-]+\\.[A-Za-z_][A-Za-z0-9_]*=[^\\s'\"]*$R\vldflagsVars\x12A\n" +
+	"\agcflags\x18\x02 \x03(\tB'\xbaH$\x92\x01!\"\x1fr\x1dR\x02-BR\x02-CR\x02-lR\x02-NR\v-d=checkptrR\agcflags\x12$\n" +
+	"\x03pgo\x18\x03 \x01(\tB\x12\xbaH\x0fr\rR\x00R\x04autoR\x03offR\x03pgo\x128\n" +
+	"\tbuildmode\x18\x04 \x01(\tB\x1a\xbaH\x17r\x15R\x00R\x03exeR\adefaultR\x03pieR\tbuildmode\x12\x1f\n" +
+	"\btrimpath\x18\x05 \x01(\bH\x00R\btrimpath\x88\x01\x01\x12\x10\n" +
+	"\x03cgo\x18\x06 \x01(\bR\x03cgo\x12\x16\n" +
+	"\x06static\x18\a \x01(\bR\x06staticB\v\n" +
+	"\t_trimpath\"K\n" +
 	"\x06Plugin\x12'\n" +
 	"\vmodule_name\x18\x01 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\n" +
 	"moduleName\x12\x18\n" +
@@ -386,7 +794,26 @@ const file_api_request_v1_request_proto_rawDesc = "" +
 	"\x03old\x18\x02 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\x03old\"K\n" +
 	"\aExclude\x12\x1e\n" +
 	"\x06module\x18\x01 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\x06module\x12 \n" +
-	"\aversion\x18\x02 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\aversionBGZEgithub.com/roadrunner-server/velox/v3/gen/go/api/request/v1;requestV1b\x06proto3"
+	"\aversion\x18\x02 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\aversion\"\r\n" +
+	"\vInfoRequest\"\xaa\x02\n" +
+	"\x11ListBuildsRequest\x12'\n" +
+	"\tpage_size\x18\x01 \x01(\x05B\n" +
+	"\xbaH\a\x1a\x05\x18\xf4\x03(\x00R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x1c\n" +
+	"\tprincipal\x18\x03 \x01(\tR\tprincipal\x12\x18\n" +
+	"\aoutcome\x18\x04 \x01(\tR\aoutcome\x12\x1d\n" +
+	"\n" +
+	"rr_version\x18\x05 \x01(\tR\trrVersion\x12\x12\n" +
+	"\x04hash\x18\x06 \x01(\tR\x04hash\x120\n" +
+	"\x05since\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x05until\"*\n" +
+	"\x0fGetBuildRequest\x12\x17\n" +
+	"\x02id\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x02id\"\xa4\x02\n" +
+	"\x15DefaultPluginsRequest\x12\x8a\x02\n" +
+	"\n" +
+	"rr_version\x18\x01 \x01(\tB\xea\x01\xbaH\xe6\x01\xba\x01\xe2\x01\n" +
+	"\x11rr_version.format\x12~rr_version must be a semantic version starting with 'v' (e.g., v2025.1.0), 'master', or a git commit SHA (7-40 hex characters)\x1aMthis == '' || this.matches('^(v\\\\d+\\\\.\\\\d+\\\\.\\\\d+.*|master|[a-f0-9]{7,40})$')R\trrVersionBGZEgithub.com/roadrunner-server/velox/v3/gen/go/api/request/v1;requestV1b\x06proto3"
 
 var (
 	file_api_request_v1_request_proto_rawDescOnce sync.Once
@@ -400,24 +827,33 @@ func file_api_request_v1_request_proto_rawDescGZIP() []byte {
 	return file_api_request_v1_request_proto_rawDescData
 }
 
-var file_api_request_v1_request_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_api_request_v1_request_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_api_request_v1_request_proto_goTypes = []any{
-	(*Platform)(nil),     // 0: api.request.v1.Platform
-	(*BuildRequest)(nil), // 1: api.request.v1.BuildRequest
-	(*Plugin)(nil),       // 2: api.request.v1.Plugin
-	(*Replace)(nil),      // 3: api.request.v1.Replace
-	(*Exclude)(nil),      // 4: api.request.v1.Exclude
+	(*Platform)(nil),              // 0: api.request.v1.Platform
+	(*BuildRequest)(nil),          // 1: api.request.v1.BuildRequest
+	(*BuildFlags)(nil),            // 2: api.request.v1.BuildFlags
+	(*Plugin)(nil),                // 3: api.request.v1.Plugin
+	(*Replace)(nil),               // 4: api.request.v1.Replace
+	(*Exclude)(nil),               // 5: api.request.v1.Exclude
+	(*InfoRequest)(nil),           // 6: api.request.v1.InfoRequest
+	(*ListBuildsRequest)(nil),     // 7: api.request.v1.ListBuildsRequest
+	(*GetBuildRequest)(nil),       // 8: api.request.v1.GetBuildRequest
+	(*DefaultPluginsRequest)(nil), // 9: api.request.v1.DefaultPluginsRequest
+	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
 }
 var file_api_request_v1_request_proto_depIdxs = []int32{
-	0, // 0: api.request.v1.BuildRequest.target_platform:type_name -> api.request.v1.Platform
-	2, // 1: api.request.v1.BuildRequest.plugins:type_name -> api.request.v1.Plugin
-	3, // 2: api.request.v1.BuildRequest.replaces:type_name -> api.request.v1.Replace
-	4, // 3: api.request.v1.BuildRequest.excludes:type_name -> api.request.v1.Exclude
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	0,  // 0: api.request.v1.BuildRequest.target_platform:type_name -> api.request.v1.Platform
+	3,  // 1: api.request.v1.BuildRequest.plugins:type_name -> api.request.v1.Plugin
+	4,  // 2: api.request.v1.BuildRequest.replaces:type_name -> api.request.v1.Replace
+	5,  // 3: api.request.v1.BuildRequest.excludes:type_name -> api.request.v1.Exclude
+	2,  // 4: api.request.v1.BuildRequest.flags:type_name -> api.request.v1.BuildFlags
+	10, // 5: api.request.v1.ListBuildsRequest.since:type_name -> google.protobuf.Timestamp
+	10, // 6: api.request.v1.ListBuildsRequest.until:type_name -> google.protobuf.Timestamp
+	7,  // [7:7] is the sub-list for method output_type
+	7,  // [7:7] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_api_request_v1_request_proto_init() }
@@ -425,13 +861,14 @@ func file_api_request_v1_request_proto_init() {
 	if File_api_request_v1_request_proto != nil {
 		return
 	}
+	file_api_request_v1_request_proto_msgTypes[2].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_request_v1_request_proto_rawDesc), len(file_api_request_v1_request_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
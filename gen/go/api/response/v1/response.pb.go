@@ -7,8 +7,11 @@
 package responseV1
 
 import (
+	v1 "github.com/roadrunner-server/velox/v3/gen/go/api/request/v1"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,9 +25,12 @@ const (
 )
 
 type BuildResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Logs          string                 `protobuf:"bytes,2,opt,name=logs,proto3" json:"logs,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Logs  string                 `protobuf:"bytes,2,opt,name=logs,proto3" json:"logs,omitempty"`
+	// build_id is the history id of the build, for GetBuild and the
+	// /download/{build_id} endpoint
+	BuildId       string `protobuf:"bytes,3,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,14 +79,801 @@ func (x *BuildResponse) GetLogs() string {
 	return ""
 }
 
+func (x *BuildResponse) GetBuildId() string {
+	if x != nil {
+		return x.BuildId
+	}
+	return ""
+}
+
+// BuildEvent is one message of a BuildStream: log lines while the build
+// runs, then its result.
+type BuildEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*BuildEvent_Log
+	//	*BuildEvent_Result
+	Event         isBuildEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuildEvent) Reset() {
+	*x = BuildEvent{}
+	mi := &file_api_response_v1_response_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildEvent) ProtoMessage() {}
+
+func (x *BuildEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_response_v1_response_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildEvent.ProtoReflect.Descriptor instead.
+func (*BuildEvent) Descriptor() ([]byte, []int) {
+	return file_api_response_v1_response_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BuildEvent) GetEvent() isBuildEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *BuildEvent) GetLog() string {
+	if x != nil {
+		if x, ok := x.Event.(*BuildEvent_Log); ok {
+			return x.Log
+		}
+	}
+	return ""
+}
+
+func (x *BuildEvent) GetResult() *BuildResponse {
+	if x != nil {
+		if x, ok := x.Event.(*BuildEvent_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isBuildEvent_Event interface {
+	isBuildEvent_Event()
+}
+
+type BuildEvent_Log struct {
+	// log is one line of the build log, in logfmt
+	Log string `protobuf:"bytes,1,opt,name=log,proto3,oneof"`
+}
+
+type BuildEvent_Result struct {
+	Result *BuildResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*BuildEvent_Log) isBuildEvent_Event() {}
+
+func (*BuildEvent_Result) isBuildEvent_Event() {}
+
+type DefaultPluginsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// rr_version is the RoadRunner ref the plugins were read from
+	RrVersion string `protobuf:"bytes,1,opt,name=rr_version,json=rrVersion,proto3" json:"rr_version,omitempty"`
+	// plugins are the plugins upstream RoadRunner bundles at rr_version,
+	// sorted by module name
+	Plugins       []*v1.Plugin `protobuf:"bytes,2,rep,name=plugins,proto3" json:"plugins,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DefaultPluginsResponse) Reset() {
+	*x = DefaultPluginsResponse{}
+	mi := &file_api_response_v1_response_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DefaultPluginsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DefaultPluginsResponse) ProtoMessage() {}
+
+func (x *DefaultPluginsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_response_v1_response_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DefaultPluginsResponse.ProtoReflect.Descriptor instead.
+func (*DefaultPluginsResponse) Descriptor() ([]byte, []int) {
+	return file_api_response_v1_response_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DefaultPluginsResponse) GetRrVersion() string {
+	if x != nil {
+		return x.RrVersion
+	}
+	return ""
+}
+
+func (x *DefaultPluginsResponse) GetPlugins() []*v1.Plugin {
+	if x != nil {
+		return x.Plugins
+	}
+	return nil
+}
+
+type InfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// velox_version is the version of the velox server
+	VeloxVersion string `protobuf:"bytes,1,opt,name=velox_version,json=veloxVersion,proto3" json:"velox_version,omitempty"`
+	// go_version is the version of the go toolchain builds use by default
+	// (`go env GOVERSION`); empty when no toolchain is available
+	GoVersion string `protobuf:"bytes,2,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	// runtime_version is the Go version the server itself was built with
+	RuntimeVersion string `protobuf:"bytes,3,opt,name=runtime_version,json=runtimeVersion,proto3" json:"runtime_version,omitempty"`
+	// platforms are the targets the server can build for (`go tool dist list`,
+	// without the unsupported windows targets)
+	Platforms []*v1.Platform `protobuf:"bytes,4,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	Cache     *CacheStats    `protobuf:"bytes,5,opt,name=cache,proto3" json:"cache,omitempty"`
+	// running_builds are the builds in progress, oldest first
+	RunningBuilds []*RunningBuild `protobuf:"bytes,6,rep,name=running_builds,json=runningBuilds,proto3" json:"running_builds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InfoResponse) Reset() {
+	*x = InfoResponse{}
+	mi := &file_api_response_v1_response_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfoResponse) ProtoMessage() {}
+
+func (x *InfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_response_v1_response_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfoResponse.ProtoReflect.Descriptor instead.
+func (*InfoResponse) Descriptor() ([]byte, []int) {
+	return file_api_response_v1_response_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *InfoResponse) GetVeloxVersion() string {
+	if x != nil {
+		return x.VeloxVersion
+	}
+	return ""
+}
+
+func (x *InfoResponse) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+func (x *InfoResponse) GetRuntimeVersion() string {
+	if x != nil {
+		return x.RuntimeVersion
+	}
+	return ""
+}
+
+func (x *InfoResponse) GetPlatforms() []*v1.Platform {
+	if x != nil {
+		return x.Platforms
+	}
+	return nil
+}
+
+func (x *InfoResponse) GetCache() *CacheStats {
+	if x != nil {
+		return x.Cache
+	}
+	return nil
+}
+
+func (x *InfoResponse) GetRunningBuilds() []*RunningBuild {
+	if x != nil {
+		return x.RunningBuilds
+	}
+	return nil
+}
+
+type CacheStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// binary_entries is the number of built binaries kept for reuse
+	BinaryEntries uint32 `protobuf:"varint,1,opt,name=binary_entries,json=binaryEntries,proto3" json:"binary_entries,omitempty"`
+	// hits and misses count binary cache lookups since the server started
+	Hits   uint64 `protobuf:"varint,2,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses uint64 `protobuf:"varint,3,opt,name=misses,proto3" json:"misses,omitempty"`
+	// archive_entries is the number of RoadRunner source archives kept in memory
+	ArchiveEntries uint32 `protobuf:"varint,4,opt,name=archive_entries,json=archiveEntries,proto3" json:"archive_entries,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CacheStats) Reset() {
+	*x = CacheStats{}
+	mi := &file_api_response_v1_response_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStats) ProtoMessage() {}
+
+func (x *CacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_response_v1_response_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStats.ProtoReflect.Descriptor instead.
+func (*CacheStats) Descriptor() ([]byte, []int) {
+	return file_api_response_v1_response_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CacheStats) GetBinaryEntries() uint32 {
+	if x != nil {
+		return x.BinaryEntries
+	}
+	return 0
+}
+
+func (x *CacheStats) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheStats) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *CacheStats) GetArchiveEntries() uint32 {
+	if x != nil {
+		return x.ArchiveEntries
+	}
+	return 0
+}
+
+type RunningBuild struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// hash is the build's cache key
+	Hash           string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	RrVersion      string                 `protobuf:"bytes,2,opt,name=rr_version,json=rrVersion,proto3" json:"rr_version,omitempty"`
+	TargetPlatform *v1.Platform           `protobuf:"bytes,3,opt,name=target_platform,json=targetPlatform,proto3" json:"target_platform,omitempty"`
+	Plugins        uint32                 `protobuf:"varint,4,opt,name=plugins,proto3" json:"plugins,omitempty"`
+	StartedAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RunningBuild) Reset() {
+	*x = RunningBuild{}
+	mi := &file_api_response_v1_response_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunningBuild) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunningBuild) ProtoMessage() {}
+
+func (x *RunningBuild) ProtoReflect() protoreflect.Message {
+	mi := &file_api_response_v1_response_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunningBuild.ProtoReflect.Descriptor instead.
+func (*RunningBuild) Descriptor() ([]byte, []int) {
+	return file_api_response_v1_response_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RunningBuild) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *RunningBuild) GetRrVersion() string {
+	if x != nil {
+		return x.RrVersion
+	}
+	return ""
+}
+
+func (x *RunningBuild) GetTargetPlatform() *v1.Platform {
+	if x != nil {
+		return x.TargetPlatform
+	}
+	return nil
+}
+
+func (x *RunningBuild) GetPlugins() uint32 {
+	if x != nil {
+		return x.Plugins
+	}
+	return 0
+}
+
+func (x *RunningBuild) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+// BuildRecord is the history entry of one Build request, rejected ones
+// included.
+type BuildRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id identifies the build; ids sort by start time
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// request is the request after profile expansion, with the values of
+	// flags.ldflags_vars redacted and without the pgo_profile bytes
+	Request *v1.BuildRequest `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	// pgo_profile_sha256 is the digest of the uploaded PGO profile
+	PgoProfileSha256 string `protobuf:"bytes,3,opt,name=pgo_profile_sha256,json=pgoProfileSha256,proto3" json:"pgo_profile_sha256,omitempty"`
+	// principal, auth_method and policy describe the authenticated caller;
+	// they are empty when the server runs without auth. principal is
+	// "<auth_method>:<name>", e.g. "jwt:alice", since names are only unique
+	// within a method
+	Principal  string `protobuf:"bytes,4,opt,name=principal,proto3" json:"principal,omitempty"`
+	AuthMethod string `protobuf:"bytes,5,opt,name=auth_method,json=authMethod,proto3" json:"auth_method,omitempty"`
+	Policy     string `protobuf:"bytes,6,opt,name=policy,proto3" json:"policy,omitempty"`
+	// peer is the remote address of the caller
+	Peer string `protobuf:"bytes,7,opt,name=peer,proto3" json:"peer,omitempty"`
+	// hash is the cache key; empty when the request was rejected before it
+	// was computed
+	Hash      string                 `protobuf:"bytes,8,opt,name=hash,proto3" json:"hash,omitempty"`
+	StartedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Duration  *durationpb.Duration   `protobuf:"bytes,10,opt,name=duration,proto3" json:"duration,omitempty"`
+	Stages    []*BuildStage          `protobuf:"bytes,11,rep,name=stages,proto3" json:"stages,omitempty"`
+	// cpu_time is the CPU time of the build's go commands
+	CpuTime *durationpb.Duration `protobuf:"bytes,12,opt,name=cpu_time,json=cpuTime,proto3" json:"cpu_time,omitempty"`
+	// outcome is "success", "cached" or the error code, e.g. "permission_denied"
+	Outcome string `protobuf:"bytes,13,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	Error   string `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`
+	// artifact is the binary served; a cached one carries its path only, the
+	// build that produced it has the digest
+	Artifact      *Artifact `protobuf:"bytes,15,opt,name=artifact,proto3" json:"artifact,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuildRecord) Reset() {
+	*x = BuildRecord{}
+	mi := &file_api_response_v1_response_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildRecord) ProtoMessage() {}
+
+func (x *BuildRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_api_response_v1_response_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildRecord.ProtoReflect.Descriptor instead.
+func (*BuildRecord) Descriptor() ([]byte, []int) {
+	return file_api_response_v1_response_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BuildRecord) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BuildRecord) GetRequest() *v1.BuildRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *BuildRecord) GetPgoProfileSha256() string {
+	if x != nil {
+		return x.PgoProfileSha256
+	}
+	return ""
+}
+
+func (x *BuildRecord) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *BuildRecord) GetAuthMethod() string {
+	if x != nil {
+		return x.AuthMethod
+	}
+	return ""
+}
+
+func (x *BuildRecord) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *BuildRecord) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *BuildRecord) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *BuildRecord) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *BuildRecord) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *BuildRecord) GetStages() []*BuildStage {
+	if x != nil {
+		return x.Stages
+	}
+	return nil
+}
+
+func (x *BuildRecord) GetCpuTime() *durationpb.Duration {
+	if x != nil {
+		return x.CpuTime
+	}
+	return nil
+}
+
+func (x *BuildRecord) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *BuildRecord) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *BuildRecord) GetArtifact() *Artifact {
+	if x != nil {
+		return x.Artifact
+	}
+	return nil
+}
+
+type BuildStage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Duration      *durationpb.Duration   `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuildStage) Reset() {
+	*x = BuildStage{}
+	mi := &file_api_response_v1_response_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildStage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildStage) ProtoMessage() {}
+
+func (x *BuildStage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_response_v1_response_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildStage.ProtoReflect.Descriptor instead.
+func (*BuildStage) Descriptor() ([]byte, []int) {
+	return file_api_response_v1_response_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BuildStage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BuildStage) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type Artifact struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	mi := &file_api_response_v1_response_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_api_response_v1_response_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_api_response_v1_response_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Artifact) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Artifact) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Artifact) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+type ListBuildsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// builds are the matching builds, newest first
+	Builds []*BuildRecord `protobuf:"bytes,1,rep,name=builds,proto3" json:"builds,omitempty"`
+	// next_page_token fetches the next page; empty on the last one
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBuildsResponse) Reset() {
+	*x = ListBuildsResponse{}
+	mi := &file_api_response_v1_response_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBuildsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBuildsResponse) ProtoMessage() {}
+
+func (x *ListBuildsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_response_v1_response_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBuildsResponse.ProtoReflect.Descriptor instead.
+func (*ListBuildsResponse) Descriptor() ([]byte, []int) {
+	return file_api_response_v1_response_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListBuildsResponse) GetBuilds() []*BuildRecord {
+	if x != nil {
+		return x.Builds
+	}
+	return nil
+}
+
+func (x *ListBuildsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
 var File_api_response_v1_response_proto protoreflect.FileDescriptor
 
 const file_api_response_v1_response_proto_rawDesc = "" +
 	"\n" +
-	"\x1eapi/response/v1/response.proto\x12\x0fapi.response.v1\"7\n" +
+	"\x1eapi/response/v1/response.proto\x12\x0fapi.response.v1\x1a\x1capi/request/v1/request.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"R\n" +
 	"\rBuildResponse\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
-	"\x04logs\x18\x02 \x01(\tR\x04logsBIZGgithub.com/roadrunner-server/velox/v3/gen/go/api/response/v1;responseV1b\x06proto3"
+	"\x04logs\x18\x02 \x01(\tR\x04logs\x12\x19\n" +
+	"\bbuild_id\x18\x03 \x01(\tR\abuildId\"c\n" +
+	"\n" +
+	"BuildEvent\x12\x12\n" +
+	"\x03log\x18\x01 \x01(\tH\x00R\x03log\x128\n" +
+	"\x06result\x18\x02 \x01(\v2\x1e.api.response.v1.BuildResponseH\x00R\x06resultB\a\n" +
+	"\x05event\"i\n" +
+	"\x16DefaultPluginsResponse\x12\x1d\n" +
+	"\n" +
+	"rr_version\x18\x01 \x01(\tR\trrVersion\x120\n" +
+	"\aplugins\x18\x02 \x03(\v2\x16.api.request.v1.PluginR\aplugins\"\xac\x02\n" +
+	"\fInfoResponse\x12#\n" +
+	"\rvelox_version\x18\x01 \x01(\tR\fveloxVersion\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x02 \x01(\tR\tgoVersion\x12'\n" +
+	"\x0fruntime_version\x18\x03 \x01(\tR\x0eruntimeVersion\x126\n" +
+	"\tplatforms\x18\x04 \x03(\v2\x18.api.request.v1.PlatformR\tplatforms\x121\n" +
+	"\x05cache\x18\x05 \x01(\v2\x1b.api.response.v1.CacheStatsR\x05cache\x12D\n" +
+	"\x0erunning_builds\x18\x06 \x03(\v2\x1d.api.response.v1.RunningBuildR\rrunningBuilds\"\x88\x01\n" +
+	"\n" +
+	"CacheStats\x12%\n" +
+	"\x0ebinary_entries\x18\x01 \x01(\rR\rbinaryEntries\x12\x12\n" +
+	"\x04hits\x18\x02 \x01(\x04R\x04hits\x12\x16\n" +
+	"\x06misses\x18\x03 \x01(\x04R\x06misses\x12'\n" +
+	"\x0farchive_entries\x18\x04 \x01(\rR\x0earchiveEntries\"\xd9\x01\n" +
+	"\fRunningBuild\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x1d\n" +
+	"\n" +
+	"rr_version\x18\x02 \x01(\tR\trrVersion\x12A\n" +
+	"\x0ftarget_platform\x18\x03 \x01(\v2\x18.api.request.v1.PlatformR\x0etargetPlatform\x12\x18\n" +
+	"\aplugins\x18\x04 \x01(\rR\aplugins\x129\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\"\xc6\x04\n" +
+	"\vBuildRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x126\n" +
+	"\arequest\x18\x02 \x01(\v2\x1c.api.request.v1.BuildRequestR\arequest\x12,\n" +
+	"\x12pgo_profile_sha256\x18\x03 \x01(\tR\x10pgoProfileSha256\x12\x1c\n" +
+	"\tprincipal\x18\x04 \x01(\tR\tprincipal\x12\x1f\n" +
+	"\vauth_method\x18\x05 \x01(\tR\n" +
+	"authMethod\x12\x16\n" +
+	"\x06policy\x18\x06 \x01(\tR\x06policy\x12\x12\n" +
+	"\x04peer\x18\a \x01(\tR\x04peer\x12\x12\n" +
+	"\x04hash\x18\b \x01(\tR\x04hash\x129\n" +
+	"\n" +
+	"started_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x125\n" +
+	"\bduration\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\bduration\x123\n" +
+	"\x06stages\x18\v \x03(\v2\x1b.api.response.v1.BuildStageR\x06stages\x124\n" +
+	"\bcpu_time\x18\f \x01(\v2\x19.google.protobuf.DurationR\acpuTime\x12\x18\n" +
+	"\aoutcome\x18\r \x01(\tR\aoutcome\x12\x14\n" +
+	"\x05error\x18\x0e \x01(\tR\x05error\x125\n" +
+	"\bartifact\x18\x0f \x01(\v2\x19.api.response.v1.ArtifactR\bartifact\"W\n" +
+	"\n" +
+	"BuildStage\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x125\n" +
+	"\bduration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bduration\"J\n" +
+	"\bArtifact\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\"r\n" +
+	"\x12ListBuildsResponse\x124\n" +
+	"\x06builds\x18\x01 \x03(\v2\x1c.api.response.v1.BuildRecordR\x06builds\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageTokenBIZGgithub.com/roadrunner-server/velox/v3/gen/go/api/response/v1;responseV1b\x06proto3"
 
 var (
 	file_api_response_v1_response_proto_rawDescOnce sync.Once
@@ -94,16 +887,45 @@ func file_api_response_v1_response_proto_rawDescGZIP() []byte {
 	return file_api_response_v1_response_proto_rawDescData
 }
 
-var file_api_response_v1_response_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_api_response_v1_response_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_api_response_v1_response_proto_goTypes = []any{
-	(*BuildResponse)(nil), // 0: api.response.v1.BuildResponse
+	(*BuildResponse)(nil),          // 0: api.response.v1.BuildResponse
+	(*BuildEvent)(nil),             // 1: api.response.v1.BuildEvent
+	(*DefaultPluginsResponse)(nil), // 2: api.response.v1.DefaultPluginsResponse
+	(*InfoResponse)(nil),           // 3: api.response.v1.InfoResponse
+	(*CacheStats)(nil),             // 4: api.response.v1.CacheStats
+	(*RunningBuild)(nil),           // 5: api.response.v1.RunningBuild
+	(*BuildRecord)(nil),            // 6: api.response.v1.BuildRecord
+	(*BuildStage)(nil),             // 7: api.response.v1.BuildStage
+	(*Artifact)(nil),               // 8: api.response.v1.Artifact
+	(*ListBuildsResponse)(nil),     // 9: api.response.v1.ListBuildsResponse
+	(*v1.Plugin)(nil),              // 10: api.request.v1.Plugin
+	(*v1.Platform)(nil),            // 11: api.request.v1.Platform
+	(*timestamppb.Timestamp)(nil),  // 12: google.protobuf.Timestamp
+	(*v1.BuildRequest)(nil),        // 13: api.request.v1.BuildRequest
+	(*durationpb.Duration)(nil),    // 14: google.protobuf.Duration
 }
 var file_api_response_v1_response_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: api.response.v1.BuildEvent.result:type_name -> api.response.v1.BuildResponse
+	10, // 1: api.response.v1.DefaultPluginsResponse.plugins:type_name -> api.request.v1.Plugin
+	11, // 2: api.response.v1.InfoResponse.platforms:type_name -> api.request.v1.Platform
+	4,  // 3: api.response.v1.InfoResponse.cache:type_name -> api.response.v1.CacheStats
+	5,  // 4: api.response.v1.InfoResponse.running_builds:type_name -> api.response.v1.RunningBuild
+	11, // 5: api.response.v1.RunningBuild.target_platform:type_name -> api.request.v1.Platform
+	12, // 6: api.response.v1.RunningBuild.started_at:type_name -> google.protobuf.Timestamp
+	13, // 7: api.response.v1.BuildRecord.request:type_name -> api.request.v1.BuildRequest
+	12, // 8: api.response.v1.BuildRecord.started_at:type_name -> google.protobuf.Timestamp
+	14, // 9: api.response.v1.BuildRecord.duration:type_name -> google.protobuf.Duration
+	7,  // 10: api.response.v1.BuildRecord.stages:type_name -> api.response.v1.BuildStage
+	14, // 11: api.response.v1.BuildRecord.cpu_time:type_name -> google.protobuf.Duration
+	8,  // 12: api.response.v1.BuildRecord.artifact:type_name -> api.response.v1.Artifact
+	14, // 13: api.response.v1.BuildStage.duration:type_name -> google.protobuf.Duration
+	6,  // 14: api.response.v1.ListBuildsResponse.builds:type_name -> api.response.v1.BuildRecord
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_api_response_v1_response_proto_init() }
@@ -111,13 +933,17 @@ func file_api_response_v1_response_proto_init() {
 	if File_api_response_v1_response_proto != nil {
 		return
 	}
+	file_api_response_v1_response_proto_msgTypes[1].OneofWrappers = []any{
+		(*BuildEvent_Log)(nil),
+		(*BuildEvent_Result)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_response_v1_response_proto_rawDesc), len(file_api_response_v1_response_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
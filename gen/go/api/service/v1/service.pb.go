@@ -26,22 +26,47 @@ var File_api_service_v1_service_proto protoreflect.FileDescriptor
 
 const file_api_service_v1_service_proto_rawDesc = "" +
 	"\n" +
-	"\x1capi/service/v1/service.proto\x12\x0eapi.service.v1\x1a\x1capi/request/v1/request.proto\x1a\x1eapi/response/v1/response.proto2U\n" +
+	"\x1capi/service/v1/service.proto\x12\x0eapi.service.v1\x1a\x1capi/request/v1/request.proto\x1a\x1eapi/response/v1/response.proto2\xfc\x03\n" +
 	"\fBuildService\x12E\n" +
-	"\x05Build\x12\x1c.api.request.v1.BuildRequest\x1a\x1e.api.response.v1.BuildResponseBGZEgithub.com/roadrunner-server/velox/v3/gen/go/api/service/v1;serviceV1b\x06proto3"
+	"\x05Build\x12\x1c.api.request.v1.BuildRequest\x1a\x1e.api.response.v1.BuildResponse\x12J\n" +
+	"\vBuildStream\x12\x1c.api.request.v1.BuildRequest\x1a\x1b.api.response.v1.BuildEvent0\x01\x12G\n" +
+	"\x04Info\x12\x1b.api.request.v1.InfoRequest\x1a\x1d.api.response.v1.InfoResponse\"\x03\x90\x02\x01\x12Y\n" +
+	"\n" +
+	"ListBuilds\x12!.api.request.v1.ListBuildsRequest\x1a#.api.response.v1.ListBuildsResponse\"\x03\x90\x02\x01\x12N\n" +
+	"\bGetBuild\x12\x1f.api.request.v1.GetBuildRequest\x1a\x1c.api.response.v1.BuildRecord\"\x03\x90\x02\x01\x12e\n" +
+	"\x0eDefaultPlugins\x12%.api.request.v1.DefaultPluginsRequest\x1a'.api.response.v1.DefaultPluginsResponse\"\x03\x90\x02\x01BGZEgithub.com/roadrunner-server/velox/v3/gen/go/api/service/v1;serviceV1b\x06proto3"
 
 var file_api_service_v1_service_proto_goTypes = []any{
-	(*v1.BuildRequest)(nil),   // 0: api.request.v1.BuildRequest
-	(*v11.BuildResponse)(nil), // 1: api.response.v1.BuildResponse
+	(*v1.BuildRequest)(nil),            // 0: api.request.v1.BuildRequest
+	(*v1.InfoRequest)(nil),             // 1: api.request.v1.InfoRequest
+	(*v1.ListBuildsRequest)(nil),       // 2: api.request.v1.ListBuildsRequest
+	(*v1.GetBuildRequest)(nil),         // 3: api.request.v1.GetBuildRequest
+	(*v1.DefaultPluginsRequest)(nil),   // 4: api.request.v1.DefaultPluginsRequest
+	(*v11.BuildResponse)(nil),          // 5: api.response.v1.BuildResponse
+	(*v11.BuildEvent)(nil),             // 6: api.response.v1.BuildEvent
+	(*v11.InfoResponse)(nil),           // 7: api.response.v1.InfoResponse
+	(*v11.ListBuildsResponse)(nil),     // 8: api.response.v1.ListBuildsResponse
+	(*v11.BuildRecord)(nil),            // 9: api.response.v1.BuildRecord
+	(*v11.DefaultPluginsResponse)(nil), // 10: api.response.v1.DefaultPluginsResponse
 }
 var file_api_service_v1_service_proto_depIdxs = []int32{
-	0, // 0: api.service.v1.BuildService.Build:input_type -> api.request.v1.BuildRequest
-	1, // 1: api.service.v1.BuildService.Build:output_type -> api.response.v1.BuildResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: api.service.v1.BuildService.Build:input_type -> api.request.v1.BuildRequest
+	0,  // 1: api.service.v1.BuildService.BuildStream:input_type -> api.request.v1.BuildRequest
+	1,  // 2: api.service.v1.BuildService.Info:input_type -> api.request.v1.InfoRequest
+	2,  // 3: api.service.v1.BuildService.ListBuilds:input_type -> api.request.v1.ListBuildsRequest
+	3,  // 4: api.service.v1.BuildService.GetBuild:input_type -> api.request.v1.GetBuildRequest
+	4,  // 5: api.service.v1.BuildService.DefaultPlugins:input_type -> api.request.v1.DefaultPluginsRequest
+	5,  // 6: api.service.v1.BuildService.Build:output_type -> api.response.v1.BuildResponse
+	6,  // 7: api.service.v1.BuildService.BuildStream:output_type -> api.response.v1.BuildEvent
+	7,  // 8: api.service.v1.BuildService.Info:output_type -> api.response.v1.InfoResponse
+	8,  // 9: api.service.v1.BuildService.ListBuilds:output_type -> api.response.v1.ListBuildsResponse
+	9,  // 10: api.service.v1.BuildService.GetBuild:output_type -> api.response.v1.BuildRecord
+	10, // 11: api.service.v1.BuildService.DefaultPlugins:output_type -> api.response.v1.DefaultPluginsResponse
+	6,  // [6:12] is the sub-list for method output_type
+	0,  // [0:6] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_api_service_v1_service_proto_init() }
@@ -37,11 +37,38 @@ const (
 const (
 	// BuildServiceBuildProcedure is the fully-qualified name of the BuildService's Build RPC.
 	BuildServiceBuildProcedure = "/api.service.v1.BuildService/Build"
+	// BuildServiceBuildStreamProcedure is the fully-qualified name of the BuildService's BuildStream
+	// RPC.
+	BuildServiceBuildStreamProcedure = "/api.service.v1.BuildService/BuildStream"
+	// BuildServiceInfoProcedure is the fully-qualified name of the BuildService's Info RPC.
+	BuildServiceInfoProcedure = "/api.service.v1.BuildService/Info"
+	// BuildServiceListBuildsProcedure is the fully-qualified name of the BuildService's ListBuilds RPC.
+	BuildServiceListBuildsProcedure = "/api.service.v1.BuildService/ListBuilds"
+	// BuildServiceGetBuildProcedure is the fully-qualified name of the BuildService's GetBuild RPC.
+	BuildServiceGetBuildProcedure = "/api.service.v1.BuildService/GetBuild"
+	// BuildServiceDefaultPluginsProcedure is the fully-qualified name of the BuildService's
+	// DefaultPlugins RPC.
+	BuildServiceDefaultPluginsProcedure = "/api.service.v1.BuildService/DefaultPlugins"
 )
 
 // BuildServiceClient is a client for the api.service.v1.BuildService service.
 type BuildServiceClient interface {
 	Build(context.Context, *connect.Request[v1.BuildRequest]) (*connect.Response[v11.BuildResponse], error)
+	// BuildStream runs a Build and streams its log while it runs, ending with
+	// the result.
+	BuildStream(context.Context, *connect.Request[v1.BuildRequest]) (*connect.ServerStreamForClient[v11.BuildEvent], error)
+	// Info describes the server: versions, target platforms, cache statistics
+	// and the builds in progress.
+	Info(context.Context, *connect.Request[v1.InfoRequest]) (*connect.Response[v11.InfoResponse], error)
+	// ListBuilds pages through the build history, newest first. Callers see
+	// their own builds unless their policy sets view_all_builds; anonymous
+	// callers, who share one principal, then see none.
+	ListBuilds(context.Context, *connect.Request[v1.ListBuildsRequest]) (*connect.Response[v11.ListBuildsResponse], error)
+	// GetBuild returns one history entry by id.
+	GetBuild(context.Context, *connect.Request[v1.GetBuildRequest]) (*connect.Response[v11.BuildRecord], error)
+	// DefaultPlugins lists the plugins upstream RoadRunner bundles, the
+	// starting point for a plugin set.
+	DefaultPlugins(context.Context, *connect.Request[v1.DefaultPluginsRequest]) (*connect.Response[v11.DefaultPluginsResponse], error)
 }
 
 // NewBuildServiceClient constructs a client for the api.service.v1.BuildService service. By
@@ -61,12 +88,51 @@ func NewBuildServiceClient(httpClient connect.HTTPClient, baseURL string, opts .
 			connect.WithSchema(buildServiceMethods.ByName("Build")),
 			connect.WithClientOptions(opts...),
 		),
+		buildStream: connect.NewClient[v1.BuildRequest, v11.BuildEvent](
+			httpClient,
+			baseURL+BuildServiceBuildStreamProcedure,
+			connect.WithSchema(buildServiceMethods.ByName("BuildStream")),
+			connect.WithClientOptions(opts...),
+		),
+		info: connect.NewClient[v1.InfoRequest, v11.InfoResponse](
+			httpClient,
+			baseURL+BuildServiceInfoProcedure,
+			connect.WithSchema(buildServiceMethods.ByName("Info")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		listBuilds: connect.NewClient[v1.ListBuildsRequest, v11.ListBuildsResponse](
+			httpClient,
+			baseURL+BuildServiceListBuildsProcedure,
+			connect.WithSchema(buildServiceMethods.ByName("ListBuilds")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		getBuild: connect.NewClient[v1.GetBuildRequest, v11.BuildRecord](
+			httpClient,
+			baseURL+BuildServiceGetBuildProcedure,
+			connect.WithSchema(buildServiceMethods.ByName("GetBuild")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		defaultPlugins: connect.NewClient[v1.DefaultPluginsRequest, v11.DefaultPluginsResponse](
+			httpClient,
+			baseURL+BuildServiceDefaultPluginsProcedure,
+			connect.WithSchema(buildServiceMethods.ByName("DefaultPlugins")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // buildServiceClient implements BuildServiceClient.
 type buildServiceClient struct {
-	build *connect.Client[v1.BuildRequest, v11.BuildResponse]
+	build          *connect.Client[v1.BuildRequest, v11.BuildResponse]
+	buildStream    *connect.Client[v1.BuildRequest, v11.BuildEvent]
+	info           *connect.Client[v1.InfoRequest, v11.InfoResponse]
+	listBuilds     *connect.Client[v1.ListBuildsRequest, v11.ListBuildsResponse]
+	getBuild       *connect.Client[v1.GetBuildRequest, v11.BuildRecord]
+	defaultPlugins *connect.Client[v1.DefaultPluginsRequest, v11.DefaultPluginsResponse]
 }
 
 // Build calls api.service.v1.BuildService.Build.
@@ -74,9 +140,49 @@ func (c *buildServiceClient) Build(ctx context.Context, req *connect.Request[v1.
 	return c.build.CallUnary(ctx, req)
 }
 
+// BuildStream calls api.service.v1.BuildService.BuildStream.
+func (c *buildServiceClient) BuildStream(ctx context.Context, req *connect.Request[v1.BuildRequest]) (*connect.ServerStreamForClient[v11.BuildEvent], error) {
+	return c.buildStream.CallServerStream(ctx, req)
+}
+
+// Info calls api.service.v1.BuildService.Info.
+func (c *buildServiceClient) Info(ctx context.Context, req *connect.Request[v1.InfoRequest]) (*connect.Response[v11.InfoResponse], error) {
+	return c.info.CallUnary(ctx, req)
+}
+
+// ListBuilds calls api.service.v1.BuildService.ListBuilds.
+func (c *buildServiceClient) ListBuilds(ctx context.Context, req *connect.Request[v1.ListBuildsRequest]) (*connect.Response[v11.ListBuildsResponse], error) {
+	return c.listBuilds.CallUnary(ctx, req)
+}
+
+// GetBuild calls api.service.v1.BuildService.GetBuild.
+func (c *buildServiceClient) GetBuild(ctx context.Context, req *connect.Request[v1.GetBuildRequest]) (*connect.Response[v11.BuildRecord], error) {
+	return c.getBuild.CallUnary(ctx, req)
+}
+
+// DefaultPlugins calls api.service.v1.BuildService.DefaultPlugins.
+func (c *buildServiceClient) DefaultPlugins(ctx context.Context, req *connect.Request[v1.DefaultPluginsRequest]) (*connect.Response[v11.DefaultPluginsResponse], error) {
+	return c.defaultPlugins.CallUnary(ctx, req)
+}
+
 // BuildServiceHandler is an implementation of the api.service.v1.BuildService service.
 type BuildServiceHandler interface {
 	Build(context.Context, *connect.Request[v1.BuildRequest]) (*connect.Response[v11.BuildResponse], error)
+	// BuildStream runs a Build and streams its log while it runs, ending with
+	// the result.
+	BuildStream(context.Context, *connect.Request[v1.BuildRequest], *connect.ServerStream[v11.BuildEvent]) error
+	// Info describes the server: versions, target platforms, cache statistics
+	// and the builds in progress.
+	Info(context.Context, *connect.Request[v1.InfoRequest]) (*connect.Response[v11.InfoResponse], error)
+	// ListBuilds pages through the build history, newest first. Callers see
+	// their own builds unless their policy sets view_all_builds; anonymous
+	// callers, who share one principal, then see none.
+	ListBuilds(context.Context, *connect.Request[v1.ListBuildsRequest]) (*connect.Response[v11.ListBuildsResponse], error)
+	// GetBuild returns one history entry by id.
+	GetBuild(context.Context, *connect.Request[v1.GetBuildRequest]) (*connect.Response[v11.BuildRecord], error)
+	// DefaultPlugins lists the plugins upstream RoadRunner bundles, the
+	// starting point for a plugin set.
+	DefaultPlugins(context.Context, *connect.Request[v1.DefaultPluginsRequest]) (*connect.Response[v11.DefaultPluginsResponse], error)
 }
 
 // NewBuildServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -92,10 +198,54 @@ func NewBuildServiceHandler(svc BuildServiceHandler, opts ...connect.HandlerOpti
 		connect.WithSchema(buildServiceMethods.ByName("Build")),
 		connect.WithHandlerOptions(opts...),
 	)
+	buildServiceBuildStreamHandler := connect.NewServerStreamHandler(
+		BuildServiceBuildStreamProcedure,
+		svc.BuildStream,
+		connect.WithSchema(buildServiceMethods.ByName("BuildStream")),
+		connect.WithHandlerOptions(opts...),
+	)
+	buildServiceInfoHandler := connect.NewUnaryHandler(
+		BuildServiceInfoProcedure,
+		svc.Info,
+		connect.WithSchema(buildServiceMethods.ByName("Info")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	buildServiceListBuildsHandler := connect.NewUnaryHandler(
+		BuildServiceListBuildsProcedure,
+		svc.ListBuilds,
+		connect.WithSchema(buildServiceMethods.ByName("ListBuilds")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	buildServiceGetBuildHandler := connect.NewUnaryHandler(
+		BuildServiceGetBuildProcedure,
+		svc.GetBuild,
+		connect.WithSchema(buildServiceMethods.ByName("GetBuild")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	buildServiceDefaultPluginsHandler := connect.NewUnaryHandler(
+		BuildServiceDefaultPluginsProcedure,
+		svc.DefaultPlugins,
+		connect.WithSchema(buildServiceMethods.ByName("DefaultPlugins")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/api.service.v1.BuildService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case BuildServiceBuildProcedure:
 			buildServiceBuildHandler.ServeHTTP(w, r)
+		case BuildServiceBuildStreamProcedure:
+			buildServiceBuildStreamHandler.ServeHTTP(w, r)
+		case BuildServiceInfoProcedure:
+			buildServiceInfoHandler.ServeHTTP(w, r)
+		case BuildServiceListBuildsProcedure:
+			buildServiceListBuildsHandler.ServeHTTP(w, r)
+		case BuildServiceGetBuildProcedure:
+			buildServiceGetBuildHandler.ServeHTTP(w, r)
+		case BuildServiceDefaultPluginsProcedure:
+			buildServiceDefaultPluginsHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -108,3 +258,23 @@ type UnimplementedBuildServiceHandler struct{}
 func (UnimplementedBuildServiceHandler) Build(context.Context, *connect.Request[v1.BuildRequest]) (*connect.Response[v11.BuildResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.service.v1.BuildService.Build is not implemented"))
 }
+
+func (UnimplementedBuildServiceHandler) BuildStream(context.Context, *connect.Request[v1.BuildRequest], *connect.ServerStream[v11.BuildEvent]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("api.service.v1.BuildService.BuildStream is not implemented"))
+}
+
+func (UnimplementedBuildServiceHandler) Info(context.Context, *connect.Request[v1.InfoRequest]) (*connect.Response[v11.InfoResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.service.v1.BuildService.Info is not implemented"))
+}
+
+func (UnimplementedBuildServiceHandler) ListBuilds(context.Context, *connect.Request[v1.ListBuildsRequest]) (*connect.Response[v11.ListBuildsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.service.v1.BuildService.ListBuilds is not implemented"))
+}
+
+func (UnimplementedBuildServiceHandler) GetBuild(context.Context, *connect.Request[v1.GetBuildRequest]) (*connect.Response[v11.BuildRecord], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.service.v1.BuildService.GetBuild is not implemented"))
+}
+
+func (UnimplementedBuildServiceHandler) DefaultPlugins(context.Context, *connect.Request[v1.DefaultPluginsRequest]) (*connect.Response[v11.DefaultPluginsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.service.v1.BuildService.DefaultPlugins is not implemented"))
+}
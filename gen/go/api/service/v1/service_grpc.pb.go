@@ -21,7 +21,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	BuildService_Build_FullMethodName = "/api.service.v1.BuildService/Build"
+	BuildService_Build_FullMethodName          = "/api.service.v1.BuildService/Build"
+	BuildService_BuildStream_FullMethodName    = "/api.service.v1.BuildService/BuildStream"
+	BuildService_Info_FullMethodName           = "/api.service.v1.BuildService/Info"
+	BuildService_ListBuilds_FullMethodName     = "/api.service.v1.BuildService/ListBuilds"
+	BuildService_GetBuild_FullMethodName       = "/api.service.v1.BuildService/GetBuild"
+	BuildService_DefaultPlugins_FullMethodName = "/api.service.v1.BuildService/DefaultPlugins"
 )
 
 // BuildServiceClient is the client API for BuildService service.
@@ -29,6 +34,21 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type BuildServiceClient interface {
 	Build(ctx context.Context, in *v1.BuildRequest, opts ...grpc.CallOption) (*v11.BuildResponse, error)
+	// BuildStream runs a Build and streams its log while it runs, ending with
+	// the result.
+	BuildStream(ctx context.Context, in *v1.BuildRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[v11.BuildEvent], error)
+	// Info describes the server: versions, target platforms, cache statistics
+	// and the builds in progress.
+	Info(ctx context.Context, in *v1.InfoRequest, opts ...grpc.CallOption) (*v11.InfoResponse, error)
+	// ListBuilds pages through the build history, newest first. Callers see
+	// their own builds unless their policy sets view_all_builds; anonymous
+	// callers, who share one principal, then see none.
+	ListBuilds(ctx context.Context, in *v1.ListBuildsRequest, opts ...grpc.CallOption) (*v11.ListBuildsResponse, error)
+	// GetBuild returns one history entry by id.
+	GetBuild(ctx context.Context, in *v1.GetBuildRequest, opts ...grpc.CallOption) (*v11.BuildRecord, error)
+	// DefaultPlugins lists the plugins upstream RoadRunner bundles, the
+	// starting point for a plugin set.
+	DefaultPlugins(ctx context.Context, in *v1.DefaultPluginsRequest, opts ...grpc.CallOption) (*v11.DefaultPluginsResponse, error)
 }
 
 type buildServiceClient struct {
@@ -49,11 +69,85 @@ func (c *buildServiceClient) Build(ctx context.Context, in *v1.BuildRequest, opt
 	return out, nil
 }
 
+func (c *buildServiceClient) BuildStream(ctx context.Context, in *v1.BuildRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[v11.BuildEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BuildService_ServiceDesc.Streams[0], BuildService_BuildStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[v1.BuildRequest, v11.BuildEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BuildService_BuildStreamClient = grpc.ServerStreamingClient[v11.BuildEvent]
+
+func (c *buildServiceClient) Info(ctx context.Context, in *v1.InfoRequest, opts ...grpc.CallOption) (*v11.InfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(v11.InfoResponse)
+	err := c.cc.Invoke(ctx, BuildService_Info_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *buildServiceClient) ListBuilds(ctx context.Context, in *v1.ListBuildsRequest, opts ...grpc.CallOption) (*v11.ListBuildsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(v11.ListBuildsResponse)
+	err := c.cc.Invoke(ctx, BuildService_ListBuilds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *buildServiceClient) GetBuild(ctx context.Context, in *v1.GetBuildRequest, opts ...grpc.CallOption) (*v11.BuildRecord, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(v11.BuildRecord)
+	err := c.cc.Invoke(ctx, BuildService_GetBuild_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *buildServiceClient) DefaultPlugins(ctx context.Context, in *v1.DefaultPluginsRequest, opts ...grpc.CallOption) (*v11.DefaultPluginsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(v11.DefaultPluginsResponse)
+	err := c.cc.Invoke(ctx, BuildService_DefaultPlugins_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BuildServiceServer is the server API for BuildService service.
 // All implementations should embed UnimplementedBuildServiceServer
 // for forward compatibility.
 type BuildServiceServer interface {
 	Build(context.Context, *v1.BuildRequest) (*v11.BuildResponse, error)
+	// BuildStream runs a Build and streams its log while it runs, ending with
+	// the result.
+	BuildStream(*v1.BuildRequest, grpc.ServerStreamingServer[v11.BuildEvent]) error
+	// Info describes the server: versions, target platforms, cache statistics
+	// and the builds in progress.
+	Info(context.Context, *v1.InfoRequest) (*v11.InfoResponse, error)
+	// ListBuilds pages through the build history, newest first. Callers see
+	// their own builds unless their policy sets view_all_builds; anonymous
+	// callers, who share one principal, then see none.
+	ListBuilds(context.Context, *v1.ListBuildsRequest) (*v11.ListBuildsResponse, error)
+	// GetBuild returns one history entry by id.
+	GetBuild(context.Context, *v1.GetBuildRequest) (*v11.BuildRecord, error)
+	// DefaultPlugins lists the plugins upstream RoadRunner bundles, the
+	// starting point for a plugin set.
+	DefaultPlugins(context.Context, *v1.DefaultPluginsRequest) (*v11.DefaultPluginsResponse, error)
 }
 
 // UnimplementedBuildServiceServer should be embedded to have
@@ -66,6 +160,21 @@ type UnimplementedBuildServiceServer struct{}
 func (UnimplementedBuildServiceServer) Build(context.Context, *v1.BuildRequest) (*v11.BuildResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Build not implemented")
 }
+func (UnimplementedBuildServiceServer) BuildStream(*v1.BuildRequest, grpc.ServerStreamingServer[v11.BuildEvent]) error {
+	return status.Error(codes.Unimplemented, "method BuildStream not implemented")
+}
+func (UnimplementedBuildServiceServer) Info(context.Context, *v1.InfoRequest) (*v11.InfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Info not implemented")
+}
+func (UnimplementedBuildServiceServer) ListBuilds(context.Context, *v1.ListBuildsRequest) (*v11.ListBuildsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListBuilds not implemented")
+}
+func (UnimplementedBuildServiceServer) GetBuild(context.Context, *v1.GetBuildRequest) (*v11.BuildRecord, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetBuild not implemented")
+}
+func (UnimplementedBuildServiceServer) DefaultPlugins(context.Context, *v1.DefaultPluginsRequest) (*v11.DefaultPluginsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DefaultPlugins not implemented")
+}
 func (UnimplementedBuildServiceServer) testEmbeddedByValue() {}
 
 // UnsafeBuildServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -104,6 +213,89 @@ func _BuildService_Build_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BuildService_BuildStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.BuildRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BuildServiceServer).BuildStream(m, &grpc.GenericServerStream[v1.BuildRequest, v11.BuildEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BuildService_BuildStreamServer = grpc.ServerStreamingServer[v11.BuildEvent]
+
+func _BuildService_Info_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.InfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BuildServiceServer).Info(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BuildService_Info_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BuildServiceServer).Info(ctx, req.(*v1.InfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BuildService_ListBuilds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.ListBuildsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BuildServiceServer).ListBuilds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BuildService_ListBuilds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BuildServiceServer).ListBuilds(ctx, req.(*v1.ListBuildsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BuildService_GetBuild_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.GetBuildRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BuildServiceServer).GetBuild(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BuildService_GetBuild_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BuildServiceServer).GetBuild(ctx, req.(*v1.GetBuildRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BuildService_DefaultPlugins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.DefaultPluginsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BuildServiceServer).DefaultPlugins(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BuildService_DefaultPlugins_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BuildServiceServer).DefaultPlugins(ctx, req.(*v1.DefaultPluginsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BuildService_ServiceDesc is the grpc.ServiceDesc for BuildService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +307,29 @@ var BuildService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Build",
 			Handler:    _BuildService_Build_Handler,
 		},
+		{
+			MethodName: "Info",
+			Handler:    _BuildService_Info_Handler,
+		},
+		{
+			MethodName: "ListBuilds",
+			Handler:    _BuildService_ListBuilds_Handler,
+		},
+		{
+			MethodName: "GetBuild",
+			Handler:    _BuildService_GetBuild_Handler,
+		},
+		{
+			MethodName: "DefaultPlugins",
+			Handler:    _BuildService_DefaultPlugins_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BuildStream",
+			Handler:       _BuildService_BuildStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/service/v1/service.proto",
 }
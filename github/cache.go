@@ -38,3 +38,6 @@ func (c *lruCache) Get(key string) ([]byte, bool) {
 func (c *lruCache) Add(key string, value []byte) {
 	c.inner.Add(key, bytes.Clone(value))
 }
+
+// Len returns the number of cached archives.
+func (c *lruCache) Len() int { return c.inner.Len() }
@@ -18,9 +18,15 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
+
+	"github.com/roadrunner-server/velox/v3/internal/telemetry"
 )
 
+var tracer = otel.Tracer("github.com/roadrunner-server/velox/v3/github")
+
 const (
 	rrOwner = "roadrunner-server"
 	rrRepo  = "roadrunner"
@@ -84,24 +90,91 @@ func NewClient(baseURL, accessToken string, cache Cache, log *slog.Logger) *Clie
 // SHA), unpacks it into downloadDir/hash/, and returns the path of the
 // extracted source tree. The archive bytes are cached so repeat builds of the
 // same ref skip the network call.
-func (c *Client) DownloadTemplate(ctx context.Context, downloadDir, hash, rrRef string) (string, error) {
+func (c *Client) DownloadTemplate(ctx context.Context, downloadDir, hash, rrRef string) (_ string, retErr error) {
+	ctx, span := tracer.Start(ctx, "DownloadTemplate", trace.WithAttributes(telemetry.AttrRRRef.String(rrRef)))
+	defer func() { telemetry.End(span, retErr) }()
+
+	zipBytes, err := c.archive(ctx, rrRef)
+	if err != nil {
+		return "", err
+	}
+
+	_, extract := tracer.Start(ctx, "extract")
+	path, err := c.saveRR(zipBytes, rrRef, filepath.Join(downloadDir, hash))
+	telemetry.End(extract, err)
+	return path, err
+}
+
+// Ping checks that the GitHub host answers. Any HTTP response counts, an
+// error status included: only the host being unreachable is reported.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// CommitTime returns the commit time of rrRef. GitHub stamps every entry of
+// a ref's archive with the committer date, so it is read from the archive
+// itself (usually already cached by DownloadTemplate) rather than from the
+// REST API, which GitHub Enterprise serves under a different base URL.
+func (c *Client) CommitTime(ctx context.Context, rrRef string) (time.Time, error) {
+	zipBytes, err := c.archive(ctx, rrRef)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ArchiveTime(zipBytes)
+}
+
+// ArchiveTime returns the modification time of the root entry of a GitHub
+// archive, i.e. the commit time of the archived ref.
+func ArchiveTime(zipBytes []byte) (time.Time, error) {
+	zr, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("open archive: %w", err)
+	}
+	if len(zr.File) == 0 {
+		return time.Time{}, errors.New("empty zip archive")
+	}
+	mod := zr.File[0].Modified
+	if mod.IsZero() {
+		return time.Time{}, fmt.Errorf("archive entry %q has no modification time", zr.File[0].Name)
+	}
+	return mod.UTC(), nil
+}
+
+// archive returns the archive bytes for rrRef from the cache, or downloads
+// and caches them.
+func (c *Client) archive(ctx context.Context, rrRef string) ([]byte, error) {
+	span := trace.SpanFromContext(ctx)
 	if cached, ok := c.cache.Get(rrRef); ok {
 		c.log.Info("RR archive cache hit", "ref", rrRef, "bytes", len(cached))
-		return c.saveRR(cached, rrRef, filepath.Join(downloadDir, hash))
+		span.SetAttributes(telemetry.AttrCacheHit.Bool(true), telemetry.AttrArchiveBytes.Int(len(cached)))
+		return cached, nil
 	}
+	span.SetAttributes(telemetry.AttrCacheHit.Bool(false))
 
 	archiveURL, err := c.archiveURL(rrRef)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	c.log.Info("downloading RR archive", "ref", rrRef, "url", archiveURL.String())
 
+	ctx, download := tracer.Start(ctx, "download")
 	zipBytes, err := c.fetch(ctx, archiveURL)
+	download.SetAttributes(telemetry.AttrArchiveBytes.Int(len(zipBytes)))
+	telemetry.End(download, err)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	c.cache.Add(rrRef, zipBytes)
-	return c.saveRR(zipBytes, rrRef, filepath.Join(downloadDir, hash))
+	return zipBytes, nil
 }
 
 // sha40 matches a 40-character hexadecimal commit SHA.
@@ -1,10 +1,16 @@
 package github
 
 import (
+	"archive/zip"
+	"bytes"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,3 +65,33 @@ func TestLRUCache_GetSet(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, []byte("payload-a"), got)
 }
+
+func TestArchiveTime(t *testing.T) {
+	commit := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, name := range []string{"roadrunner-2025.1.2/", "roadrunner-2025.1.2/go.mod"} {
+		_, err := zw.CreateHeader(&zip.FileHeader{Name: name, Modified: commit})
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	got, err := ArchiveTime(buf.Bytes())
+	require.NoError(t, err)
+	require.True(t, commit.Equal(got), "got %s", got)
+
+	_, err = ArchiveTime([]byte("not a zip"))
+	require.Error(t, err)
+}
+
+func TestPing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	c := NewClient(srv.URL, "", NewLRUCache(0), discardLogger())
+	require.NoError(t, c.Ping(t.Context()), "an error status still means the host is reachable")
+
+	srv.Close()
+	require.Error(t, c.Ping(t.Context()))
+}
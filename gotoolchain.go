@@ -0,0 +1,33 @@
+package velox
+
+import (
+	"fmt"
+	"go/version"
+	"strings"
+)
+
+// GoToolchainName returns the toolchain name ("go1.26.1") for a go_version
+// value, which may be written with or without the "go" prefix.
+func GoToolchainName(v string) string {
+	if v == "" {
+		return ""
+	}
+	return "go" + strings.TrimPrefix(v, "go")
+}
+
+// ValidateGoVersion checks a go_version value. It must name a Go release
+// ("1.26.1", "1.27rc1"), not a language version such as "1.26": since Go 1.21
+// there is no toolchain called go1.26 that GOTOOLCHAIN could select.
+func ValidateGoVersion(v string) error {
+	if v == "" {
+		return nil
+	}
+	name := GoToolchainName(v)
+	if !version.IsValid(name) {
+		return fmt.Errorf("invalid go version %q", v)
+	}
+	if version.Lang(name) == name && version.Compare(name, "go1.21") >= 0 {
+		return fmt.Errorf("go version %q is a language version; want a release such as %s.0", v, strings.TrimPrefix(name, "go"))
+	}
+	return nil
+}
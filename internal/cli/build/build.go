@@ -3,8 +3,15 @@
 package build
 
 import (
+	"cmp"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -20,13 +27,53 @@ const refKey = "ref"
 // is passed by pointer because the root command's PersistentPreRunE rewrites
 // its pointee with the config-driven logger after construction; child loggers
 // are therefore derived inside RunE, not at wiring time.
+//
+// --profile selects one of the config's [profiles]; see velox.Config.Select.
 func BindCommand(cfg *velox.Config, out *string, rootLog *slog.Logger) *cobra.Command {
-	return &cobra.Command{
+	var (
+		profile      string
+		pgo          string
+		reproducible bool
+		verify       bool
+		smokeServe   bool
+		reportPath   string
+	)
+
+	cmd := &cobra.Command{
 		Use:   "build",
 		Short: "Build a custom RoadRunner binary using velox.toml",
-		RunE: func(cmd *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) (retErr error) {
 			log := rootLog.With("component", "builder")
 
+			sel, err := cfg.Select(profile)
+			if err != nil {
+				return err
+			}
+			if profile != "" {
+				log.Info("using profile", "profile", profile, "plugins", len(sel.Plugins))
+			}
+
+			if cfg.CGO != nil && cfg.CGO.Enabled || sel.Race {
+				p := sel.TargetPlatform
+				tc := cfg.CGO.Toolchain(p.OS, p.Arch)
+				static := cfg.CGO != nil && cfg.CGO.Static
+				if err := velox.CheckCGOTarget(p.OS, p.Arch, runtime.GOOS, runtime.GOARCH, tc, static); err != nil {
+					return err
+				}
+			}
+
+			// Check a PGO profile before spending minutes on download and tidy.
+			pgo = cmp.Or(pgo, buildPGO(cfg))
+			if pgo != "" && pgo != velox.PGOAuto && pgo != velox.PGOOff {
+				data, err := os.ReadFile(pgo)
+				if err != nil {
+					return err
+				}
+				if err := builder.ValidatePGOProfile(data); err != nil {
+					return fmt.Errorf("%s: %w", pgo, err)
+				}
+			}
+
 			if *out == "." {
 				wd, err := os.Getwd()
 				if err != nil {
@@ -35,8 +82,8 @@ func BindCommand(cfg *velox.Config, out *string, rootLog *slog.Logger) *cobra.Co
 				*out = wd
 			}
 
-			plugins := make([]*plugin.Plugin, 0, len(cfg.Plugins))
-			for name, p := range cfg.Plugins {
+			plugins := make([]*plugin.Plugin, 0, len(sel.Plugins))
+			for name, p := range sel.Plugins {
 				if p == nil {
 					log.Warn("plugin info is nil", "name", name)
 					continue
@@ -55,42 +102,182 @@ func BindCommand(cfg *velox.Config, out *string, rootLog *slog.Logger) *cobra.Co
 
 			ctx := cmd.Context()
 			gh := github.NewClient(baseURL, token, github.NewLRUCache(0), log.With("component", "github"))
+			ref := cfg.Roadrunner[refKey]
+
+			reproducible = reproducible || verify
+			var sourceDate time.Time
+			if reproducible {
+				if sourceDate, err = gh.CommitTime(ctx, ref); err != nil {
+					return fmt.Errorf("reading RR commit time: %w", err)
+				}
+				log.Info("reproducible build", "ref", ref, "source_date_epoch", sourceDate.Unix())
+			}
+
+			var report *builder.Report
+			build := func(dlDir, outDir string) (string, error) {
+				start := time.Now()
+				rrPath, err := gh.DownloadTemplate(ctx, dlDir, "", ref)
+				download := builder.StageTiming{Name: "download", DurationMS: time.Since(start).Milliseconds()}
+				if err != nil {
+					log.Error("downloading template", "error", err)
+					report = &builder.Report{
+						RRRef:    ref,
+						Platform: sel.TargetPlatform.OS + "/" + sel.TargetPlatform.Arch,
+						Plugins:  make([]builder.ManifestPlugin, 0, len(plugins)),
+						Stages:   []builder.StageTiming{download},
+						Failure:  &builder.ReportFailure{Stage: download.Name, Error: err.Error()},
+					}
+					for _, p := range plugins {
+						report.Plugins = append(report.Plugins, builder.ManifestPlugin{Module: p.ModuleName(), Tag: p.Tag()})
+					}
+					return "", err
+				}
+				opts := []builder.Option{
+					builder.WithLogger(log.With("component", "build")),
+					builder.WithPlugins(plugins...),
+					builder.WithReplaces(cfg.Replaces),
+					builder.WithExcludes(cfg.Excludes),
+					builder.WithOutputDir(outDir),
+					builder.WithRRVersion(ref),
+					builder.WithGOOS(sel.TargetPlatform.OS),
+					builder.WithGOARCH(sel.TargetPlatform.Arch),
+					builder.WithDebug(sel.Debug),
+					builder.WithRace(sel.Race),
+					builder.WithGoVersion(cfg.GoVersion),
+					builder.WithGoToolchainDir(cfg.GoToolchainDir),
+					builder.WithCGOConfig(cfg.CGO),
+					builder.WithSandbox(cfg.Sandbox),
+					builder.WithBuildFlags(cfg.Build),
+					builder.WithTags(sel.Tags...),
+					builder.WithPGO(pgo),
+					builder.WithServeSmokeTest(smokeServe),
+					builder.WithExecWrapper(cfg.Smoke.ExecWrapper(sel.TargetPlatform.OS, sel.TargetPlatform.Arch)),
+				}
+				if reproducible {
+					opts = append(opts, builder.WithReproducible(sourceDate))
+				}
+				bld := builder.NewBuilder(rrPath, opts...)
+				path, err := bld.Build(ctx, ref)
+				report = bld.Report()
+				report.Stages = append([]builder.StageTiming{download}, report.Stages...)
+				report.DurationMS += download.DurationMS
+				return path, err
+			}
+			if reportPath != "" {
+				defer func() {
+					if err := writeReport(reportPath, report); err != nil && retErr == nil {
+						retErr = fmt.Errorf("writing report: %w", err)
+					}
+				}()
+			}
 
 			// Download into a unique per-build temp dir and remove it once the
 			// build finishes. The builder's own cleanup only sweeps the output
 			// dir, which differs from this download dir in CLI mode — without
 			// this defer the RR source tree + zip would leak into TempDir.
-			dlDir, err := os.MkdirTemp("", "velox-build-*")
+			// Reproducible builds use a fixed directory instead, so only one
+			// can run per host at a time.
+			dlDir, err := downloadDir(reproducible)
 			if err != nil {
 				return err
 			}
 			defer func() { _ = os.RemoveAll(dlDir) }()
 
-			rrPath, err := gh.DownloadTemplate(ctx, dlDir, "", cfg.Roadrunner[refKey])
-			if err != nil {
-				log.Error("downloading template", "error", err)
-				return err
-			}
-
-			debug := cfg.Debug != nil && cfg.Debug.Enabled
-			binaryPath, err := builder.NewBuilder(rrPath,
-				builder.WithLogger(log.With("component", "build")),
-				builder.WithPlugins(plugins...),
-				builder.WithReplaces(cfg.Replaces),
-				builder.WithExcludes(cfg.Excludes),
-				builder.WithOutputDir(*out),
-				builder.WithRRVersion(cfg.Roadrunner[refKey]),
-				builder.WithGOOS(cfg.TargetPlatform.OS),
-				builder.WithGOARCH(cfg.TargetPlatform.Arch),
-				builder.WithDebug(debug),
-			).Build(ctx, cfg.Roadrunner[refKey])
+			binaryPath, err := build(dlDir, *out)
 			if err != nil {
 				log.Error("build failed", "error", err)
 				return err
 			}
+			if verify {
+				primary := report
+				err := verifyReproducible(log, binaryPath, build)
+				report = primary
+				if err != nil {
+					report.Success = false
+					report.Failure = &builder.ReportFailure{Stage: "verifyReproducible", Error: err.Error()}
+					return err
+				}
+			}
 
 			log.Info("build finished", "path", binaryPath)
 			return nil
 		},
 	}
+	cmd.Flags().StringVarP(&profile, "profile", "p", "", "Build the named profile from the config's [profiles]")
+	cmd.Flags().StringVar(&pgo, "pgo", "",
+		"CPU profile to build with (copied to cmd/rr/default.pgo), or auto/off; overrides [build] pgo")
+	cmd.Flags().BoolVar(&reproducible, "reproducible", false,
+		"Build reproducibly: SOURCE_DATE_EPOCH from the RR commit time, a fixed build directory and -buildvcs=false")
+	cmd.Flags().BoolVar(&verify, "verify-reproducible", false,
+		"Build a second time in separate temp dirs and fail unless both binaries are identical (implies --reproducible)")
+	cmd.Flags().BoolVar(&smokeServe, "smoke-serve", false,
+		"After building, start `rr serve` with a generated config and wait for its plugins to become ready")
+	cmd.Flags().StringVar(&reportPath, "report", "",
+		"Write a JSON build report to this file (- for stdout), also when the build fails")
+	return cmd
+}
+
+// writeReport writes r as indented JSON to path, or to stdout for "-". A nil
+// report (the build never started) is not written.
+func writeReport(path string, r *builder.Report) error {
+	if r == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644) //nolint:gosec // reports are meant to be shared
+}
+
+// reproducibleDir is the fixed download directory of reproducible builds.
+const reproducibleDir = "velox-reproducible"
+
+func downloadDir(reproducible bool) (string, error) {
+	if !reproducible {
+		return os.MkdirTemp("", "velox-build-*")
+	}
+	dir := filepath.Join(os.TempDir(), reproducibleDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// verifyReproducible rebuilds into fresh temp dirs, so the source tree lives
+// at a different path, and compares the result with binaryPath.
+func verifyReproducible(log *slog.Logger, binaryPath string, build func(dlDir, outDir string) (string, error)) error {
+	tmp, err := os.MkdirTemp("", "velox-verify-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	log.Info("verifying reproducibility: building again", "dir", tmp)
+	second, err := build(filepath.Join(tmp, "src"), filepath.Join(tmp, "out"))
+	if err != nil {
+		return fmt.Errorf("verification build: %w", err)
+	}
+	c, err := builder.CompareBinaries(binaryPath, second)
+	if err != nil {
+		return err
+	}
+	if !c.Equal() {
+		return fmt.Errorf("build is not reproducible: sha256 %s != %s\n  %s",
+			c.SHA256A, c.SHA256B, strings.Join(c.Sections, "\n  "))
+	}
+	log.Info("build is reproducible", "sha256", c.SHA256A)
+	return nil
+}
+
+func buildPGO(cfg *velox.Config) string {
+	if cfg.Build == nil {
+		return ""
+	}
+	return cfg.Build.PGO
 }
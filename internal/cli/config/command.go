@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+// BindCommand returns the cobra.Command for `vx config`.
+func BindCommand(configPath *string, loadOptions func() ([]velox.LoadOption, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the velox configuration",
+	}
+	cmd.AddCommand(printCommand(configPath, loadOptions))
+	return cmd
+}
+
+// printCommand prints the config named by --config after every file it
+// extends has been merged in and the VELOX_* / --set / --plugin overrides
+// applied. Defaults are not applied and ${ENV} references are printed as
+// written; tokens given literally, by file or by override, are redacted so
+// they do not leak to stdout.
+func printCommand(configPath *string, loadOptions func() ([]velox.LoadOption, error)) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the configuration after merging everything it extends",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts, err := loadOptions()
+			if err != nil {
+				return err
+			}
+			cfg, err := velox.ReadConfig(*configPath, opts...)
+			if err != nil {
+				return err
+			}
+			redactTokens(cfg)
+
+			w := cmd.OutOrStdout()
+			switch format {
+			case "toml":
+				return cfg.EncodeTOML(w)
+			case "yaml", "yml":
+				return cfg.EncodeYAML(w)
+			case "json":
+				return cfg.EncodeJSON(w)
+			default:
+				return fmt.Errorf("unknown format %q (want toml, yaml or json)", format)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&format, "format", "f", "toml", "Output format: toml, yaml or json")
+	return cmd
+}
+
+// redacted replaces secret values in the printed config.
+const redacted = "<redacted>"
+
+// redactTokens blanks out github.token.token and server.auth.tokens.*.token
+// unless they are a bare ${ENV} reference, which reveals nothing.
+func redactTokens(cfg *velox.Config) {
+	redact := func(v *string) {
+		if *v != "" && !envRef.MatchString(*v) {
+			*v = redacted
+		}
+	}
+	if cfg.GitHub != nil && cfg.GitHub.Token != nil {
+		redact(&cfg.GitHub.Token.Token)
+	}
+	if cfg.Server != nil && cfg.Server.Auth != nil {
+		for _, t := range cfg.Server.Auth.Tokens {
+			if t != nil {
+				redact(&t.Token)
+			}
+		}
+	}
+}
+
+// envRef matches a value that is nothing but an unexpanded ${ENV} reference.
+var envRef = regexp.MustCompile(`^\$\{[A-Za-z_][A-Za-z0-9_]*\}$`)
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+func TestPrintRedactsTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "velox.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+[roadrunner]
+ref = "v2025.1.2"
+
+[github.token]
+token = "${GITHUB_TOKEN}"
+
+[server.auth.tokens.ci]
+token = "${CI_TOKEN}"
+policy = "ci"
+
+[server.policies.ci]
+
+[plugins.logger]
+tag = "v5.0.2"
+module_name = "github.com/roadrunner-server/logger/v5"
+`), 0o600))
+	opts := []velox.LoadOption{velox.WithSet("server.auth.tokens.ci.token", "set-secret")}
+	run := func() string {
+		t.Helper()
+		cmd := printCommand(&path, func() ([]velox.LoadOption, error) { return opts, nil })
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	out := run()
+	assert.NotContains(t, out, "set-secret")
+	assert.Contains(t, out, `token = '${GITHUB_TOKEN}'`, "a bare ${ENV} reference reveals nothing")
+	assert.Contains(t, out, `token = '`+redacted+`'`)
+	assert.Contains(t, out, `policy = 'ci'`)
+
+	t.Setenv("VELOX_GITHUB_TOKEN_TOKEN", "env-secret")
+	opts = append(opts, velox.WithEnvPrefix("VELOX"))
+	out = run()
+	assert.NotContains(t, out, "env-secret")
+	assert.NotContains(t, out, "${GITHUB_TOKEN}", "the VELOX_* override replaced the reference")
+}
@@ -0,0 +1,2 @@
+// Package config implements the `vx config` subcommands.
+package config
@@ -0,0 +1,166 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/roadrunner-server/velox/v3"
+	"github.com/roadrunner-server/velox/v3/builder"
+	"github.com/roadrunner-server/velox/v3/github"
+	"github.com/roadrunner-server/velox/v3/plugin"
+)
+
+const refKey = "ref"
+
+// BindCommand returns the cobra.Command for `vx diff`. Each argument is
+// either a config file or a git object "rev:path". When both arguments are
+// bare revisions, they are applied to the config named by --config
+// (configPath), e.g. `vx diff origin/main HEAD`.
+//
+// Both configs go through the tidy stage of the Builder against their own RR
+// ref, so the output reflects transitive changes, not just the TOML edit.
+func BindCommand(configPath *string, rootLog *slog.Logger) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Show how the resolved module graph changes between two velox configs",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := rootLog.With("component", "diff")
+			ctx := cmd.Context()
+
+			oldSpec, newSpec := args[0], args[1]
+			if isBareRev(oldSpec) && isBareRev(newSpec) {
+				oldSpec, newSpec = oldSpec+":"+*configPath, newSpec+":"+*configPath
+			}
+
+			workDir, err := os.MkdirTemp("", "velox-diff-*")
+			if err != nil {
+				return err
+			}
+			defer func() { _ = os.RemoveAll(workDir) }()
+
+			cache := github.NewLRUCache(0)
+			oldGraph, err := resolveSpec(ctx, log, cache, workDir, "old", oldSpec)
+			if err != nil {
+				return fmt.Errorf("%s: %w", oldSpec, err)
+			}
+			newGraph, err := resolveSpec(ctx, log, cache, workDir, "new", newSpec)
+			if err != nil {
+				return fmt.Errorf("%s: %w", newSpec, err)
+			}
+
+			changes := Graphs(oldGraph, newGraph)
+			if asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(changes)
+			}
+			return writeChanges(cmd.OutOrStdout(), changes)
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the changes as JSON")
+	return cmd
+}
+
+// isBareRev reports whether spec looks like a git revision on its own: not
+// an existing file and not a "rev:path" object.
+func isBareRev(spec string) bool {
+	if _, err := os.Stat(spec); err == nil {
+		return false
+	}
+	return !strings.Contains(spec, ":")
+}
+
+// loadSpec reads a config from a file or, for "rev:path", from git.
+func loadSpec(ctx context.Context, spec string) (*velox.Config, error) {
+	if _, err := os.Stat(spec); err == nil {
+		return velox.LoadConfig(spec)
+	}
+	rev, path, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, errors.New("no such file; use <rev>:<path> to read a config from git")
+	}
+	out, err := exec.CommandContext(ctx, "git", "show", rev+":"+path).Output()
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			return nil, fmt.Errorf("git show %s:%s: %s", rev, path, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, err
+	}
+	return velox.ParseConfig(out, path)
+}
+
+// resolveSpec loads spec, downloads its RR ref into workDir/name and returns
+// the module graph after tidy.
+func resolveSpec(ctx context.Context, log *slog.Logger, cache github.Cache, workDir, name, spec string) ([]builder.Module, error) {
+	cfg, err := loadSpec(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := make([]*plugin.Plugin, 0, len(cfg.Plugins))
+	for _, p := range cfg.Plugins {
+		plugins = append(plugins, plugin.NewPlugin(p.ModuleName, p.Tag))
+	}
+	token := ""
+	if cfg.GitHub.Token != nil {
+		token = cfg.GitHub.Token.Token
+	}
+
+	gh := github.NewClient(cfg.GitHub.BaseURL, token, cache, log.With("component", "github"))
+	rrPath, err := gh.DownloadTemplate(ctx, workDir, name, cfg.Roadrunner[refKey])
+	if err != nil {
+		return nil, fmt.Errorf("downloading template: %w", err)
+	}
+
+	return builder.NewBuilder(rrPath,
+		builder.WithLogger(log.With("component", "build", "config", name)),
+		builder.WithPlugins(plugins...),
+		builder.WithReplaces(cfg.Replaces),
+		builder.WithExcludes(cfg.Excludes),
+		builder.WithGOOS(cfg.TargetPlatform.OS),
+		builder.WithGOARCH(cfg.TargetPlatform.Arch),
+		builder.WithGoVersion(cfg.GoVersion),
+		builder.WithGoToolchainDir(cfg.GoToolchainDir),
+	).Resolve(ctx)
+}
+
+func writeChanges(w io.Writer, changes []ModuleChange) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "module graphs are identical")
+		return err
+	}
+	for _, c := range changes {
+		var line string
+		switch c.Kind {
+		case Added:
+			line = fmt.Sprintf("+ %s %s", c.Path, c.New)
+		case Removed:
+			line = fmt.Sprintf("- %s %s", c.Path, c.Old)
+		default:
+			line = fmt.Sprintf("~ %s %s -> %s", c.Path, c.Old, c.New)
+		}
+		if c.MajorBump {
+			line += "  [major version bump]"
+		}
+		if c.NewReplace != "" {
+			line += "  [new replace => " + c.NewReplace + "]"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+// Package diff implements the `vx diff` subcommand, which compares the
+// resolved module graphs of two velox configs.
+package diff
@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"cmp"
+	"slices"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"github.com/roadrunner-server/velox/v3/builder"
+)
+
+// Change kinds.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// ModuleChange is one difference between two resolved module graphs.
+type ModuleChange struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+	// MajorBump is set when the major version changed, either within one
+	// module path (v0 → v1) or across semantic-import-versioned paths
+	// (foo/v2 → foo/v3, reported on the added path).
+	MajorBump bool `json:"major_bump,omitempty"`
+	// NewReplace holds the replacement target when the module is replaced
+	// in the new graph but was not (or was replaced differently) in the old.
+	NewReplace string `json:"new_replace,omitempty"`
+}
+
+// Graphs compares two module graphs. The main module is ignored and the
+// result is sorted by module path.
+func Graphs(oldGraph, newGraph []builder.Module) []ModuleChange {
+	a, b := index(oldGraph), index(newGraph)
+
+	// prefixes records, per path without the /vN suffix, the major version
+	// present in the old graph, so a /v2 → /v3 switch reads as a bump rather
+	// than an unrelated add + remove.
+	oldMajors := map[string]string{}
+	for p, m := range a {
+		prefix, _, _ := module.SplitPathVersion(p)
+		oldMajors[prefix] = semver.Major(m.Version)
+	}
+
+	var out []ModuleChange
+	for p, om := range a {
+		nm, ok := b[p]
+		if !ok {
+			out = append(out, ModuleChange{Kind: Removed, Path: p, Old: om.Version})
+			continue
+		}
+		c := ModuleChange{Kind: Changed, Path: p, Old: om.Version, New: nm.Version}
+		if nr := replaceTarget(nm); nr != "" && nr != replaceTarget(om) {
+			c.NewReplace = nr
+		}
+		if om.Version == nm.Version && c.NewReplace == "" {
+			continue
+		}
+		c.MajorBump = om.Version != "" && nm.Version != "" &&
+			semver.Major(om.Version) != semver.Major(nm.Version)
+		out = append(out, c)
+	}
+	for p, nm := range b {
+		if _, ok := a[p]; ok {
+			continue
+		}
+		c := ModuleChange{Kind: Added, Path: p, New: nm.Version, NewReplace: replaceTarget(nm)}
+		prefix, _, _ := module.SplitPathVersion(p)
+		if major, ok := oldMajors[prefix]; ok && major != semver.Major(nm.Version) {
+			c.MajorBump = true
+		}
+		out = append(out, c)
+	}
+
+	slices.SortFunc(out, func(x, y ModuleChange) int { return cmp.Compare(x.Path, y.Path) })
+	return out
+}
+
+func index(graph []builder.Module) map[string]builder.Module {
+	m := make(map[string]builder.Module, len(graph))
+	for _, mod := range graph {
+		if mod.Main {
+			continue
+		}
+		m[mod.Path] = mod
+	}
+	return m
+}
+
+// replaceTarget renders a module's replacement as "path[@version]", or "".
+func replaceTarget(m builder.Module) string {
+	if m.Replace == nil {
+		return ""
+	}
+	if m.Replace.Version == "" {
+		return m.Replace.Path
+	}
+	return m.Replace.Path + "@" + m.Replace.Version
+}
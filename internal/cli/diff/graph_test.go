@@ -0,0 +1,49 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/roadrunner-server/velox/v3/builder"
+)
+
+func TestGraphs(t *testing.T) {
+	oldGraph := []builder.Module{
+		{Path: "github.com/roadrunner-server/roadrunner/v2025", Main: true},
+		{Path: "github.com/roadrunner-server/http/v5", Version: "v5.0.3"},
+		{Path: "github.com/roadrunner-server/logger/v5", Version: "v5.0.2"},
+		{Path: "github.com/foo/bar/v2", Version: "v2.4.0"},
+		{Path: "github.com/zero/one", Version: "v0.9.0"},
+		{Path: "github.com/gone/away", Version: "v1.0.0"},
+	}
+	newGraph := []builder.Module{
+		{Path: "github.com/roadrunner-server/roadrunner/v2025", Main: true},
+		{Path: "github.com/roadrunner-server/http/v5", Version: "v5.1.0"},
+		{
+			Path: "github.com/roadrunner-server/logger/v5", Version: "v5.0.2",
+			Replace: &builder.Module{Path: "../logger"},
+		},
+		{Path: "github.com/foo/bar/v3", Version: "v3.0.0"},
+		{Path: "github.com/zero/one", Version: "v1.0.0"},
+		{Path: "github.com/brand/new", Version: "v0.1.0"},
+	}
+
+	assert.Equal(t, []ModuleChange{
+		{Kind: Added, Path: "github.com/brand/new", New: "v0.1.0"},
+		{Kind: Removed, Path: "github.com/foo/bar/v2", Old: "v2.4.0"},
+		{Kind: Added, Path: "github.com/foo/bar/v3", New: "v3.0.0", MajorBump: true},
+		{Kind: Removed, Path: "github.com/gone/away", Old: "v1.0.0"},
+		{Kind: Changed, Path: "github.com/roadrunner-server/http/v5", Old: "v5.0.3", New: "v5.1.0"},
+		{
+			Kind: Changed, Path: "github.com/roadrunner-server/logger/v5", Old: "v5.0.2", New: "v5.0.2",
+			NewReplace: "../logger",
+		},
+		{Kind: Changed, Path: "github.com/zero/one", Old: "v0.9.0", New: "v1.0.0", MajorBump: true},
+	}, Graphs(oldGraph, newGraph))
+}
+
+func TestGraphs_Identical(t *testing.T) {
+	g := []builder.Module{{Path: "github.com/a/b", Version: "v1.0.0"}}
+	assert.Empty(t, Graphs(g, g))
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+// BindCommand returns the cobra.Command for `vx import`. The root
+// *slog.Logger is passed by pointer for the same reason as in the build
+// command: child loggers are derived inside RunE.
+//
+// The source is treated as a plugins.go file when it has a .go extension or
+// is a directory (an RR source tree); anything else is read as a binary.
+func BindCommand(rootLog *slog.Logger) *cobra.Command {
+	var writeTo string
+
+	cmd := &cobra.Command{
+		Use:   "import <rr-binary | container/plugins.go | rr-source-dir>",
+		Short: "Generate a velox.toml reproducing the plugin set of an existing RoadRunner build",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := rootLog.With("component", "import")
+			src := args[0]
+
+			var (
+				cfg *velox.Config
+				err error
+			)
+			if info, statErr := os.Stat(src); statErr == nil && (info.IsDir() || filepath.Ext(src) == ".go") {
+				cfg, err = FromPluginsGo(src)
+			} else {
+				cfg, err = FromBinary(src)
+			}
+			if err != nil {
+				return err
+			}
+			if cfg.Roadrunner[refKey] == "" {
+				log.Warn("could not determine the RoadRunner ref; set [roadrunner] ref in the generated config")
+			}
+
+			buf := &bytes.Buffer{}
+			_, _ = fmt.Fprintf(buf, "# Generated by `vx import %s`.\n\n", filepath.Base(src))
+			if err := cfg.EncodeTOML(buf); err != nil {
+				return err
+			}
+
+			if writeTo == "" {
+				_, err := io.Copy(cmd.OutOrStdout(), buf)
+				return err
+			}
+			if err := os.WriteFile(writeTo, buf.Bytes(), 0o600); err != nil {
+				return err
+			}
+			log.Info("velox config written", "path", writeTo, "plugins", len(cfg.Plugins),
+				"replaces", len(cfg.Replaces), "source", src)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&writeTo, "write", "w", "", "Write the generated config to this file instead of stdout")
+	return cmd
+}
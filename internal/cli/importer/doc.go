@@ -0,0 +1,3 @@
+// Package importer implements the `vx import` subcommand, which recreates a
+// velox.toml from an existing RoadRunner binary or container/plugins.go.
+package importer
@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/roadrunner-server/velox/v3"
+	"github.com/roadrunner-server/velox/v3/builder/templates"
+	"github.com/roadrunner-server/velox/v3/internal/rrbin"
+)
+
+const (
+	pluginsRelPath = "container/plugins.go"
+	goModFile      = "go.mod"
+	refKey         = "ref"
+	latestTag      = "latest"
+	develVersion   = "(devel)"
+)
+
+// majorSuffix matches the /vN element of a semantic-import-versioned path.
+var majorSuffix = regexp.MustCompile(`^v\d+$`)
+
+// FromBinary reconstructs a velox configuration from the build info and
+// function table of a RoadRunner binary. The RR ref comes from the injected
+// meta.version (unstripped binaries) or a versioned main module (e.g.
+// `go install ...@vX`); otherwise the returned config leaves [roadrunner] unset.
+func FromBinary(binPath string) (*velox.Config, error) {
+	bin, err := rrbin.Read(binPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &velox.Config{Plugins: map[string]*velox.Plugin{}}
+	switch v := bin.Main.Version; {
+	case bin.MetaVersion != "":
+		cfg.Roadrunner = map[string]string{refKey: bin.MetaVersion}
+	case v != "" && v != develVersion:
+		cfg.Roadrunner = map[string]string{refKey: v}
+	}
+	if goos, goarch := bin.Setting("GOOS"), bin.Setting("GOARCH"); goos != "" && goarch != "" {
+		cfg.TargetPlatform = &velox.TargetPlatform{OS: goos, Arch: goarch}
+	}
+	if strings.Contains(bin.Setting("-gcflags"), "-N -l") {
+		cfg.Debug = &velox.Debug{Enabled: true}
+	}
+
+	for _, p := range bin.Plugins() {
+		cfg.Plugins[pluginKey(p.Package, cfg.Plugins)] = &velox.Plugin{
+			ModuleName: p.Package,
+			Tag:        p.Module.Version,
+		}
+	}
+	for _, m := range bin.Replaces() {
+		cfg.Replaces = append(cfg.Replaces, velox.Replace{
+			Old: m.Path,
+			New: modVersion(m.Replace.Path, m.Replace.Version),
+		})
+	}
+	if len(cfg.Plugins) == 0 {
+		return nil, fmt.Errorf("%s: no RoadRunner plugins found in the binary", binPath)
+	}
+	return cfg, nil
+}
+
+// FromPluginsGo reconstructs a velox configuration from a container/plugins.go
+// file (or an RR source tree containing one). Plugin versions and replace
+// directives come from the nearest go.mod above the file; plugins the go.mod
+// does not pin are imported with tag "latest".
+func FromPluginsGo(src string) (*velox.Config, error) {
+	if info, err := os.Stat(src); err == nil && info.IsDir() {
+		src = filepath.Join(src, pluginsRelPath)
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), src, nil, parser.ImportsOnly)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", src, err)
+	}
+
+	mod, err := findGoMod(filepath.Dir(src))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &velox.Config{Plugins: map[string]*velox.Plugin{}}
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: bad import %s: %w", src, imp.Path.Value, err)
+		}
+		if templates.IsBundledModule(importPath) {
+			continue
+		}
+		cfg.Plugins[pluginKey(importPath, cfg.Plugins)] = &velox.Plugin{
+			ModuleName: importPath,
+			Tag:        requiredVersion(mod, importPath),
+		}
+	}
+	if len(cfg.Plugins) == 0 {
+		return nil, fmt.Errorf("%s: no plugin imports found", src)
+	}
+
+	if mod != nil {
+		for _, r := range mod.Replace {
+			cfg.Replaces = append(cfg.Replaces, velox.Replace{
+				Old: modVersion(r.Old.Path, r.Old.Version),
+				New: modVersion(r.New.Path, r.New.Version),
+			})
+		}
+		for _, e := range mod.Exclude {
+			cfg.Excludes = append(cfg.Excludes, velox.Exclude{Module: e.Mod.Path, Version: e.Mod.Version})
+		}
+	}
+	return cfg, nil
+}
+
+// findGoMod walks up from dir to the filesystem root looking for go.mod. A
+// missing go.mod is not an error: every plugin then gets tag "latest".
+func findGoMod(dir string) (*modfile.File, error) {
+	for {
+		p := filepath.Join(dir, goModFile)
+		data, err := os.ReadFile(p)
+		switch {
+		case err == nil:
+			mf, err := modfile.Parse(p, data, nil)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", p, err)
+			}
+			return mf, nil
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// requiredVersion returns the version go.mod requires for the module that
+// provides importPath (longest matching module path), or "latest".
+func requiredVersion(mod *modfile.File, importPath string) string {
+	if mod == nil {
+		return latestTag
+	}
+	best, version := "", latestTag
+	for _, r := range mod.Require {
+		p := r.Mod.Path
+		if importPath != p && !strings.HasPrefix(importPath, p+"/") {
+			continue
+		}
+		if len(p) > len(best) {
+			best, version = p, r.Mod.Version
+		}
+	}
+	return version
+}
+
+// pluginKey derives a [plugins.<key>] name from an import path: the last path
+// element, skipping a trailing /vN. Collisions get a numeric suffix.
+func pluginKey(importPath string, taken map[string]*velox.Plugin) string {
+	key := path.Base(importPath)
+	if majorSuffix.MatchString(key) {
+		key = path.Base(path.Dir(importPath))
+	}
+	candidate := key
+	for i := 2; ; i++ {
+		if _, dup := taken[candidate]; !dup {
+			return candidate
+		}
+		candidate = key + strconv.Itoa(i)
+	}
+}
+
+// modVersion renders "path@version", or just path when version is empty
+// (local-path replacements and unversioned replace sources).
+func modVersion(p, version string) string {
+	if version == "" {
+		return p
+	}
+	return p + "@" + version
+}
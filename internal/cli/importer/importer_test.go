@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+const samplePluginsGo = `package container
+
+import (
+	"github.com/roadrunner-server/informer/v5"
+	"github.com/roadrunner-server/resetter/v5"
+	httpPlugin "github.com/roadrunner-server/http/v5"
+	"github.com/roadrunner-server/logger/v5"
+	rrt "github.com/temporalio/roadrunner-temporal/v5"
+	"github.com/example/unpinned"
+)
+
+func Plugins() []any {
+	return []any{
+		&informer.Plugin{},
+		&resetter.Plugin{},
+		&httpPlugin.Plugin{},
+		&logger.Plugin{},
+		&rrt.Plugin{},
+		&unpinned.Plugin{},
+	}
+}
+`
+
+const sampleGoMod = `module github.com/roadrunner-server/roadrunner/v2025
+
+go 1.24
+
+require (
+	github.com/roadrunner-server/http/v5 v5.0.3
+	github.com/roadrunner-server/informer/v5 v5.1.0
+	github.com/roadrunner-server/logger/v5 v5.0.2
+	github.com/roadrunner-server/resetter/v5 v5.0.2
+	github.com/temporalio/roadrunner-temporal/v5 v5.2.0
+)
+
+replace github.com/roadrunner-server/logger/v5 => ../logger
+
+replace github.com/foo/bar v1.0.0 => github.com/me/bar v1.0.1
+
+exclude github.com/redis/go-redis/v9 v9.15.0
+`
+
+func writeTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "container"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "container", "plugins.go"), []byte(samplePluginsGo), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte(sampleGoMod), 0o600))
+	return root
+}
+
+func TestFromPluginsGo(t *testing.T) {
+	root := writeTree(t)
+
+	for _, src := range []string{root, filepath.Join(root, "container", "plugins.go")} {
+		t.Run(filepath.Base(src), func(t *testing.T) {
+			cfg, err := FromPluginsGo(src)
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]*velox.Plugin{
+				"http":                {ModuleName: "github.com/roadrunner-server/http/v5", Tag: "v5.0.3"},
+				"logger":              {ModuleName: "github.com/roadrunner-server/logger/v5", Tag: "v5.0.2"},
+				"roadrunner-temporal": {ModuleName: "github.com/temporalio/roadrunner-temporal/v5", Tag: "v5.2.0"},
+				"unpinned":            {ModuleName: "github.com/example/unpinned", Tag: "latest"},
+			}, cfg.Plugins)
+			assert.Equal(t, []velox.Replace{
+				{Old: "github.com/roadrunner-server/logger/v5", New: "../logger"},
+				{Old: "github.com/foo/bar@v1.0.0", New: "github.com/me/bar@v1.0.1"},
+			}, cfg.Replaces)
+			assert.Equal(t, []velox.Exclude{{Module: "github.com/redis/go-redis/v9", Version: "v9.15.0"}}, cfg.Excludes)
+		})
+	}
+}
+
+func TestFromPluginsGo_NoPlugins(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "plugins.go")
+	require.NoError(t, os.WriteFile(src, []byte("package container\n\nimport \"github.com/roadrunner-server/informer/v5\"\n"), 0o600))
+
+	_, err := FromPluginsGo(src)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no plugin imports")
+}
+
+func TestPluginKey(t *testing.T) {
+	taken := map[string]*velox.Plugin{}
+	assert.Equal(t, "http", pluginKey("github.com/roadrunner-server/http/v5", taken))
+	assert.Equal(t, "proxy_ip_parser", pluginKey("github.com/roadrunner-server/proxy_ip_parser/v5", taken))
+
+	taken["http"] = &velox.Plugin{}
+	assert.Equal(t, "http2", pluginKey("github.com/other/http", taken))
+}
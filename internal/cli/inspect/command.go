@@ -0,0 +1,143 @@
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// BindCommand returns the cobra.Command for `vx inspect`. It needs no
+// velox.toml: everything is read from the binary and its optional manifest.
+func BindCommand() *cobra.Command {
+	var (
+		asJSON       bool
+		manifestPath string
+		diffWith     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "inspect <rr-binary>",
+		Short: "Show the RoadRunner version, plugins, replaces and build flags of a binary",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := Inspect(args[0], manifestPath)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+
+			if diffWith == "" {
+				if asJSON {
+					return writeJSON(out, report)
+				}
+				return writeReport(out, report)
+			}
+
+			other, err := Inspect(diffWith, "")
+			if err != nil {
+				return err
+			}
+			changes := Diff(report, other)
+			if asJSON {
+				return writeJSON(out, changes)
+			}
+			return writeChanges(out, changes)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	flags.StringVar(&manifestPath, "manifest", "", "Path to the velox build manifest (default: <binary>.manifest.json)")
+	flags.StringVar(&diffWith, "diff", "", "Compare against another binary instead of printing a report")
+	return cmd
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeReport(w io.Writer, r *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	row := func(k, v string) { _, _ = fmt.Fprintf(tw, "%s\t%s\n", k, v) }
+
+	row("binary:", r.Binary)
+	row("go version:", r.GoVersion)
+	row("rr module:", r.RRModule)
+	if r.RRVersion != "" {
+		row("rr version:", fmt.Sprintf("%s (from %s)", r.RRVersion, r.RRVersionSource))
+	} else {
+		row("rr version:", "unknown")
+	}
+	if r.BuildTime != "" {
+		row("build time:", r.BuildTime)
+	}
+	row("platform:", r.Platform)
+	row("flags:", fmt.Sprintf("debug=%t race=%t trimpath=%t cgo=%t stripped=%t",
+		r.Flags.Debug, r.Flags.Race, r.Flags.Trimpath, r.Flags.CGO, r.Flags.Stripped))
+	if r.Flags.Tags != "" {
+		row("tags:", r.Flags.Tags)
+	}
+	if r.Flags.Gcflags != "" {
+		row("gcflags:", r.Flags.Gcflags)
+	}
+	if r.Flags.Buildmode != "" {
+		row("buildmode:", r.Flags.Buildmode)
+	}
+	switch {
+	case r.Flags.Ldflags != "":
+		row("ldflags:", r.Flags.Ldflags)
+	case r.Flags.Trimpath:
+		row("ldflags:", "not recorded (-trimpath)")
+	}
+
+	row(fmt.Sprintf("plugins (%d):", len(r.Plugins)), "")
+	for _, p := range r.Plugins {
+		row("  "+p.Path, p.Version)
+	}
+	if len(r.Replaces) > 0 {
+		row(fmt.Sprintf("replaces (%d):", len(r.Replaces)), "")
+		for _, rp := range r.Replaces {
+			row("  "+rp.Old, "=> "+rp.New)
+		}
+	}
+
+	switch {
+	case r.Manifest == nil:
+		row("manifest:", "none")
+	case len(r.Manifest.Mismatches) == 0:
+		row("manifest:", r.Manifest.Path+" (matches)")
+	default:
+		row("manifest:", fmt.Sprintf("%s (%d mismatches)", r.Manifest.Path, len(r.Manifest.Mismatches)))
+		for _, m := range r.Manifest.Mismatches {
+			row("  !", m)
+		}
+	}
+	return tw.Flush()
+}
+
+func writeChanges(w io.Writer, changes []Change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "no differences")
+		return err
+	}
+	for _, c := range changes {
+		var err error
+		switch c.Kind {
+		case Added:
+			_, err = fmt.Fprintf(w, "+ %s %s\n", c.Field, c.New)
+		case Removed:
+			_, err = fmt.Fprintf(w, "- %s %s\n", c.Field, c.Old)
+		default:
+			_, err = fmt.Fprintf(w, "~ %s: %s -> %s\n", c.Field, c.Old, c.New)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package inspect
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+)
+
+// Change kinds reported by Diff.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is a single difference between two inspected binaries.
+type Change struct {
+	Kind  string `json:"kind"`
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// Diff lists what changed from a to b: RR and Go versions, platform, build
+// flags, plugins (keyed by package path) and replaces (keyed by old module).
+// Build time is ignored; it differs between any two builds.
+func Diff(a, b *Report) []Change {
+	var out []Change
+	scalar := func(field, old, cur string) {
+		if old != cur {
+			out = append(out, Change{Kind: Changed, Field: field, Old: old, New: cur})
+		}
+	}
+	scalar("rr_version", a.RRVersion, b.RRVersion)
+	scalar("go_version", a.GoVersion, b.GoVersion)
+	scalar("platform", a.Platform, b.Platform)
+	scalar("flags.debug", strconv.FormatBool(a.Flags.Debug), strconv.FormatBool(b.Flags.Debug))
+	scalar("flags.race", strconv.FormatBool(a.Flags.Race), strconv.FormatBool(b.Flags.Race))
+	scalar("flags.trimpath", strconv.FormatBool(a.Flags.Trimpath), strconv.FormatBool(b.Flags.Trimpath))
+	scalar("flags.cgo", strconv.FormatBool(a.Flags.CGO), strconv.FormatBool(b.Flags.CGO))
+	scalar("flags.tags", a.Flags.Tags, b.Flags.Tags)
+	scalar("flags.gcflags", a.Flags.Gcflags, b.Flags.Gcflags)
+	scalar("flags.ldflags", a.Flags.Ldflags, b.Flags.Ldflags)
+	scalar("flags.buildmode", a.Flags.Buildmode, b.Flags.Buildmode)
+
+	out = append(out, diffKeyed("plugin ", pluginMap(a.Plugins), pluginMap(b.Plugins))...)
+	out = append(out, diffKeyed("replace ", replaceMap(a.Replaces), replaceMap(b.Replaces))...)
+	return out
+}
+
+func pluginMap(ps []Module) map[string]string {
+	m := make(map[string]string, len(ps))
+	for _, p := range ps {
+		m[p.Path] = p.Version
+	}
+	return m
+}
+
+func replaceMap(rs []Replace) map[string]string {
+	m := make(map[string]string, len(rs))
+	for _, r := range rs {
+		m[r.Old] = r.New
+	}
+	return m
+}
+
+// diffKeyed compares two key→value maps and returns the changes sorted by key.
+func diffKeyed(prefix string, a, b map[string]string) []Change {
+	var out []Change
+	for k, old := range a {
+		cur, ok := b[k]
+		switch {
+		case !ok:
+			out = append(out, Change{Kind: Removed, Field: prefix + k, Old: old})
+		case cur != old:
+			out = append(out, Change{Kind: Changed, Field: prefix + k, Old: old, New: cur})
+		}
+	}
+	for k, cur := range b {
+		if _, ok := a[k]; !ok {
+			out = append(out, Change{Kind: Added, Field: prefix + k, New: cur})
+		}
+	}
+	slices.SortFunc(out, func(x, y Change) int { return cmp.Compare(x.Field, y.Field) })
+	return out
+}
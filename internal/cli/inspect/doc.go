@@ -0,0 +1,3 @@
+// Package inspect implements the `vx inspect` subcommand, which reports the
+// RoadRunner version, plugins, replaces and build flags of a binary.
+package inspect
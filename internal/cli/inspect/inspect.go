@@ -0,0 +1,195 @@
+package inspect
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/roadrunner-server/velox/v3/builder"
+	"github.com/roadrunner-server/velox/v3/internal/rrbin"
+)
+
+const develVersion = "(devel)"
+
+// Sources of Report.RRVersion, from most to least authoritative.
+const (
+	sourceMeta     = "meta.version"
+	sourceManifest = "manifest"
+	sourceModule   = "main module"
+)
+
+// Report is what `vx inspect` knows about a binary.
+type Report struct {
+	Binary          string         `json:"binary"`
+	GoVersion       string         `json:"go_version"`
+	RRModule        string         `json:"rr_module"`
+	RRVersion       string         `json:"rr_version,omitempty"`
+	RRVersionSource string         `json:"rr_version_source,omitempty"`
+	BuildTime       string         `json:"build_time,omitempty"`
+	Platform        string         `json:"platform"`
+	Flags           Flags          `json:"flags"`
+	Plugins         []Module       `json:"plugins"`
+	Replaces        []Replace      `json:"replaces,omitempty"`
+	Manifest        *ManifestCheck `json:"manifest,omitempty"`
+}
+
+// Flags are the build flags recorded in the binary's build info.
+type Flags struct {
+	Debug     bool   `json:"debug"`
+	Race      bool   `json:"race"`
+	Trimpath  bool   `json:"trimpath"`
+	CGO       bool   `json:"cgo"`
+	Stripped  bool   `json:"stripped"`
+	Tags      string `json:"tags,omitempty"`
+	Gcflags   string `json:"gcflags,omitempty"`
+	Buildmode string `json:"buildmode,omitempty"`
+	// Ldflags is empty for -trimpath builds: the go toolchain omits it from
+	// the build info because it may contain host paths.
+	Ldflags string `json:"ldflags,omitempty"`
+}
+
+// Module is a plugin package and the version of the module providing it.
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// Replace is a replace directive that was in effect at build time.
+type Replace struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// ManifestCheck is the result of comparing a binary to its build manifest.
+type ManifestCheck struct {
+	Path       string   `json:"path"`
+	Mismatches []string `json:"mismatches,omitempty"`
+}
+
+// Inspect reads the binary at binPath. manifestPath overrides the default
+// manifest location (next to the binary); a missing default manifest is not
+// an error, a missing explicit one is.
+func Inspect(binPath, manifestPath string) (*Report, error) {
+	bin, err := rrbin.Read(binPath)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{
+		Binary:    binPath,
+		GoVersion: bin.GoVersion,
+		RRModule:  bin.Main.Path,
+		BuildTime: bin.MetaBuildTime,
+		Platform:  bin.Setting("GOOS") + "/" + bin.Setting("GOARCH"),
+		Flags: Flags{
+			Debug:     strings.Contains(bin.Setting("-gcflags"), "-N -l"),
+			Race:      bin.Setting("-race") == "true",
+			Trimpath:  bin.Setting("-trimpath") == "true",
+			CGO:       bin.Setting("CGO_ENABLED") == "1",
+			Stripped:  bin.Stripped,
+			Tags:      bin.Setting("-tags"),
+			Gcflags:   bin.Setting("-gcflags"),
+			Buildmode: bin.Setting("-buildmode"),
+			Ldflags:   bin.Setting("-ldflags"),
+		},
+	}
+	if bin.MetaVersion != "" {
+		r.RRVersion, r.RRVersionSource = bin.MetaVersion, sourceMeta
+	}
+	for _, p := range bin.Plugins() {
+		r.Plugins = append(r.Plugins, Module{Path: p.Package, Version: p.Module.Version})
+	}
+	for _, m := range bin.Replaces() {
+		repl := m.Replace.Path
+		if m.Replace.Version != "" {
+			repl += "@" + m.Replace.Version
+		}
+		r.Replaces = append(r.Replaces, Replace{Old: m.Path, New: repl})
+	}
+
+	explicit := manifestPath != ""
+	if !explicit {
+		manifestPath = builder.ManifestPath(binPath)
+	}
+	m, err := builder.ReadManifest(manifestPath)
+	switch {
+	case err == nil:
+		r.Manifest = &ManifestCheck{Path: manifestPath, Mismatches: r.compare(m)}
+		if r.RRVersion == "" {
+			r.RRVersion, r.RRVersionSource = m.RRVersion, sourceManifest
+		}
+		if r.BuildTime == "" {
+			r.BuildTime = m.BuildTime
+		}
+	case explicit || !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	if r.RRVersion == "" && bin.Main.Version != "" && bin.Main.Version != develVersion {
+		r.RRVersion, r.RRVersionSource = bin.Main.Version, sourceModule
+	}
+	return r, nil
+}
+
+// compare lists every difference between the binary and its manifest.
+func (r *Report) compare(m *builder.Manifest) []string {
+	var out []string
+	if r.RRVersion != "" && m.RRVersion != r.RRVersion {
+		out = append(out, fmt.Sprintf("rr version: manifest %s, binary %s", m.RRVersion, r.RRVersion))
+	}
+	if m.GoVersion != "" && m.GoVersion != r.GoVersion {
+		out = append(out, fmt.Sprintf("go version: manifest %s, binary %s", m.GoVersion, r.GoVersion))
+	}
+	if p := m.GOOS + "/" + m.GOARCH; m.GOOS != "" && p != r.Platform {
+		out = append(out, fmt.Sprintf("platform: manifest %s, binary %s", p, r.Platform))
+	}
+	if m.Debug != r.Flags.Debug {
+		out = append(out, fmt.Sprintf("debug: manifest %t, binary %t", m.Debug, r.Flags.Debug))
+	}
+	if m.Race != r.Flags.Race {
+		out = append(out, fmt.Sprintf("race: manifest %t, binary %t", m.Race, r.Flags.Race))
+	}
+	if tags := strings.Join(m.Tags, ","); tags != r.Flags.Tags {
+		out = append(out, fmt.Sprintf("tags: manifest %q, binary %q", tags, r.Flags.Tags))
+	}
+	// Race implies cgo; older manifests only recorded the former. A race
+	// mismatch already explains a cgo one, so it is not reported twice.
+	if want := m.CGO || m.Race; want != r.Flags.CGO && m.Race == r.Flags.Race {
+		out = append(out, fmt.Sprintf("cgo: manifest %t, binary %t", want, r.Flags.CGO))
+	}
+	if m.Trimpath != nil && *m.Trimpath != r.Flags.Trimpath {
+		out = append(out, fmt.Sprintf("trimpath: manifest %t, binary %t", *m.Trimpath, r.Flags.Trimpath))
+	}
+	// Manifests predating configurable gcflags left the field empty even for
+	// debug builds.
+	if (m.Gcflags != "" || !m.Debug) && m.Gcflags != r.Flags.Gcflags {
+		out = append(out, fmt.Sprintf("gcflags: manifest %q, binary %q", m.Gcflags, r.Flags.Gcflags))
+	}
+	if bm := cmp.Or(m.Buildmode, "exe"); r.Flags.Buildmode != "" && bm != "default" && bm != r.Flags.Buildmode {
+		out = append(out, fmt.Sprintf("buildmode: manifest %s, binary %s", bm, r.Flags.Buildmode))
+	}
+
+	inBinary := make(map[string]string, len(r.Plugins))
+	for _, p := range r.Plugins {
+		inBinary[p.Path] = p.Version
+	}
+	for _, p := range m.Plugins {
+		got, ok := inBinary[p.Module]
+		want := cmp.Or(p.Resolved, p.Tag)
+		switch {
+		case !ok:
+			out = append(out, fmt.Sprintf("plugin %s: in manifest but not compiled in", p.Module))
+		case want != "latest" && got != want:
+			out = append(out, fmt.Sprintf("plugin %s: manifest %s, binary %s", p.Module, want, got))
+		}
+		delete(inBinary, p.Module)
+	}
+	for _, path := range slices.Sorted(maps.Keys(inBinary)) {
+		out = append(out, fmt.Sprintf("plugin %s: compiled in but not in manifest", path))
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package inspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roadrunner-server/velox/v3/builder"
+)
+
+func sampleReport() *Report {
+	return &Report{
+		RRVersion: "v2025.1.2",
+		GoVersion: "go1.26.1",
+		Platform:  "linux/amd64",
+		Flags:     Flags{Trimpath: true},
+		Plugins: []Module{
+			{Path: "github.com/roadrunner-server/http/v5", Version: "v5.0.3"},
+			{Path: "github.com/roadrunner-server/logger/v5", Version: "v5.0.2"},
+		},
+		Replaces: []Replace{{Old: "github.com/foo/bar", New: "github.com/me/bar@v1.0.1"}},
+	}
+}
+
+func TestCompareManifest(t *testing.T) {
+	r := sampleReport()
+	m := &builder.Manifest{
+		RRVersion: "v2025.1.2",
+		GOOS:      "linux",
+		GOARCH:    "amd64",
+		Plugins: []builder.ManifestPlugin{
+			{Module: "github.com/roadrunner-server/http/v5", Tag: "latest", Resolved: "v5.0.3"},
+			{Module: "github.com/roadrunner-server/logger/v5", Tag: "latest"},
+		},
+	}
+	assert.Empty(t, r.compare(m))
+
+	m.Race = true
+	m.Plugins[0].Resolved = "v5.0.4"
+	m.Plugins = append(m.Plugins, builder.ManifestPlugin{Module: "github.com/roadrunner-server/rpc/v5", Tag: "v5.0.1"})
+	r.Plugins = append(r.Plugins, Module{Path: "github.com/roadrunner-server/kv/v5", Version: "v5.0.0"})
+
+	assert.Equal(t, []string{
+		"race: manifest true, binary false",
+		"plugin github.com/roadrunner-server/http/v5: manifest v5.0.4, binary v5.0.3",
+		"plugin github.com/roadrunner-server/rpc/v5: in manifest but not compiled in",
+		"plugin github.com/roadrunner-server/kv/v5: compiled in but not in manifest",
+	}, r.compare(m))
+}
+
+func TestDiff(t *testing.T) {
+	a, b := sampleReport(), sampleReport()
+	require.Empty(t, Diff(a, b))
+
+	b.RRVersion = "v2025.1.3"
+	b.Flags.Race = true
+	b.Plugins[0].Version = "v5.1.0"
+	b.Plugins = b.Plugins[:1]
+	b.Plugins = append(b.Plugins, Module{Path: "github.com/roadrunner-server/rpc/v5", Version: "v5.0.1"})
+	b.Replaces = nil
+
+	assert.Equal(t, []Change{
+		{Kind: Changed, Field: "rr_version", Old: "v2025.1.2", New: "v2025.1.3"},
+		{Kind: Changed, Field: "flags.race", Old: "false", New: "true"},
+		{Kind: Changed, Field: "plugin github.com/roadrunner-server/http/v5", Old: "v5.0.3", New: "v5.1.0"},
+		{Kind: Removed, Field: "plugin github.com/roadrunner-server/logger/v5", Old: "v5.0.2"},
+		{Kind: Added, Field: "plugin github.com/roadrunner-server/rpc/v5", New: "v5.0.1"},
+		{Kind: Removed, Field: "replace github.com/foo/bar", Old: "github.com/me/bar@v1.0.1"},
+	}, Diff(a, b))
+}
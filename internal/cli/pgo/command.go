@@ -0,0 +1,50 @@
+package pgo
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// BindCommand returns the cobra.Command for `vx pgo`.
+func BindCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pgo",
+		Short: "Prepare CPU profiles for profile-guided optimisation builds",
+	}
+	cmd.AddCommand(mergeCommand())
+	return cmd
+}
+
+func mergeCommand() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "merge <profile.pprof>...",
+		Short: "Merge several CPU profiles into one for `vx build --pgo`",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			merged, err := MergeFiles(args...)
+			if err != nil {
+				return err
+			}
+			f, err := os.Create(out)
+			if err != nil {
+				return err
+			}
+			if err := merged.Write(f); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "merged %d profiles (%d samples) into %s\n",
+				len(args), len(merged.Sample), out)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&out, "output", "o", "default.pgo", "Path of the merged profile")
+	return cmd
+}
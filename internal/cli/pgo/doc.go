@@ -0,0 +1,3 @@
+// Package pgo implements the `vx pgo` subcommands for preparing
+// profile-guided optimisation inputs.
+package pgo
@@ -0,0 +1,47 @@
+package pgo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/pprof/profile"
+)
+
+// Merge combines pprof profiles into one. Samples at the same call stacks
+// are summed, which is what the compiler expects when a service's load is
+// collected from several instances or time windows. All inputs must have the
+// same sample types (e.g. all CPU profiles).
+func Merge(inputs ...io.Reader) (*profile.Profile, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("no profiles to merge")
+	}
+	profiles := make([]*profile.Profile, 0, len(inputs))
+	for i, r := range inputs {
+		p, err := profile.Parse(r)
+		if err != nil {
+			return nil, fmt.Errorf("profile %d: %w", i, err)
+		}
+		profiles = append(profiles, p)
+	}
+	merged, err := profile.Merge(profiles)
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// MergeFiles is Merge for profiles on disk.
+func MergeFiles(paths ...string) (*profile.Profile, error) {
+	readers := make([]io.Reader, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		readers = append(readers, f)
+	}
+	return Merge(readers...)
+}
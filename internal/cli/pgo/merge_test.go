@@ -0,0 +1,79 @@
+package pgo
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roadrunner-server/velox/v3/builder"
+)
+
+// cpuProfile builds a minimal CPU profile with one sample of the given value
+// per function name.
+func cpuProfile(t *testing.T, samples map[string]int64) []byte {
+	t.Helper()
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		PeriodType:    &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:        int64(10 * time.Millisecond),
+		TimeNanos:     time.Now().UnixNano(),
+		DurationNanos: int64(time.Second),
+	}
+	var id uint64
+	for name, v := range samples {
+		id++
+		fn := &profile.Function{ID: id, Name: name, SystemName: name, Filename: "main.go"}
+		loc := &profile.Location{ID: id, Address: 0x1000 * id, Line: []profile.Line{{Function: fn, Line: int64(id)}}}
+		p.Function = append(p.Function, fn)
+		p.Location = append(p.Location, loc)
+		p.Sample = append(p.Sample, &profile.Sample{Location: []*profile.Location{loc}, Value: []int64{1, v}})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, p.Write(&buf))
+	return buf.Bytes()
+}
+
+func total(p *profile.Profile, name string) int64 {
+	var sum int64
+	for _, s := range p.Sample {
+		if s.Location[0].Line[0].Function.Name == name {
+			sum += s.Value[1]
+		}
+	}
+	return sum
+}
+
+func TestMerge(t *testing.T) {
+	a := cpuProfile(t, map[string]int64{"main.handle": 100, "main.parse": 50})
+	b := cpuProfile(t, map[string]int64{"main.handle": 200})
+
+	merged, err := Merge(bytes.NewReader(a), bytes.NewReader(b))
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), total(merged, "main.handle"))
+	assert.Equal(t, int64(50), total(merged, "main.parse"))
+
+	var out bytes.Buffer
+	require.NoError(t, merged.Write(&out))
+	require.NoError(t, builder.ValidatePGOProfile(out.Bytes()), "merged output must be usable for -pgo")
+}
+
+func TestMergeErrors(t *testing.T) {
+	_, err := Merge()
+	require.Error(t, err)
+
+	_, err = Merge(bytes.NewReader([]byte("not a profile")))
+	require.ErrorContains(t, err, "profile 0")
+
+	heap := &profile.Profile{SampleType: []*profile.ValueType{{Type: "alloc_space", Unit: "bytes"}}}
+	var buf bytes.Buffer
+	require.NoError(t, heap.Write(&buf))
+	_, err = Merge(bytes.NewReader(cpuProfile(t, map[string]int64{"f": 1})), &buf)
+	require.Error(t, err, "profiles with different sample types cannot be merged")
+}
@@ -1,4 +1,4 @@
-// Package cli wires the root cobra command and the build / server subcommands.
+// Package cli wires the root cobra command and its subcommands.
 package cli
 
 import (
@@ -7,13 +7,20 @@ import (
 	"log/slog"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"github.com/roadrunner-server/velox/v3"
 	"github.com/roadrunner-server/velox/v3/internal/cli/build"
+	vxconfig "github.com/roadrunner-server/velox/v3/internal/cli/config"
+	"github.com/roadrunner-server/velox/v3/internal/cli/diff"
+	"github.com/roadrunner-server/velox/v3/internal/cli/importer"
+	"github.com/roadrunner-server/velox/v3/internal/cli/inspect"
+	"github.com/roadrunner-server/velox/v3/internal/cli/pgo"
+	"github.com/roadrunner-server/velox/v3/internal/cli/schema"
 	"github.com/roadrunner-server/velox/v3/internal/cli/server"
+	"github.com/roadrunner-server/velox/v3/internal/cli/validate"
 	"github.com/roadrunner-server/velox/v3/internal/version"
 	"github.com/roadrunner-server/velox/v3/logger"
 )
@@ -29,8 +36,17 @@ import (
 func NewCommand(executableName string) *cobra.Command {
 	lg := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
 
+	// configless lists the subcommands (or command groups) that do not need
+	// velox.toml loaded up front.
+	configless := map[string]struct{}{
+		"server": {}, "import": {}, "inspect": {}, "diff": {}, "validate": {}, "schema": {}, "config": {}, "pgo": {},
+	}
+
 	var (
 		pathToConfig string
+		strict       bool
+		sets         []string
+		plugins      []string
 		outputFile   string
 		address      string
 		config       = &velox.Config{}
@@ -43,26 +59,24 @@ func NewCommand(executableName string) *cobra.Command {
 		SilenceUsage:  true,
 		Version:       fmt.Sprintf("%s (build time: %s, %s)", version.Version(), version.BuildTime(), runtime.Version()),
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
-			if cmd.Use == "server" {
-				return nil
+			for c := cmd; c != nil; c = c.Parent() {
+				if _, ok := configless[c.Name()]; ok {
+					return nil
+				}
 			}
 			if pathToConfig == "" {
 				return errors.New("path to the config should be provided")
 			}
 
-			v := viper.New()
-			v.SetConfigFile(pathToConfig)
-			if err := v.ReadInConfig(); err != nil {
-				return err
-			}
-			var cfg velox.Config
-			if err := v.Unmarshal(&cfg); err != nil {
+			opts, err := loadOptions(strict, sets, plugins)
+			if err != nil {
 				return err
 			}
-			if err := cfg.Validate(); err != nil {
+			cfg, err := velox.LoadConfig(pathToConfig, opts...)
+			if err != nil {
 				return err
 			}
-			*config = cfg
+			*config = *cfg
 
 			zlog, err := logger.BuildLogger(config.Log[velox.LogLevelKey], config.Log[velox.LogModeKey])
 			if err != nil {
@@ -75,12 +89,54 @@ func NewCommand(executableName string) *cobra.Command {
 
 	flag := cmd.PersistentFlags()
 	flag.StringVarP(&pathToConfig, "config", "c", "velox.toml", "Path to the velox configuration file")
+	flag.BoolVar(&strict, "strict", false, "Reject unknown keys in the velox configuration instead of ignoring them")
+	flag.StringArrayVar(&sets, "set", nil,
+		"Override a config key, e.g. --set plugins.http.tag=v5.2.0 (repeatable; wins over VELOX_* environment variables)")
+	flag.StringArrayVar(&plugins, "plugin", nil,
+		"Add or replace a plugin as name=module@tag (repeatable; wins over --set)")
 	flag.StringVarP(&outputFile, "out", "o", ".", "Output directory for the produced RoadRunner binary")
 	flag.StringVarP(&address, "address", "a", "127.0.0.1:8080", "Bind address for the build server")
 
 	cmd.AddCommand(
 		build.BindCommand(config, &outputFile, lg),
 		server.BindCommand(&address, lg),
+		importer.BindCommand(lg),
+		inspect.BindCommand(),
+		diff.BindCommand(&pathToConfig, lg),
+		validate.BindCommand(&pathToConfig, lg),
+		schema.BindCommand(),
+		pgo.BindCommand(),
+		vxconfig.BindCommand(&pathToConfig, func() ([]velox.LoadOption, error) {
+			return loadOptions(strict, sets, plugins)
+		}),
 	)
 	return cmd
 }
+
+// envPrefix is the prefix of environment variables overriding config keys,
+// e.g. VELOX_ROADRUNNER_REF or VELOX_PLUGINS_HTTP_TAG.
+const envPrefix = "VELOX"
+
+// loadOptions turns the global flags into config load options. Precedence,
+// lowest first: config file, VELOX_* environment, --set, --plugin.
+func loadOptions(strict bool, sets, plugins []string) ([]velox.LoadOption, error) {
+	opts := []velox.LoadOption{velox.WithStrict(strict), velox.WithEnvPrefix(envPrefix)}
+	for _, s := range sets {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("--set %q: want key=value", s)
+		}
+		opts = append(opts, velox.WithSet(key, value))
+	}
+	for _, spec := range plugins {
+		name, p, err := velox.ParsePluginSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("--plugin: %w", err)
+		}
+		opts = append(opts,
+			velox.WithSet("plugins."+name+".module_name", p.ModuleName),
+			velox.WithSet("plugins."+name+".tag", p.Tag),
+		)
+	}
+	return opts, nil
+}
@@ -0,0 +1,36 @@
+package schema
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+// BindCommand returns the cobra.Command for `vx schema`.
+func BindCommand() *cobra.Command {
+	var writeTo string
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema for velox.toml (for editor completion)",
+		Long: "Print the JSON Schema for velox.toml. Reference it from the first line of the\n" +
+			"config with `#:schema ./velox.schema.json` for completion in TOML-aware editors.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			data, err := velox.JSONSchema()
+			if err != nil {
+				return err
+			}
+			data = append(data, '\n')
+			if writeTo != "" {
+				return os.WriteFile(writeTo, data, 0o600)
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&writeTo, "write", "w", "", "Write the schema to this file instead of stdout")
+	return cmd
+}
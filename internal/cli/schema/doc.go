@@ -0,0 +1,3 @@
+// Package schema implements the `vx schema` subcommand, which prints the JSON
+// Schema for velox.toml.
+package schema
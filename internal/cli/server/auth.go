@@ -0,0 +1,297 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"github.com/roadrunner-server/velox/v3"
+	requestV1 "github.com/roadrunner-server/velox/v3/gen/go/api/request/v1"
+)
+
+// jwtLeeway is the clock skew tolerated when checking exp/nbf/iat.
+const jwtLeeway = time.Minute
+
+// jwtAlgorithms are the signature algorithms accepted for bearer JWTs.
+var jwtAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Name identifies the caller: the token or client cert name from the
+	// config, the JWT principal claim, or "anonymous".
+	Name string
+	// Method is how the caller authenticated: token, jwt, mtls or anonymous.
+	Method string
+	// PolicyName and Policy are what the caller may build.
+	PolicyName string
+	Policy     *velox.Policy
+}
+
+// ID identifies the principal across authentication methods, e.g.
+// "jwt:alice". Names are only unique within a method: a JWT subject may
+// equal a token or certificate name without being the same caller.
+func (p *Principal) ID() string {
+	return p.Method + ":" + p.Name
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext returns the principal the auth interceptor attached
+// to ctx, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+type peerCertKey struct{}
+
+// withPeerCerts makes the verified TLS client certificate of each request
+// available to the auth interceptor, which only sees Connect requests.
+func withPeerCerts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), peerCertKey{}, r.TLS.VerifiedChains[0][0]))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticator identifies callers according to the [server.auth] config.
+type authenticator struct {
+	auth     *velox.Auth
+	policies map[string]*velox.Policy
+	jwks     *jose.JSONWebKeySet
+}
+
+// newAuthenticator builds the authenticator of a validated [server] section,
+// loading the JWKS file when JWTs are enabled.
+func newAuthenticator(cfg *velox.Server) (*authenticator, error) {
+	a := &authenticator{auth: cfg.Auth, policies: cfg.Policies}
+	if j := cfg.Auth.JWT; j != nil {
+		data, err := os.ReadFile(j.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("server.auth.jwt: %w", err)
+		}
+		a.jwks = &jose.JSONWebKeySet{}
+		if err := json.Unmarshal(data, a.jwks); err != nil {
+			return nil, fmt.Errorf("server.auth.jwt: parse %s: %w", j.JWKSFile, err)
+		}
+		if len(a.jwks.Keys) == 0 {
+			return nil, fmt.Errorf("server.auth.jwt: %s holds no keys", j.JWKSFile)
+		}
+	}
+	return a, nil
+}
+
+// Interceptor rejects requests it cannot authenticate with
+// CodeUnauthenticated and attaches the Principal of the others. It covers
+// unary and streaming handlers alike.
+func (a *authenticator) Interceptor() connect.Interceptor {
+	return authInterceptor{a}
+}
+
+type authInterceptor struct{ a *authenticator }
+
+func (i authInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		ctx, err := i.a.withPrincipal(ctx, req.Header())
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (authInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i authInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := i.a.withPrincipal(ctx, conn.RequestHeader())
+		if err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+// withPrincipal authenticates a request and attaches its Principal to ctx.
+func (a *authenticator) withPrincipal(ctx context.Context, h http.Header) (context.Context, error) {
+	p, err := a.authenticate(ctx, h)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, err)
+	}
+	return context.WithValue(ctx, principalKey{}, p), nil
+}
+
+func (a *authenticator) authenticate(ctx context.Context, h http.Header) (*Principal, error) {
+	if authz := h.Get("Authorization"); authz != "" {
+		scheme, token, ok := strings.Cut(authz, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return nil, errors.New("authorization header must be a bearer token")
+		}
+		return a.bearer(token)
+	}
+	if cert, ok := ctx.Value(peerCertKey{}).(*x509.Certificate); ok {
+		for name, c := range a.auth.ClientCerts {
+			if c.CommonName == cert.Subject.CommonName {
+				return a.principal(name, "mtls", c.Policy), nil
+			}
+		}
+		return nil, fmt.Errorf("client certificate %q is not mapped to a principal", cert.Subject.CommonName)
+	}
+	if a.auth.AnonymousPolicy != "" {
+		return a.principal("anonymous", "anonymous", a.auth.AnonymousPolicy), nil
+	}
+	return nil, errors.New("credentials required")
+}
+
+// bearer matches a static token in constant time, then tries the token as
+// a JWT.
+func (a *authenticator) bearer(token string) (*Principal, error) {
+	for name, t := range a.auth.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			return a.principal(name, "token", t.Policy), nil
+		}
+	}
+	if a.jwks == nil || strings.Count(token, ".") != 2 {
+		return nil, errors.New("invalid token")
+	}
+	return a.verifyJWT(token)
+}
+
+func (a *authenticator) verifyJWT(raw string) (*Principal, error) {
+	j := a.auth.JWT
+	tok, err := jwt.ParseSigned(raw, jwtAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt: %w", err)
+	}
+	keys := a.jwks.Keys
+	if kid := tok.Headers[0].KeyID; kid != "" {
+		keys = a.jwks.Key(kid)
+	}
+
+	var std jwt.Claims
+	var custom map[string]any
+	verified := false
+	for _, k := range keys {
+		if tok.Claims(k.Key, &std, &custom) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("invalid jwt: signature does not match any key of the jwks")
+	}
+	if std.Expiry == nil {
+		return nil, errors.New("invalid jwt: exp claim is required")
+	}
+	expected := jwt.Expected{Issuer: j.Issuer, Time: time.Now()}
+	if j.Audience != "" {
+		expected.AnyAudience = jwt.Audience{j.Audience}
+	}
+	if err := std.ValidateWithLeeway(expected, jwtLeeway); err != nil {
+		return nil, fmt.Errorf("invalid jwt: %w", err)
+	}
+
+	name, _ := custom[j.PrincipalClaim].(string)
+	if name == "" {
+		return nil, fmt.Errorf("invalid jwt: no %q claim", j.PrincipalClaim)
+	}
+	policy, _ := custom[j.PolicyClaim].(string)
+	if policy == "" {
+		policy = j.DefaultPolicy
+	}
+	if policy == "" {
+		return nil, fmt.Errorf("jwt for %q: no %q claim and no default_policy", name, j.PolicyClaim)
+	}
+	if _, ok := a.policies[policy]; !ok {
+		return nil, fmt.Errorf("jwt for %q: unknown policy %q", name, policy)
+	}
+	return a.principal(name, "jwt", policy), nil
+}
+
+func (a *authenticator) principal(name, method, policy string) *Principal {
+	return &Principal{Name: name, Method: method, PolicyName: policy, Policy: a.policies[policy]}
+}
+
+// authorize checks a fully expanded request (profile applied, platform
+// defaulted) against the caller's policy. Requests without a principal,
+// when auth is disabled, are not restricted.
+func authorize(ctx context.Context, req *requestV1.BuildRequest) error {
+	if err := authorizeRRVersion(ctx, req.GetRrVersion()); err != nil {
+		return err
+	}
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || p.Policy == nil {
+		return nil
+	}
+	pol := p.Policy
+	deny := func(format string, args ...any) error { return denied(p, format, args...) }
+
+	if len(pol.Platforms) > 0 {
+		platform := req.GetTargetPlatform().GetOs() + "/" + req.GetTargetPlatform().GetArch()
+		if !velox.MatchAnyGlob(pol.Platforms, platform) {
+			return deny("platform %q is not allowed", platform)
+		}
+	}
+	if len(pol.PluginPrefixes) > 0 {
+		for _, pl := range req.GetPlugins() {
+			if !hasAnyPrefix(pl.GetModuleName(), pol.PluginPrefixes) {
+				return deny("plugin %q is outside the allowed module prefixes", pl.GetModuleName())
+			}
+		}
+	}
+	for _, r := range req.GetReplaces() {
+		// Match the module path only, like [server.modules] replace_targets.
+		target, _, _ := strings.Cut(r.GetNew(), "@")
+		if !velox.MatchAnyGlob(pol.ReplaceTargets, target) {
+			return deny("replace %s => %s is not allowed", r.GetOld(), r.GetNew())
+		}
+	}
+	return nil
+}
+
+// authorizeRRVersion checks a RoadRunner version against the rr_versions of
+// the caller's policy.
+func authorizeRRVersion(ctx context.Context, version string) error {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || p.Policy == nil || len(p.Policy.RRVersions) == 0 {
+		return nil
+	}
+	if !velox.MatchAnyGlob(p.Policy.RRVersions, version) {
+		return denied(p, "rr_version %q is not allowed", version)
+	}
+	return nil
+}
+
+func denied(p *Principal, format string, args ...any) error {
+	return connect.NewError(connect.CodePermissionDenied,
+		fmt.Errorf("principal %q (policy %q): %s", p.Name, p.PolicyName, fmt.Sprintf(format, args...)))
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/roadrunner-server/velox/v3"
+	"github.com/roadrunner-server/velox/v3/builder"
+	requestV1 "github.com/roadrunner-server/velox/v3/gen/go/api/request/v1"
+	responseV1 "github.com/roadrunner-server/velox/v3/gen/go/api/response/v1"
+	"github.com/roadrunner-server/velox/v3/internal/history"
+)
+
+// historyPruneInterval is how often the retention policy is applied.
+const historyPruneInterval = time.Hour
+
+// redacted replaces the values of ldflags -X variables in recorded
+// requests; they are where build-time secrets end up.
+const redacted = "<redacted>"
+
+// WithHistory sets the store builds are recorded in. The default keeps
+// them in memory.
+func WithHistory(s history.Store) Option {
+	return func(b *BuildServer) { b.history = s }
+}
+
+// newRecord starts the history entry of a Build request.
+func newRecord(ctx context.Context, peer connect.Peer, start time.Time) *responseV1.BuildRecord {
+	rec := &responseV1.BuildRecord{
+		Id:        history.NewID(start),
+		Peer:      peer.Addr,
+		StartedAt: timestamppb.New(start),
+	}
+	if p, ok := PrincipalFromContext(ctx); ok {
+		rec.Principal, rec.AuthMethod, rec.Policy = p.ID(), p.Method, p.PolicyName
+	}
+	return rec
+}
+
+// recordBuild completes rec with the request and outcome and stores it.
+// A failing store is logged; it never fails the build.
+func (b *BuildServer) recordBuild(rec *responseV1.BuildRecord, req *requestV1.BuildRequest, start time.Time, cached bool, err error) {
+	rec.Request, rec.PgoProfileSha256 = redactRequest(req)
+	rec.Duration = durationpb.New(time.Since(start))
+	rec.Outcome = buildOutcome(cached, err)
+	if ce, ok := errors.AsType[*connect.Error](err); ok {
+		rec.Error = ce.Message()
+	} else if err != nil {
+		rec.Error = err.Error()
+	}
+	if err := b.history.Add(rec); err != nil {
+		b.log.Error("recording build history", "id", rec.GetId(), "error", err)
+	}
+}
+
+// stageRecord converts a builder stage timing for the history.
+func stageRecord(st builder.StageTiming) *responseV1.BuildStage {
+	return &responseV1.BuildStage{Name: st.Name, Duration: durationpb.New(time.Duration(st.DurationMS) * time.Millisecond)}
+}
+
+// redactRequest returns a copy of req fit for the history: the PGO profile
+// is replaced by its digest and ldflags variable values are redacted.
+func redactRequest(req *requestV1.BuildRequest) (*requestV1.BuildRequest, string) {
+	r := proto.CloneOf(req)
+	digest := ""
+	if pgo := r.GetPgoProfile(); len(pgo) > 0 {
+		sum := sha256.Sum256(pgo)
+		digest = hex.EncodeToString(sum[:])
+		r.PgoProfile = nil
+	}
+	for i, kv := range r.GetFlags().GetLdflagsVars() {
+		if name, _, ok := strings.Cut(kv, "="); ok {
+			r.Flags.LdflagsVars[i] = name + "=" + redacted
+		}
+	}
+	return r, digest
+}
+
+// ListBuilds pages through the build history. A principal whose policy
+// lacks view_all_builds only sees its own builds; see ownBuildsOnly.
+func (b *BuildServer) ListBuilds(ctx context.Context, req *connect.Request[requestV1.ListBuildsRequest]) (*connect.Response[responseV1.ListBuildsResponse], error) {
+	q := history.Query{
+		PageSize:  int(req.Msg.GetPageSize()),
+		PageToken: req.Msg.GetPageToken(),
+		Principal: req.Msg.GetPrincipal(),
+		Outcome:   req.Msg.GetOutcome(),
+		RRVersion: req.Msg.GetRrVersion(),
+		Hash:      req.Msg.GetHash(),
+	}
+	if t := req.Msg.GetSince(); t != nil {
+		q.Since = t.AsTime()
+	}
+	if t := req.Msg.GetUntil(); t != nil {
+		q.Until = t.AsTime()
+	}
+	p, own, err := ownBuildsOnly(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if own {
+		if q.Principal != "" && q.Principal != p.ID() {
+			return nil, connect.NewError(connect.CodePermissionDenied,
+				fmt.Errorf("principal %q (policy %q) may only list its own builds", p.ID(), p.PolicyName))
+		}
+		q.Principal = p.ID()
+	}
+
+	builds, next, err := b.history.List(q)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("listing builds: %w", err))
+	}
+	return connect.NewResponse(&responseV1.ListBuildsResponse{Builds: builds, NextPageToken: next}), nil
+}
+
+// GetBuild returns one history entry. Another principal's build is
+// reported as not found to callers that may only see their own.
+func (b *BuildServer) GetBuild(ctx context.Context, req *connect.Request[requestV1.GetBuildRequest]) (*connect.Response[responseV1.BuildRecord], error) {
+	rec, err := b.lookupBuild(ctx, req.Msg.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(rec), nil
+}
+
+// lookupBuild reads a history entry the caller may see.
+func (b *BuildServer) lookupBuild(ctx context.Context, id string) (*responseV1.BuildRecord, error) {
+	p, own, err := ownBuildsOnly(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := b.history.Get(id)
+	if own && err == nil && rec.GetPrincipal() != p.ID() {
+		err = history.ErrNotFound
+	}
+	switch {
+	case errors.Is(err, history.ErrNotFound):
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("build %q not found", id))
+	case err != nil:
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("reading build %q: %w", id, err))
+	}
+	return rec, nil
+}
+
+// downloadHandler serves the binary of a history entry at
+// GET /download/{id}. Callers are authenticated like API calls when auth is
+// set, and see the builds GetBuild would show them.
+func (b *BuildServer) downloadHandler(auth *authenticator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if auth != nil {
+			var err error
+			if ctx, err = auth.withPrincipal(ctx, r.Header); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		id := r.PathValue("id")
+		rec, err := b.lookupBuild(ctx, id)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch connect.CodeOf(err) { //nolint:exhaustive // the codes lookupBuild returns
+			case connect.CodeNotFound:
+				status = http.StatusNotFound
+			case connect.CodeUnauthenticated:
+				status = http.StatusUnauthorized
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if rec.GetArtifact().GetPath() == "" {
+			http.Error(w, fmt.Sprintf("build %q produced no binary", id), http.StatusNotFound)
+			return
+		}
+		f, err := os.Open(rec.GetArtifact().GetPath())
+		if errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, fmt.Sprintf("the binary of build %q has left the cache; build it again", id), http.StatusGone)
+			return
+		}
+		if err != nil {
+			b.log.Error("opening build artifact", "id", id, "error", err)
+			http.Error(w, "opening the binary failed", http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = f.Close() }()
+		info, err := f.Stat()
+		if err != nil {
+			http.Error(w, "opening the binary failed", http.StatusInternalServerError)
+			return
+		}
+
+		p := rec.GetRequest().GetTargetPlatform()
+		name := strings.Join([]string{"rr", rec.GetRequest().GetRrVersion(), p.GetOs(), p.GetArch()}, "-")
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+		http.ServeContent(w, r, "", info.ModTime(), f)
+	})
+}
+
+// ownBuildsOnly returns the caller when it may only see its own builds.
+// Anonymous callers all share one principal, so "their own" builds would be
+// every anonymous caller's: unless their policy has view_all_builds they
+// see none.
+func ownBuildsOnly(ctx context.Context) (*Principal, bool, error) {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || (p.Policy != nil && p.Policy.ViewAllBuilds) {
+		return nil, false, nil
+	}
+	if p.Method == "anonymous" {
+		return nil, false, connect.NewError(connect.CodeUnauthenticated,
+			errors.New("the build history needs an authenticated caller"))
+	}
+	return p, true, nil
+}
+
+// openHistory opens the store [server.history] configures and returns it
+// with its retention policy.
+func openHistory(cfg *velox.History) (history.Store, history.Retention, error) {
+	if cfg == nil {
+		cfg = &velox.History{}
+	}
+	r := history.Retention{
+		MaxAge:     time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+		MaxRecords: cfg.MaxRecords,
+	}
+	if cfg.Path == "" {
+		if r.MaxRecords == 0 {
+			r.MaxRecords = velox.DefaultHistoryRecords
+		}
+		return history.NewMemory(), r, nil
+	}
+	s, err := history.OpenBolt(cfg.Path)
+	return s, r, err
+}
+
+// pruneHistory applies r to the history now and every
+// historyPruneInterval until ctx is done.
+func (b *BuildServer) pruneHistory(ctx context.Context, r history.Retention) {
+	if r == (history.Retention{}) {
+		return
+	}
+	prune := func() {
+		n, err := b.history.Prune(r, time.Now())
+		switch {
+		case err != nil:
+			b.log.Error("pruning build history", "error", err)
+		case n > 0:
+			b.log.Info("pruned build history", "deleted", n)
+		}
+	}
+	prune()
+	go func() {
+		t := time.NewTicker(historyPruneInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				prune()
+			}
+		}
+	}()
+}
@@ -1,17 +1,21 @@
 package server
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"connectrpc.com/connect"
+	"connectrpc.com/grpchealth"
 	"connectrpc.com/grpcreflect"
 	"connectrpc.com/validate"
 	"github.com/spf13/cobra"
 
+	"github.com/roadrunner-server/velox/v3"
 	servicev1 "github.com/roadrunner-server/velox/v3/gen/go/api/service/v1/serviceV1connect"
 )
 
@@ -24,37 +28,141 @@ const shutdownTimeout = 30 * time.Second
 //
 // The server honors the inherited cobra context for graceful shutdown: on
 // SIGINT/SIGTERM, in-flight HTTP/2 streams get up to shutdownTimeout to
-// finish before forced close.
+// finish before forced close. Prometheus metrics are served at /metrics,
+// liveness at /healthz and readiness (go toolchain, writable temp dir,
+// GitHub reachable) at /readyz and through grpc.health.v1.Health. A web UI
+// for composing and downloading builds is served at /ui/, and the binaries
+// of recorded builds at /download/{id}.
+//
+// --profiles names a velox config whose [plugins] and [profiles] back
+// BuildRequest.profile, whose [cgo.toolchains] are used for cgo builds,
+// whose go_version / go_toolchain_dir select the Go toolchain, whose
+// [smoke.exec_wrappers] run cross-compiled smoke tests, and whose [server]
+// section configures TLS, authentication, per-principal policies and the
+// build history behind ListBuilds/GetBuild (in memory unless
+// server.history.path names a bbolt file).
+//
+// With a certificate (server.tls or --tls-cert/--tls-key) the server speaks
+// HTTPS with HTTP/2 and reloads the certificate files when they change or on
+// SIGHUP; without one it falls back to plaintext HTTP/1.1 and h2c.
 func BindCommand(address *string, rootLog *slog.Logger) *cobra.Command {
-	return &cobra.Command{
+	var (
+		profilesPath string
+		tlsFlags     velox.ServerTLS
+	)
+
+	cmd := &cobra.Command{
 		Use:   "server",
-		Short: "Run the Velox build server (Connect / gRPC over h2c)",
+		Short: "Run the Velox build server (Connect / gRPC over TLS or h2c)",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			log := rootLog.With("component", "server")
 			log.Debug("starting velox server", "address", *address)
 
-			reflector := grpcreflect.NewStaticReflector("/api.service.v1.BuildService/")
+			var (
+				opts []Option
+				cfg  *velox.Config
+			)
+			if profilesPath != "" {
+				var err error
+				cfg, err = velox.LoadConfig(profilesPath)
+				if err != nil {
+					return fmt.Errorf("loading profiles: %w", err)
+				}
+				log.Info("profiles loaded", "path", profilesPath, "profiles", len(cfg.Profiles))
+				opts = append(opts, WithProfiles(cfg))
+			}
+
+			tlsCfg := mergeTLS(cfg, tlsFlags)
+			if err := tlsCfg.Validate(); err != nil {
+				return err
+			}
+			if cfg != nil && cfg.Server != nil && cfg.Server.Auth != nil &&
+				len(cfg.Server.Auth.ClientCerts) > 0 && tlsCfg.ClientCAFile == "" {
+				return errClientCertsWithoutCA
+			}
+
+			reflector := grpcreflect.NewStaticReflector(servicev1.BuildServiceName, grpchealth.HealthV1ServiceName)
 			mux := http.NewServeMux()
+			interceptors := []connect.Interceptor{validate.NewInterceptor()}
+			var auth *authenticator
+			if cfg != nil && cfg.Server != nil && cfg.Server.Auth != nil {
+				var err error
+				if auth, err = newAuthenticator(cfg.Server); err != nil {
+					return err
+				}
+				// Authenticate before validating, so anonymous callers learn
+				// nothing about the API.
+				interceptors = append([]connect.Interceptor{auth.Interceptor()}, interceptors...)
+				log.Info("authentication enabled",
+					"tokens", len(cfg.Server.Auth.Tokens),
+					"client_certs", len(cfg.Server.Auth.ClientCerts),
+					"jwt", cfg.Server.Auth.JWT != nil)
+			} else {
+				log.Warn("authentication disabled: every caller may build; configure [server.auth]")
+			}
+
+			var historyCfg *velox.History
+			if cfg != nil && cfg.Server != nil {
+				historyCfg = cfg.Server.History
+			}
+			store, retention, err := openHistory(historyCfg)
+			if err != nil {
+				return fmt.Errorf("opening build history: %w", err)
+			}
+			defer func() {
+				if err := store.Close(); err != nil {
+					log.Error("closing build history", "error", err)
+				}
+			}()
+			opts = append(opts, WithHistory(store))
+
+			buildServer := NewBuildServer(log, opts...)
+			buildServer.pruneHistory(cmd.Context(), retention)
 			path, handler := servicev1.NewBuildServiceHandler(
-				NewBuildServer(log),
-				connect.WithInterceptors(validate.NewInterceptor()),
+				buildServer,
+				connect.WithInterceptors(interceptors...),
 			)
 			mux.Handle(path, handler)
 			mux.Handle(grpcreflect.NewHandlerV1(reflector))
+			mux.Handle(grpchealth.NewHandler(buildServer))
+			mux.Handle("GET /metrics", buildServer.MetricsHandler())
+			mux.Handle("GET /healthz", HealthzHandler())
+			mux.Handle("GET /readyz", buildServer.ReadyzHandler())
+			mux.Handle("GET /download/{id}", buildServer.downloadHandler(auth))
+			mux.Handle("GET /ui/", http.StripPrefix("/ui", UIHandler()))
+			mux.Handle("GET /{$}", http.RedirectHandler("/ui/", http.StatusFound))
 
 			protocols := &http.Protocols{}
 			protocols.SetHTTP1(true)
-			protocols.SetUnencryptedHTTP2(true)
 			srv := &http.Server{
 				Addr:              *address,
-				Handler:           mux,
+				Handler:           withPeerCerts(mux),
 				ReadHeaderTimeout: time.Minute,
 				Protocols:         protocols,
 				HTTP2:             &http.HTTP2Config{MaxConcurrentStreams: 256},
 			}
 
+			serve := srv.ListenAndServe
+			if tlsCfg.Enabled() {
+				reloader, err := newCertReloader(tlsCfg, log.With("component", "tls"))
+				if err != nil {
+					return err
+				}
+				if err := reloader.watch(cmd.Context()); err != nil {
+					return err
+				}
+				protocols.SetHTTP2(true)
+				srv.TLSConfig = reloader.TLSConfig()
+				serve = func() error { return srv.ListenAndServeTLS("", "") }
+				log.Info("serving HTTPS", "address", *address, "cert", tlsCfg.CertFile,
+					"client_ca", tlsCfg.ClientCAFile, "min_version", cmp.Or(tlsCfg.MinVersion, "1.2"))
+			} else {
+				protocols.SetUnencryptedHTTP2(true)
+				log.Warn("TLS not configured: serving plaintext HTTP/1.1 and h2c", "address", *address)
+			}
+
 			errCh := make(chan error, 1)
-			go func() { errCh <- srv.ListenAndServe() }()
+			go func() { errCh <- serve() }()
 
 			select {
 			case <-cmd.Context().Done():
@@ -70,4 +178,29 @@ func BindCommand(address *string, rootLog *slog.Logger) *cobra.Command {
 			}
 		},
 	}
+	cmd.Flags().StringVar(&profilesPath, "profiles", "",
+		"velox config providing [profiles] for BuildRequest.profile, [cgo.toolchains] for cgo builds, the default go_version and [server] settings")
+	cmd.Flags().StringVar(&tlsFlags.CertFile, "tls-cert", "", "TLS certificate file (PEM); overrides server.tls.cert_file")
+	cmd.Flags().StringVar(&tlsFlags.KeyFile, "tls-key", "", "TLS private key file (PEM); overrides server.tls.key_file")
+	cmd.Flags().StringVar(&tlsFlags.ClientCAFile, "tls-client-ca", "",
+		"CA bundle (PEM) to verify client certificates with, enabling mTLS; overrides server.tls.client_ca_file")
+	cmd.Flags().StringVar(&tlsFlags.ClientAuth, "tls-client-auth", "",
+		"verify_if_given (default) or require; overrides server.tls.client_auth")
+	cmd.Flags().StringVar(&tlsFlags.MinVersion, "tls-min-version", "", "1.2 (default) or 1.3; overrides server.tls.min_version")
+	return cmd
+}
+
+// mergeTLS returns the [server.tls] settings of cfg with every flag that was
+// set taking precedence.
+func mergeTLS(cfg *velox.Config, flags velox.ServerTLS) *velox.ServerTLS {
+	var t velox.ServerTLS
+	if cfg != nil && cfg.Server != nil && cfg.Server.TLS != nil {
+		t = *cfg.Server.TLS
+	}
+	t.CertFile = cmp.Or(flags.CertFile, t.CertFile)
+	t.KeyFile = cmp.Or(flags.KeyFile, t.KeyFile)
+	t.ClientCAFile = cmp.Or(flags.ClientCAFile, t.ClientCAFile)
+	t.ClientAuth = cmp.Or(flags.ClientAuth, t.ClientAuth)
+	t.MinVersion = cmp.Or(flags.MinVersion, t.MinVersion)
+	return &t
 }
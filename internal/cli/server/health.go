@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpchealth"
+
+	"github.com/roadrunner-server/velox/v3"
+	"github.com/roadrunner-server/velox/v3/gen/go/api/service/v1/serviceV1connect"
+	"github.com/roadrunner-server/velox/v3/github"
+)
+
+const (
+	// readinessTTL is how long a readiness result is reused, so load balancer
+	// probes don't run `go` and call GitHub on every request.
+	readinessTTL = 10 * time.Second
+	// readinessCheckTimeout bounds each individual readiness check.
+	readinessCheckTimeout = 5 * time.Second
+)
+
+// readinessCheck is one named dependency the server needs to build.
+type readinessCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+type checkResult struct {
+	name string
+	err  error
+}
+
+// readiness runs the readiness checks and caches their results for
+// readinessTTL.
+type readiness struct {
+	checks []readinessCheck
+
+	mu      sync.Mutex
+	checked time.Time
+	results []checkResult
+}
+
+// check returns the result of every check, running them again once the
+// cached results are older than readinessTTL.
+func (r *readiness) check(ctx context.Context) []checkResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.results != nil && time.Since(r.checked) < readinessTTL {
+		return r.results
+	}
+	results := make([]checkResult, len(r.checks))
+	for i, c := range r.checks {
+		cctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+		results[i] = checkResult{name: c.name, err: c.run(cctx)}
+		cancel()
+	}
+	r.results, r.checked = results, time.Now()
+	return results
+}
+
+func ready(results []checkResult) bool {
+	for _, r := range results {
+		if r.err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// readinessChecks are the dependencies of a build: the go toolchain, a
+// writable temp dir for sources and binaries, and GitHub for RR archives.
+func (b *BuildServer) readinessChecks() []readinessCheck {
+	return []readinessCheck{
+		{name: "go", run: func(ctx context.Context) error {
+			_, err := b.goEnvVersion(ctx)
+			return err
+		}},
+		{name: "tempdir", run: func(context.Context) error { return checkTempDir() }},
+		{name: "github", run: func(ctx context.Context) error {
+			return github.NewClient("", os.Getenv("GITHUB_TOKEN"), b.rrCache, b.log.With("component", "github")).Ping(ctx)
+		}},
+	}
+}
+
+// goCommand returns the go command builds run by default: the pinned
+// toolchain from go_toolchain_dir when the server config has one, otherwise
+// "go" from PATH.
+func (b *BuildServer) goCommand() string {
+	if b.profiles == nil || b.profiles.GoVersion == "" || b.profiles.GoToolchainDir == "" {
+		return "go"
+	}
+	return filepath.Join(b.profiles.GoToolchainDir, velox.GoToolchainName(b.profiles.GoVersion), "bin", "go")
+}
+
+// goEnvVersion returns `go env GOVERSION` of the default go command. It
+// never downloads a toolchain.
+func (b *BuildServer) goEnvVersion(ctx context.Context) (string, error) {
+	out, err := b.goOutput(ctx, "env", "GOVERSION")
+	if err != nil {
+		return "", err
+	}
+	// GOVERSION may carry a " X:experiment" suffix.
+	if f := strings.Fields(out); len(f) > 0 {
+		return f[0], nil
+	}
+	return "", fmt.Errorf("%s env GOVERSION printed nothing", b.goCommand())
+}
+
+func (b *BuildServer) goOutput(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, b.goCommand(), args...)
+	cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := errors.AsType[*exec.ExitError](err); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("%s %s: %w: %s", b.goCommand(), strings.Join(args, " "), err, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("%s %s: %w", b.goCommand(), strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+// checkTempDir verifies that the temp dir builds run in is writable.
+func checkTempDir() error {
+	f, err := os.CreateTemp("", "velox-ready-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_, werr := f.WriteString("ok")
+	cerr := f.Close()
+	_ = os.Remove(name)
+	if werr != nil {
+		return werr
+	}
+	return cerr
+}
+
+// Check implements grpchealth.Checker. The server as a whole ("") and
+// BuildService are SERVING while every readiness check passes.
+func (b *BuildServer) Check(ctx context.Context, req *grpchealth.CheckRequest) (*grpchealth.CheckResponse, error) {
+	if req.Service != "" && req.Service != serviceV1connect.BuildServiceName {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("unknown service %q", req.Service))
+	}
+	if !ready(b.readiness.check(ctx)) {
+		return &grpchealth.CheckResponse{Status: grpchealth.StatusNotServing}, nil
+	}
+	return &grpchealth.CheckResponse{Status: grpchealth.StatusServing}, nil
+}
+
+// HealthzHandler answers liveness probes: the process is up and serving HTTP.
+func HealthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
+
+// ReadyzHandler answers readiness probes with 200 when every readiness
+// check passes and 503 otherwise. The body lists each check.
+func (b *BuildServer) ReadyzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := b.readiness.check(r.Context())
+		var sb strings.Builder
+		for _, res := range results {
+			if res.err != nil {
+				fmt.Fprintf(&sb, "[-]%s failed: %v\n", res.name, res.err)
+				continue
+			}
+			fmt.Fprintf(&sb, "[+]%s ok\n", res.name)
+		}
+		status := http.StatusOK
+		if ready(results) {
+			sb.WriteString("ready\n")
+		} else {
+			status = http.StatusServiceUnavailable
+			sb.WriteString("not ready\n")
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(sb.String()))
+	})
+}
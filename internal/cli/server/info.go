@@ -0,0 +1,159 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	requestV1 "github.com/roadrunner-server/velox/v3/gen/go/api/request/v1"
+	responseV1 "github.com/roadrunner-server/velox/v3/gen/go/api/response/v1"
+	"github.com/roadrunner-server/velox/v3/github"
+	"github.com/roadrunner-server/velox/v3/internal/cli/importer"
+	"github.com/roadrunner-server/velox/v3/internal/version"
+)
+
+// runningBuild describes a build in progress for the Info RPC.
+type runningBuild struct {
+	rrVersion string
+	platform  *requestV1.Platform
+	plugins   int
+	started   time.Time
+}
+
+// Info describes the server: versions, target platforms, cache statistics
+// and the builds in progress.
+func (b *BuildServer) Info(ctx context.Context, _ *connect.Request[requestV1.InfoRequest]) (*connect.Response[responseV1.InfoResponse], error) {
+	resp := &responseV1.InfoResponse{
+		VeloxVersion:   version.Version(),
+		RuntimeVersion: runtime.Version(),
+		Cache: &responseV1.CacheStats{
+			BinaryEntries: uint32(b.lru.Len()), //nolint:gosec // bounded by binaryCacheSize
+			Hits:          b.metrics.cacheHits.Load(),
+			Misses:        b.metrics.cacheMisses.Load(),
+		},
+	}
+	if goVersion, err := b.goEnvVersion(ctx); err == nil {
+		resp.GoVersion = goVersion
+	} else {
+		b.log.Warn("info: go toolchain unavailable", "error", err)
+	}
+	if c, ok := b.rrCache.(interface{ Len() int }); ok {
+		resp.Cache.ArchiveEntries = uint32(c.Len()) //nolint:gosec // bounded by the cache size
+	}
+
+	platforms, err := b.platforms(ctx)
+	if err != nil {
+		b.log.Warn("info: listing platforms", "error", err)
+	}
+	resp.Platforms = platforms
+
+	b.inflightMu.Lock()
+	for _, hash := range b.currentlyProcessing.Keys() {
+		rb, ok := b.currentlyProcessing.Peek(hash)
+		if !ok {
+			continue
+		}
+		resp.RunningBuilds = append(resp.RunningBuilds, &responseV1.RunningBuild{
+			Hash:           hash,
+			RrVersion:      rb.rrVersion,
+			TargetPlatform: rb.platform,
+			Plugins:        uint32(rb.plugins), //nolint:gosec // plugin counts are small
+			StartedAt:      timestamppb.New(rb.started),
+		})
+	}
+	b.inflightMu.Unlock()
+	slices.SortFunc(resp.RunningBuilds, func(x, y *responseV1.RunningBuild) int {
+		return x.GetStartedAt().AsTime().Compare(y.GetStartedAt().AsTime())
+	})
+	return connect.NewResponse(resp), nil
+}
+
+// rrVersionRe is the rr_version rule of the request protos.
+var rrVersionRe = regexp.MustCompile(`^(v\d+\.\d+\.\d+.*|master|[a-f0-9]{7,40})$`)
+
+// DefaultPlugins lists the plugins upstream RoadRunner bundles at the
+// requested ref, read from its container/plugins.go and go.mod like
+// `vx import` does. The ref defaults to the [roadrunner] ref of the server's
+// config, then master. Since it downloads from GitHub, it counts against the
+// caller's request rate like Build and is subject to the caller's
+// rr_versions.
+func (b *BuildServer) DefaultPlugins(ctx context.Context, req *connect.Request[requestV1.DefaultPluginsRequest]) (*connect.Response[responseV1.DefaultPluginsResponse], error) {
+	if err := b.limiter.allow(callerKey(ctx, req.Peer()), b.limitsFor(ctx)); err != nil {
+		return nil, err
+	}
+	ref := req.Msg.GetRrVersion()
+	if ref == "" && b.profiles != nil {
+		ref = b.profiles.Roadrunner["ref"]
+	}
+	ref = cmp.Or(ref, "master")
+	if !rrVersionRe.MatchString(ref) {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf(
+			"rr_version %q must be a semantic version starting with 'v' (e.g., v2025.1.0), 'master', or a git commit SHA (7-40 hex characters)", ref))
+	}
+	if err := authorizeRRVersion(ctx, ref); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "velox-defaults-")
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	gh := github.NewClient("", os.Getenv("GITHUB_TOKEN"), b.rrCache, b.log.With("component", "github"))
+	src, err := gh.DownloadTemplate(ctx, dir, "rr", ref)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("downloading template: %w", err))
+	}
+	cfg, err := importer.FromPluginsGo(src)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("reading the plugins of %s: %w", ref, err))
+	}
+
+	resp := &responseV1.DefaultPluginsResponse{RrVersion: ref}
+	for _, p := range cfg.Plugins {
+		resp.Plugins = append(resp.Plugins, &requestV1.Plugin{ModuleName: p.ModuleName, Tag: p.Tag})
+	}
+	slices.SortFunc(resp.Plugins, func(x, y *requestV1.Plugin) int {
+		return strings.Compare(x.GetModuleName(), y.GetModuleName())
+	})
+	return connect.NewResponse(resp), nil
+}
+
+// platforms returns the targets the default go toolchain supports, minus
+// windows, which velox does not build for. A successful listing is kept for
+// the lifetime of the server.
+func (b *BuildServer) platforms(ctx context.Context) ([]*requestV1.Platform, error) {
+	b.platformsMu.Lock()
+	defer b.platformsMu.Unlock()
+	if b.platformList != nil {
+		return b.platformList, nil
+	}
+	out, err := b.goOutput(ctx, "tool", "dist", "list")
+	if err != nil {
+		return nil, err
+	}
+	b.platformList = parseDistList(out)
+	return b.platformList, nil
+}
+
+// parseDistList parses `go tool dist list` output ("goos/goarch" per line).
+func parseDistList(out string) []*requestV1.Platform {
+	var platforms []*requestV1.Platform
+	for line := range strings.Lines(out) {
+		goos, goarch, ok := strings.Cut(strings.TrimSpace(line), "/")
+		if !ok || goos == "windows" {
+			continue
+		}
+		platforms = append(platforms, &requestV1.Platform{Os: goos, Arch: goarch})
+	}
+	return platforms
+}
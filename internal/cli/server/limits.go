@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+// concurrencyRetryAfter is the hint given when all of a caller's build
+// slots are taken; a running build has no predictable end.
+const concurrencyRetryAfter = 30 * time.Second
+
+// limiter enforces [server.limits] per caller. Its state is in memory, so
+// a restart forgives everyone.
+type limiter struct {
+	mu  sync.Mutex
+	now func() time.Time
+	// day is the UTC date the CPU counters belong to.
+	day     string
+	callers map[string]*callerState
+}
+
+type callerState struct {
+	// tokens is the request bucket's level at refilled.
+	tokens   float64
+	refilled time.Time
+	running  int
+	cpu      time.Duration
+}
+
+func newLimiter() *limiter {
+	return &limiter{now: time.Now, callers: map[string]*callerState{}}
+}
+
+// state returns the caller's state. At UTC midnight the CPU counters start
+// over and idle callers are forgotten, which bounds the map for IP keys.
+// l.mu must be held.
+func (l *limiter) state(key string, now time.Time) *callerState {
+	if day := now.UTC().Format(time.DateOnly); day != l.day {
+		l.day = day
+		for k, c := range l.callers {
+			if c.running == 0 {
+				delete(l.callers, k)
+			} else {
+				c.cpu = 0
+			}
+		}
+	}
+	c, ok := l.callers[key]
+	if !ok {
+		c = &callerState{}
+		l.callers[key] = c
+	}
+	return c
+}
+
+// allow takes a token from the caller's request bucket.
+func (l *limiter) allow(key string, lim *velox.Limits) error {
+	if lim == nil || lim.RequestsPerMinute == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	c := l.state(key, now)
+	perSecond := float64(lim.RequestsPerMinute) / 60
+	if c.refilled.IsZero() {
+		c.tokens = float64(lim.Burst)
+	} else {
+		c.tokens = min(float64(lim.Burst), c.tokens+now.Sub(c.refilled).Seconds()*perSecond)
+	}
+	c.refilled = now
+	if c.tokens >= 1 {
+		c.tokens--
+		return nil
+	}
+	wait := time.Duration((1 - c.tokens) / perSecond * float64(time.Second))
+	return resourceExhausted(wait, fmt.Errorf("%s: rate limit of %d requests per minute exceeded", key, lim.RequestsPerMinute))
+}
+
+// acquire takes one of the caller's build slots, provided its daily CPU
+// quota is not used up. release returns the slot and charges the CPU time
+// the build consumed.
+func (l *limiter) acquire(key string, lim *velox.Limits) (release func(cpu time.Duration), err error) {
+	if lim == nil || (lim.MaxConcurrentBuilds == 0 && lim.DailyCPUSeconds == 0) {
+		return func(time.Duration) {}, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	c := l.state(key, now)
+	if quota := time.Duration(lim.DailyCPUSeconds) * time.Second; quota > 0 && c.cpu >= quota {
+		y, m, d := now.UTC().Date()
+		midnight := time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+		return nil, resourceExhausted(midnight.Sub(now),
+			fmt.Errorf("%s: daily CPU quota of %s used up (%s)", key, quota, c.cpu.Round(time.Second)))
+	}
+	if lim.MaxConcurrentBuilds > 0 && c.running >= lim.MaxConcurrentBuilds {
+		return nil, resourceExhausted(concurrencyRetryAfter,
+			fmt.Errorf("%s: %d concurrent builds already running", key, c.running))
+	}
+	c.running++
+	return func(cpu time.Duration) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		c.running--
+		c.cpu += cpu
+	}, nil
+}
+
+// resourceExhausted is the error of a throttled request. The retry hint is
+// sent as a Retry-After header (whole seconds) and a google.rpc.RetryInfo
+// detail for gRPC clients.
+func resourceExhausted(retryAfter time.Duration, err error) *connect.Error {
+	secs := max(1, int64(math.Ceil(retryAfter.Seconds())))
+	retryAfter = time.Duration(secs) * time.Second
+	e := connect.NewError(connect.CodeResourceExhausted, fmt.Errorf("%w; retry after %s", err, retryAfter))
+	e.Meta().Set("Retry-After", strconv.FormatInt(secs, 10))
+	if detail, derr := connect.NewErrorDetail(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); derr == nil {
+		e.AddDetail(detail)
+	}
+	return e
+}
+
+// callerKey identifies whom limits apply to: the authenticated principal,
+// or the remote IP of anonymous callers.
+func callerKey(ctx context.Context, peer connect.Peer) string {
+	if p, ok := PrincipalFromContext(ctx); ok && p.Method != "anonymous" {
+		return fmt.Sprintf("principal %q", p.ID())
+	}
+	host, _, err := net.SplitHostPort(peer.Addr)
+	if err != nil {
+		host = peer.Addr
+	}
+	return "ip " + host
+}
+
+// limitsFor returns the caller's policy limits, falling back to
+// [server.limits].
+func (b *BuildServer) limitsFor(ctx context.Context) *velox.Limits {
+	if p, ok := PrincipalFromContext(ctx); ok && p.Policy != nil && p.Policy.Limits != nil {
+		return p.Policy.Limits
+	}
+	if b.profiles != nil && b.profiles.Server != nil {
+		return b.profiles.Server.Limits
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/roadrunner-server/velox/v3/builder"
+)
+
+const metricsNamespace = "velox"
+
+// Build outcomes besides the Connect error codes of failed requests.
+const (
+	outcomeSuccess = "success"
+	outcomeCached  = "cached"
+)
+
+// metrics are the Prometheus metrics of one BuildServer. Each server owns
+// its registry, so several servers (or tests) never collide.
+type metrics struct {
+	registry      *prometheus.Registry
+	builds        *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	stageDuration *prometheus.HistogramVec
+	queueDepth    prometheus.Gauge
+	cacheLookups  *prometheus.CounterVec
+
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		builds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "builds_total",
+			Help:      "Build requests by outcome: success, cached, or the Connect error code.",
+		}, []string{"outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "build_duration_seconds",
+			Help:      "Time to answer a build request, by outcome.",
+			Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200},
+		}, []string{"outcome"}),
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "build_stage_duration_seconds",
+			Help:      "Duration of the individual build stages.",
+			Buckets:   []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600},
+		}, []string{"stage"}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "build_queue_depth",
+			Help:      "Build requests accepted and not yet answered.",
+		}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "binary_cache_lookups_total",
+			Help:      "Binary cache lookups by result (hit or miss).",
+		}, []string{"result"}),
+	}
+	m.registry.MustRegister(
+		m.builds, m.duration, m.stageDuration, m.queueDepth, m.cacheLookups,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "binary_cache_hit_ratio",
+			Help:      "Share of binary cache lookups that were hits since the server started.",
+		}, m.cacheHitRatio),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// cacheLookup counts one binary cache lookup.
+func (m *metrics) cacheLookup(hit bool) {
+	if hit {
+		m.cacheHits.Add(1)
+		m.cacheLookups.WithLabelValues("hit").Inc()
+		return
+	}
+	m.cacheMisses.Add(1)
+	m.cacheLookups.WithLabelValues("miss").Inc()
+}
+
+func (m *metrics) cacheHitRatio() float64 {
+	hits, misses := m.cacheHits.Load(), m.cacheMisses.Load()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// observeBuild records a finished build request. cached marks a response
+// served from the binary cache; a non-nil err is labeled with its code.
+func (m *metrics) observeBuild(start time.Time, cached bool, err error) {
+	outcome := buildOutcome(cached, err)
+	m.builds.WithLabelValues(outcome).Inc()
+	m.duration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+}
+
+// observeStages records the stage timings of a build report.
+func (m *metrics) observeStages(stages []builder.StageTiming) {
+	for _, s := range stages {
+		m.stageDuration.WithLabelValues(s.Name).Observe(float64(s.DurationMS) / 1000)
+	}
+}
+
+// buildOutcome labels a finished build request: success, cached, or the
+// Connect code of err.
+func buildOutcome(cached bool, err error) string {
+	switch {
+	case err != nil:
+		return connect.CodeOf(err).String()
+	case cached:
+		return outcomeCached
+	}
+	return outcomeSuccess
+}
+
+// MetricsHandler serves the server's Prometheus metrics.
+func (b *BuildServer) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(b.metrics.registry, promhttp.HandlerOpts{Registry: b.metrics.registry})
+}
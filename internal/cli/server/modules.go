@@ -0,0 +1,53 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	"github.com/roadrunner-server/velox/v3"
+	requestV1 "github.com/roadrunner-server/velox/v3/gen/go/api/request/v1"
+)
+
+// checkModules applies the server-wide [server.modules] policy to an
+// expanded request. A local-path replace is malformed for a remote build
+// unless explicitly allowed (CodeInvalidArgument); modules the allow/deny
+// lists reject are CodePermissionDenied. Both name the offending entry.
+func (b *BuildServer) checkModules(req *requestV1.BuildRequest) error {
+	var m *velox.ModulePolicy
+	if b.profiles != nil && b.profiles.Server != nil {
+		m = b.profiles.Server.Modules
+	}
+	if m == nil {
+		m = &velox.ModulePolicy{}
+	}
+	denied := func(format string, args ...any) error {
+		return connect.NewError(connect.CodePermissionDenied, fmt.Errorf(format, args...))
+	}
+
+	for i, p := range req.GetPlugins() {
+		if !m.Plugins.Permits(p.GetModuleName()) {
+			return denied("plugins[%d]: module %q is not allowed by server.modules.plugins", i, p.GetModuleName())
+		}
+	}
+	for i, r := range req.GetReplaces() {
+		if velox.IsLocalPath(r.GetNew()) {
+			if !m.AllowLocalReplaces {
+				return connect.NewError(connect.CodeInvalidArgument,
+					fmt.Errorf("replaces[%d]: local path %q is not accepted by this server (server.modules.allow_local_replaces)", i, r.GetNew()))
+			}
+			continue
+		}
+		target, _, _ := strings.Cut(r.GetNew(), "@")
+		if !m.ReplaceTargets.Permits(target) {
+			return denied("replaces[%d]: target %q is not allowed by server.modules.replace_targets", i, r.GetNew())
+		}
+	}
+	for i, e := range req.GetExcludes() {
+		if !m.Excludes.Permits(e.GetModule()) {
+			return denied("excludes[%d]: module %q is not allowed by server.modules.excludes", i, e.GetModule())
+		}
+	}
+	return nil
+}
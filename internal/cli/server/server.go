@@ -5,9 +5,12 @@ package server
 import (
 	"cmp"
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -18,13 +21,19 @@ import (
 
 	"connectrpc.com/connect"
 	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/roadrunner-server/velox/v3"
 	"github.com/roadrunner-server/velox/v3/builder"
 	requestV1 "github.com/roadrunner-server/velox/v3/gen/go/api/request/v1"
 	responseV1 "github.com/roadrunner-server/velox/v3/gen/go/api/response/v1"
 	"github.com/roadrunner-server/velox/v3/github"
+	"github.com/roadrunner-server/velox/v3/internal/history"
+	"github.com/roadrunner-server/velox/v3/internal/telemetry"
 	"github.com/roadrunner-server/velox/v3/plugin"
 )
 
@@ -35,21 +44,43 @@ const (
 	processingLockTTL  = 5 * time.Minute
 )
 
+var tracer = otel.Tracer("github.com/roadrunner-server/velox/v3/internal/cli/server")
+
 // BuildServer is the Connect/gRPC handler for BuildService.
 type BuildServer struct {
 	log                 *slog.Logger
 	lru                 *lru.LRU[string, string]
-	currentlyProcessing *lru.LRU[string, struct{}]
+	currentlyProcessing *lru.LRU[string, runningBuild]
 	// inflightMu serializes the Contains/Add pair on currentlyProcessing so
 	// two concurrent identical requests can't both pass the dedupe check.
 	inflightMu sync.Mutex
 	rrCache    github.Cache
+	// profiles holds the config whose [profiles] BuildRequest.profile selects
+	// from; nil when the server was started without one.
+	profiles  *velox.Config
+	metrics   *metrics
+	readiness *readiness
+	limiter   *limiter
+	history   history.Store
+
+	// platformsMu guards platformList, the cached `go tool dist list`.
+	platformsMu  sync.Mutex
+	platformList []*requestV1.Platform
+}
+
+// Option configures a BuildServer. Pass these to NewBuildServer.
+type Option func(*BuildServer)
+
+// WithProfiles sets the validated config whose [plugins] and [profiles]
+// back BuildRequest.profile.
+func WithProfiles(cfg *velox.Config) Option {
+	return func(b *BuildServer) { b.profiles = cfg }
 }
 
 // NewBuildServer constructs the server with bounded caches and per-eviction
 // cleanup of on-disk artifacts.
-func NewBuildServer(log *slog.Logger) *BuildServer {
-	return &BuildServer{
+func NewBuildServer(log *slog.Logger, opts ...Option) *BuildServer {
+	b := &BuildServer{
 		log: log,
 		lru: lru.NewLRU(binaryCacheSize, func(hash, rrBinPath string) {
 			log.Info("evicting binary cache entry",
@@ -62,48 +93,146 @@ func NewBuildServer(log *slog.Logger) *BuildServer {
 				log.Error("removing temp dir", "path", tempDir, "error", err)
 			}
 		}, binaryCacheTTL),
-		currentlyProcessing: lru.NewLRU(processingLockSize, func(key string, _ struct{}) {
+		currentlyProcessing: lru.NewLRU(processingLockSize, func(key string, _ runningBuild) {
 			log.Info("releasing in-flight lock", "key", key)
 		}, processingLockTTL),
 		rrCache: github.NewLRUCache(0),
+		metrics: newMetrics(),
+		limiter: newLimiter(),
+		history: history.NewMemory(),
+	}
+	for _, opt := range opts {
+		opt(b)
 	}
+	b.readiness = &readiness{checks: b.readinessChecks()}
+	return b
 }
 
 // Build handles a single BuildRequest: deduplicates concurrent identical
 // requests, serves cached results when possible, and otherwise drives the
 // Builder pipeline end-to-end.
 func (b *BuildServer) Build(ctx context.Context, req *connect.Request[requestV1.BuildRequest]) (*connect.Response[responseV1.BuildResponse], error) {
+	resp, err := b.build(ctx, req, b.log)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// build runs a Build request, logging its progress to log.
+func (b *BuildServer) build(ctx context.Context, req *connect.Request[requestV1.BuildRequest], log *slog.Logger) (_ *responseV1.BuildResponse, retErr error) {
+	start, cached := time.Now(), false
+	b.metrics.queueDepth.Inc()
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Header()))
+	ctx, span := tracer.Start(ctx, "BuildService.Build", trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+		telemetry.AttrRRRef.String(req.Msg.GetRrVersion()),
+		telemetry.AttrPluginCount.Int(len(req.Msg.GetPlugins())),
+	))
+	defer func() {
+		b.metrics.queueDepth.Dec()
+		b.metrics.observeBuild(start, cached, retErr)
+		telemetry.End(span, retErr)
+	}()
+	rec := newRecord(ctx, req.Peer(), start)
+	defer func() { b.recordBuild(rec, req.Msg, start, cached, retErr) }()
+
+	caller, limits := callerKey(ctx, req.Peer()), b.limitsFor(ctx)
+	if err := b.limiter.allow(caller, limits); err != nil {
+		return nil, err
+	}
+
+	// Expand the profile first so it can supply the target platform and so
+	// the cache key describes the build itself, not how it was requested.
+	if err := b.applyProfile(req.Msg); err != nil {
+		return nil, err
+	}
+
 	// Default a missing target_platform to the host BEFORE hashing so that
 	// `{platform: nil}` and `{platform: <host>}` produce the same cache key —
 	// they describe the same build.
 	if req.Msg.GetTargetPlatform() == nil {
-		b.log.Info("target platform unspecified; using host platform")
+		log.Info("target platform unspecified; using host platform")
 		req.Msg.TargetPlatform = &requestV1.Platform{Os: runtime.GOOS, Arch: runtime.GOARCH}
 	}
 
+	// Likewise, a request without go_version builds with the server's pin.
+	if req.Msg.GetGoVersion() == "" && b.profiles != nil {
+		req.Msg.GoVersion = b.profiles.GoVersion
+	}
+	if err := velox.ValidateGoVersion(req.Msg.GetGoVersion()); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	// Reject bad flags and profiles before the request can match a cached
+	// or in-flight build.
+	if err := validateBuildFlags(toBuildFlags(req.Msg.GetFlags())); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if pgo := req.Msg.GetPgoProfile(); len(pgo) > 0 {
+		if req.Msg.GetFlags().GetPgo() == velox.PGOOff {
+			return nil, connect.NewError(connect.CodeInvalidArgument,
+				errors.New(`pgo_profile cannot be combined with flags.pgo = "off"`))
+		}
+		if err := builder.ValidatePGOProfile(pgo); err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+	}
+
+	// Authorize the expanded request, so a profile can't smuggle in what
+	// the caller's policy forbids.
+	if p, ok := PrincipalFromContext(ctx); ok {
+		span.SetAttributes(telemetry.AttrPrincipal.String(p.ID()))
+		log.Info("build requested", "principal", p.Name, "auth", p.Method, "policy", p.PolicyName)
+	}
+	if err := b.checkModules(req.Msg); err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, req.Msg); err != nil {
+		return nil, err
+	}
+
 	hash, err := b.generateCacheHash(req.Msg)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("generating cache hash: %w", err))
 	}
-	b.log.Debug("cache key computed", "hash", hash)
+	log.Debug("cache key computed", "hash", hash)
+	span.SetAttributes(telemetry.AttrBuildHash.String(hash))
+	rec.Hash = hash
 
 	b.inflightMu.Lock()
 	if b.currentlyProcessing.Contains(hash) {
 		b.inflightMu.Unlock()
 		return nil, connect.NewError(connect.CodeAlreadyExists, fmt.Errorf("build %s is already in progress", hash))
 	}
-	b.currentlyProcessing.Add(hash, struct{}{})
+	b.currentlyProcessing.Add(hash, runningBuild{
+		rrVersion: req.Msg.GetRrVersion(),
+		platform:  req.Msg.GetTargetPlatform(),
+		plugins:   len(req.Msg.GetPlugins()),
+		started:   start,
+	})
 	b.inflightMu.Unlock()
 	defer b.currentlyProcessing.Remove(hash)
 
-	if cached, ok := b.lru.Get(hash); ok && !req.Msg.GetForceRebuild() {
-		b.log.Debug("cache hit", "hash", hash)
-		return connect.NewResponse(&responseV1.BuildResponse{
-			Path: cached,
-			Logs: "cached output, logs are available only on the first build",
-		}), nil
+	cachedPath, ok := b.lru.Get(hash)
+	cached = ok && !req.Msg.GetForceRebuild()
+	b.metrics.cacheLookup(cached)
+	span.SetAttributes(telemetry.AttrCacheHit.Bool(cached))
+	if cached {
+		log.Debug("cache hit", "hash", hash)
+		rec.Artifact = &responseV1.Artifact{Path: cachedPath}
+		return &responseV1.BuildResponse{
+			Path:    cachedPath,
+			Logs:    "cached output, logs are available only on the first build",
+			BuildId: rec.GetId(),
+		}, nil
 	}
 
+	release, err := b.limiter.acquire(caller, limits)
+	if err != nil {
+		return nil, err
+	}
+	var cpu time.Duration
+	defer func() { release(cpu) }()
+
 	plugins := make([]*plugin.Plugin, 0, len(req.Msg.GetPlugins()))
 	for _, p := range req.Msg.GetPlugins() {
 		if p == nil {
@@ -113,16 +242,29 @@ func (b *BuildServer) Build(ctx context.Context, req *connect.Request[requestV1.
 	}
 	replaces := toReplaces(req.Msg.GetReplaces())
 	excludes := toExcludes(req.Msg.GetExcludes())
+	flags := toBuildFlags(req.Msg.GetFlags())
+	cgo := b.cgoConfig(req.Msg.GetFlags())
+	if cgo.Enabled || req.Msg.GetRace() {
+		p := req.Msg.GetTargetPlatform()
+		tc := cgo.Toolchain(p.GetOs(), p.GetArch())
+		if err := velox.CheckCGOTarget(p.GetOs(), p.GetArch(), runtime.GOOS, runtime.GOARCH, tc, cgo.Static); err != nil {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+		}
+	}
 
-	gh := github.NewClient("", os.Getenv("GITHUB_TOKEN"), b.rrCache, b.log.With("component", "github"))
+	gh := github.NewClient("", os.Getenv("GITHUB_TOKEN"), b.rrCache, log.With("component", "github"))
+	dlStart := time.Now()
 	rrPath, err := gh.DownloadTemplate(ctx, os.TempDir(), hash, req.Msg.GetRrVersion())
+	download := builder.StageTiming{Name: "download", DurationMS: time.Since(dlStart).Milliseconds()}
+	b.metrics.observeStages([]builder.StageTiming{download})
+	rec.Stages = append(rec.Stages, stageRecord(download))
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("downloading template: %w", err))
 	}
 
 	outputPath := filepath.Join(os.TempDir(), hash)
-	binaryPath, err := builder.NewBuilder(rrPath,
-		builder.WithLogger(b.log.With("component", "build")),
+	bld := builder.NewBuilder(rrPath,
+		builder.WithLogger(log.With("component", "build")),
 		builder.WithPlugins(plugins...),
 		builder.WithReplaces(replaces),
 		builder.WithExcludes(excludes),
@@ -132,20 +274,46 @@ func (b *BuildServer) Build(ctx context.Context, req *connect.Request[requestV1.
 		builder.WithGOARCH(req.Msg.GetTargetPlatform().GetArch()),
 		builder.WithDebug(req.Msg.GetDebug()),
 		builder.WithRace(req.Msg.GetRace()),
-	).Build(ctx, req.Msg.GetRrVersion())
+		builder.WithTags(req.Msg.GetTags()...),
+		builder.WithBuildFlags(flags),
+		builder.WithCGOConfig(cgo),
+		builder.WithPGOProfile(req.Msg.GetPgoProfile()),
+		builder.WithGoVersion(req.Msg.GetGoVersion()),
+		builder.WithGoToolchainDir(b.goToolchainDir()),
+		builder.WithSandbox(b.sandboxConfig()),
+		builder.WithExecWrapper(b.execWrapper(req.Msg.GetTargetPlatform())),
+	)
+	binaryPath, err := bld.Build(ctx, req.Msg.GetRrVersion())
+	report := bld.Report()
+	b.metrics.observeStages(report.Stages)
+	cpu = time.Duration(report.CPUTimeMS) * time.Millisecond
+	rec.CpuTime = durationpb.New(cpu)
+	for _, st := range report.Stages {
+		rec.Stages = append(rec.Stages, stageRecord(st))
+	}
+	if bin := report.Binary; bin != nil {
+		rec.Artifact = &responseV1.Artifact{Path: bin.Path, Size: bin.Size, Sha256: bin.SHA256}
+	}
+	log.Info("build finished", "caller", caller, "cpu", cpu)
 	if err != nil {
-		b.log.Error("build failed", "error", err)
+		log.Error("build failed", "error", err)
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("building plugins: %w", err))
 	}
 
 	b.lru.Add(hash, binaryPath)
-	return connect.NewResponse(&responseV1.BuildResponse{Path: binaryPath}), nil
+	return &responseV1.BuildResponse{Path: binaryPath, BuildId: rec.GetId()}, nil
 }
 
 // generateCacheHash produces a deterministic key for the request. RequestId is
 // excluded (UUID per call) and all repeated fields are sorted so two
-// semantically equal requests with reordered lists produce the same hash.
+// semantically equal requests with reordered lists produce the same hash. An
+// uploaded PGO profile contributes its sha256 digest rather than its bytes.
 func (b *BuildServer) generateCacheHash(req *requestV1.BuildRequest) (string, error) {
+	var pgoDigest []byte
+	if pgo := req.GetPgoProfile(); len(pgo) > 0 {
+		sum := sha256.Sum256(pgo)
+		pgoDigest = sum[:]
+	}
 	keyed := &requestV1.BuildRequest{
 		RrVersion:      req.GetRrVersion(),
 		TargetPlatform: req.GetTargetPlatform(),
@@ -154,6 +322,10 @@ func (b *BuildServer) generateCacheHash(req *requestV1.BuildRequest) (string, er
 		Excludes:       sortedExcludes(req.GetExcludes()),
 		Race:           req.GetRace(),
 		Debug:          req.GetDebug(),
+		Tags:           sortedTags(req.GetTags()),
+		Flags:          normalizedFlags(req.GetFlags()),
+		PgoProfile:     pgoDigest,
+		GoVersion:      velox.GoToolchainName(req.GetGoVersion()),
 	}
 	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(keyed)
 	if err != nil {
@@ -164,6 +336,116 @@ func (b *BuildServer) generateCacheHash(req *requestV1.BuildRequest) (string, er
 	return strconv.FormatUint(h.Sum64(), 16), nil
 }
 
+// applyProfile folds the requested profile into req: the profile's plugins
+// come first and request plugins add to or override them by module name,
+// debug/race are enabled if either side enables them, the profile's platform
+// is used when the request has none, and build tags are merged.
+func (b *BuildServer) applyProfile(req *requestV1.BuildRequest) error {
+	name := req.GetProfile()
+	if name == "" {
+		return nil
+	}
+	if b.profiles == nil {
+		return connect.NewError(connect.CodeInvalidArgument,
+			fmt.Errorf("profile %q requested but the server has no profiles configured", name))
+	}
+	sel, err := b.profiles.Select(name)
+	if err != nil {
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	plugins := make([]*requestV1.Plugin, 0, len(sel.Plugins)+len(req.GetPlugins()))
+	index := make(map[string]int, len(sel.Plugins))
+	for _, pn := range slices.Sorted(maps.Keys(sel.Plugins)) {
+		p := sel.Plugins[pn]
+		index[p.ModuleName] = len(plugins)
+		plugins = append(plugins, &requestV1.Plugin{ModuleName: p.ModuleName, Tag: p.Tag})
+	}
+	for _, p := range req.GetPlugins() {
+		if i, ok := index[p.GetModuleName()]; ok {
+			plugins[i] = p
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+	req.Plugins = plugins
+
+	req.Debug = req.GetDebug() || sel.Debug
+	req.Race = req.GetRace() || sel.Race
+	if req.GetTargetPlatform() == nil && sel.TargetPlatform.OS != "" {
+		req.TargetPlatform = &requestV1.Platform{Os: sel.TargetPlatform.OS, Arch: sel.TargetPlatform.Arch}
+	}
+	for _, t := range sel.Tags {
+		if !slices.Contains(req.GetTags(), t) {
+			req.Tags = append(req.Tags, t)
+		}
+	}
+	req.Profile = ""
+	return nil
+}
+
+// cgoConfig combines the request's cgo switches with the C toolchains from
+// the server's own config. Toolchains are never taken from requests: CC is a
+// command the server would execute.
+func (b *BuildServer) cgoConfig(f *requestV1.BuildFlags) *velox.CGO {
+	c := &velox.CGO{Enabled: f.GetCgo(), Static: f.GetStatic()}
+	if b.profiles != nil && b.profiles.CGO != nil {
+		c.Toolchains = b.profiles.CGO.Toolchains
+	}
+	return c
+}
+
+// goToolchainDir returns the local toolchain directory from the server's
+// config. Like C toolchains, it is never taken from requests.
+func (b *BuildServer) goToolchainDir() string {
+	if b.profiles == nil {
+		return ""
+	}
+	return b.profiles.GoToolchainDir
+}
+
+// sandboxConfig returns the server's [sandbox] section, if any.
+func (b *BuildServer) sandboxConfig() *velox.Sandbox {
+	if b.profiles == nil {
+		return nil
+	}
+	return b.profiles.Sandbox
+}
+
+// execWrapper returns the smoke test exec wrapper the server's config sets
+// for platform. Like C toolchains, wrappers are never taken from requests.
+func (b *BuildServer) execWrapper(p *requestV1.Platform) string {
+	if b.profiles == nil {
+		return ""
+	}
+	return b.profiles.Smoke.ExecWrapper(p.GetOs(), p.GetArch())
+}
+
+// normalizedFlags drops flag values that equal the defaults so that an
+// absent flags message and one spelling out the defaults hash the same.
+func normalizedFlags(f *requestV1.BuildFlags) *requestV1.BuildFlags {
+	if f == nil {
+		return nil
+	}
+	out := proto.CloneOf(f)
+	if out.Trimpath != nil && out.GetTrimpath() {
+		out.Trimpath = nil
+	}
+	if out.GetBuildmode() == "exe" || out.GetBuildmode() == "default" {
+		out.Buildmode = ""
+	}
+	if proto.Size(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func sortedTags(in []string) []string {
+	out := slices.Clone(in)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
 func sortedPlugins(in []*requestV1.Plugin) []*requestV1.Plugin {
 	out := slices.Clone(in)
 	slices.SortStableFunc(out, func(a, b *requestV1.Plugin) int {
@@ -221,3 +503,37 @@ func toExcludes(in []*requestV1.Exclude) []velox.Exclude {
 	}
 	return out
 }
+
+// validateBuildFlags checks request flags like [build] ones, and their
+// gcflags against the stricter velox.RemoteGcflags.
+func validateBuildFlags(flags *velox.Build) error {
+	if err := flags.Validate(); err != nil || flags == nil {
+		return err
+	}
+	for _, f := range flags.Gcflags {
+		if err := velox.ValidateRemoteGcflag(f); err != nil {
+			return fmt.Errorf("flags.gcflags: %w", err)
+		}
+	}
+	// Any other value is a profile path the builder would read from the
+	// server's file system; remote profiles come as pgo_profile.
+	switch flags.PGO {
+	case "", velox.PGOAuto, velox.PGOOff:
+	default:
+		return fmt.Errorf("flags.pgo: %q is not allowed; want %q or %q", flags.PGO, velox.PGOAuto, velox.PGOOff)
+	}
+	return nil
+}
+
+func toBuildFlags(f *requestV1.BuildFlags) *velox.Build {
+	if f == nil {
+		return nil
+	}
+	return &velox.Build{
+		Vars:      f.GetLdflagsVars(),
+		Gcflags:   f.GetGcflags(),
+		PGO:       f.GetPgo(),
+		Buildmode: f.GetBuildmode(),
+		Trimpath:  f.Trimpath,
+	}
+}
@@ -1,9 +1,47 @@
 package server
 
 import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"maps"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"buf.build/go/protovalidate"
+	"connectrpc.com/connect"
+	"connectrpc.com/grpchealth"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	"github.com/roadrunner-server/velox/v3"
 	requestV1 "github.com/roadrunner-server/velox/v3/gen/go/api/request/v1"
+	responseV1 "github.com/roadrunner-server/velox/v3/gen/go/api/response/v1"
+	"github.com/roadrunner-server/velox/v3/gen/go/api/service/v1/serviceV1connect"
+	"github.com/roadrunner-server/velox/v3/internal/history"
+	"github.com/roadrunner-server/velox/v3/logger"
 )
 
 // hashOf computes the cache key for req. generateCacheHash does not touch any
@@ -42,6 +80,14 @@ func sampleRequest() *requestV1.BuildRequest {
 	}
 }
 
+// remoteRequest is sampleRequest without the local-path replaces that a
+// server rejects by default.
+func remoteRequest() *requestV1.BuildRequest {
+	req := sampleRequest()
+	req.Replaces = nil
+	return req
+}
+
 func TestGenerateCacheHash_OrderIndependent(t *testing.T) {
 	a := sampleRequest()
 
@@ -81,6 +127,18 @@ func TestGenerateCacheHash_DistinguishesRealFields(t *testing.T) {
 			r.Excludes = append(r.Excludes, &requestV1.Exclude{Module: "github.com/x/y", Version: "v1.2.3"})
 		},
 		"replace_new_path": func(r *requestV1.BuildRequest) { r.Replaces[0].New = "../somewhere-else" },
+		"tags":             func(r *requestV1.BuildRequest) { r.Tags = []string{"otel"} },
+		"ldflags_vars": func(r *requestV1.BuildRequest) {
+			r.Flags = &requestV1.BuildFlags{LdflagsVars: []string{"main.env=prod"}}
+		},
+		"gcflags":     func(r *requestV1.BuildRequest) { r.Flags = &requestV1.BuildFlags{Gcflags: []string{"-B"}} },
+		"pgo":         func(r *requestV1.BuildRequest) { r.Flags = &requestV1.BuildFlags{Pgo: "auto"} },
+		"buildmode":   func(r *requestV1.BuildRequest) { r.Flags = &requestV1.BuildFlags{Buildmode: "pie"} },
+		"pgo_profile": func(r *requestV1.BuildRequest) { r.PgoProfile = []byte("profile-a") },
+		"go_version":  func(r *requestV1.BuildRequest) { r.GoVersion = "1.26.1" },
+		"no_trimpath": func(r *requestV1.BuildRequest) {
+			r.Flags = &requestV1.BuildFlags{Trimpath: new(false)}
+		},
 	}
 
 	for name, mutate := range cases {
@@ -93,3 +151,1235 @@ func TestGenerateCacheHash_DistinguishesRealFields(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateCacheHash_TagsOrderIndependent(t *testing.T) {
+	a := sampleRequest()
+	a.Tags = []string{"otel", "nats"}
+	b := sampleRequest()
+	b.Tags = []string{"nats", "otel", "nats"}
+
+	if hashOf(t, a) != hashOf(t, b) {
+		t.Fatalf("reordered tags produced different hashes:\n a=%s\n b=%s", hashOf(t, a), hashOf(t, b))
+	}
+}
+
+func TestGenerateCacheHash_PGOProfileDigest(t *testing.T) {
+	a := sampleRequest()
+	a.PgoProfile = []byte("profile-a")
+	b := sampleRequest()
+	b.PgoProfile = []byte("profile-a")
+	c := sampleRequest()
+	c.PgoProfile = []byte("profile-b")
+
+	if hashOf(t, a) != hashOf(t, b) {
+		t.Fatal("identical PGO profiles must hash equally")
+	}
+	if hashOf(t, a) == hashOf(t, c) {
+		t.Fatal("different PGO profiles must hash differently")
+	}
+}
+
+func TestGenerateCacheHash_DefaultFlags(t *testing.T) {
+	base := hashOf(t, sampleRequest())
+
+	for name, flags := range map[string]*requestV1.BuildFlags{
+		"empty":     {},
+		"trimpath":  {Trimpath: new(true)},
+		"buildmode": {Buildmode: "exe"},
+	} {
+		req := sampleRequest()
+		req.Flags = flags
+		if got := hashOf(t, req); got != base {
+			t.Fatalf("%s: flags equal to the defaults must not change the hash (%s != %s)", name, got, base)
+		}
+	}
+}
+
+func TestGenerateCacheHash_GoVersionPrefix(t *testing.T) {
+	a := sampleRequest()
+	a.GoVersion = "1.26.1"
+	b := sampleRequest()
+	b.GoVersion = "go1.26.1"
+
+	if hashOf(t, a) != hashOf(t, b) {
+		t.Fatal("go_version with and without the go prefix must hash equally")
+	}
+}
+
+func profilesConfig(t *testing.T) *velox.Config {
+	t.Helper()
+	race := true
+	cfg := &velox.Config{
+		TargetPlatform: &velox.TargetPlatform{OS: "linux", Arch: "arm64"},
+		Plugins: map[string]*velox.Plugin{
+			"logger": {ModuleName: "github.com/roadrunner-server/logger/v6", Tag: "v6.1.0"},
+			"http":   {ModuleName: "github.com/roadrunner-server/http/v6", Tag: "v6.1.0"},
+			"amqp":   {ModuleName: "github.com/roadrunner-server/amqp/v6", Tag: "v6.0.0"},
+		},
+		Profiles: map[string]*velox.Profile{
+			"minimal": {Plugins: []string{"logger", "http"}, Race: &race, Tags: []string{"otel"}},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	return cfg
+}
+
+func TestApplyProfile(t *testing.T) {
+	srv := &BuildServer{profiles: profilesConfig(t)}
+	req := &requestV1.BuildRequest{
+		RrVersion: "v2025.1.0",
+		Profile:   "minimal",
+		Plugins:   []*requestV1.Plugin{{ModuleName: "github.com/roadrunner-server/http/v6", Tag: "v6.2.0"}},
+		Tags:      []string{"nats"},
+	}
+	if err := srv.applyProfile(req); err != nil {
+		t.Fatalf("applyProfile: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, p := range req.GetPlugins() {
+		got[p.GetModuleName()] = p.GetTag()
+	}
+	want := map[string]string{
+		"github.com/roadrunner-server/http/v6":   "v6.2.0",
+		"github.com/roadrunner-server/logger/v6": "v6.1.0",
+	}
+	if !maps.Equal(got, want) {
+		t.Fatalf("plugins = %v, want %v", got, want)
+	}
+	if !req.GetRace() || req.GetDebug() {
+		t.Fatalf("race/debug = %t/%t, want true/false", req.GetRace(), req.GetDebug())
+	}
+	if p := req.GetTargetPlatform(); p.GetOs() != "linux" || p.GetArch() != "arm64" {
+		t.Fatalf("platform = %v, want linux/arm64", p)
+	}
+	if !slices.Equal(req.GetTags(), []string{"nats", "otel"}) {
+		t.Fatalf("tags = %v", req.GetTags())
+	}
+
+	// The expanded request and an equivalent explicit one share a cache entry.
+	explicit := &requestV1.BuildRequest{
+		RrVersion:      "v2025.1.0",
+		TargetPlatform: &requestV1.Platform{Os: "linux", Arch: "arm64"},
+		Plugins: []*requestV1.Plugin{
+			{ModuleName: "github.com/roadrunner-server/logger/v6", Tag: "v6.1.0"},
+			{ModuleName: "github.com/roadrunner-server/http/v6", Tag: "v6.2.0"},
+		},
+		Race: true,
+		Tags: []string{"otel", "nats"},
+	}
+	if hashOf(t, req) != hashOf(t, explicit) {
+		t.Fatal("profile request and equivalent explicit request must hash equally")
+	}
+}
+
+func TestApplyProfile_Errors(t *testing.T) {
+	req := &requestV1.BuildRequest{RrVersion: "v2025.1.0", Profile: "minimal"}
+	err := (&BuildServer{}).applyProfile(req)
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Fatalf("no profiles configured: got %v, want InvalidArgument", err)
+	}
+
+	req.Profile = "nope"
+	err = (&BuildServer{profiles: profilesConfig(t)}).applyProfile(req)
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Fatalf("unknown profile: got %v, want InvalidArgument", err)
+	}
+}
+
+func TestBuild_CGOCrossCompileWithoutToolchain(t *testing.T) {
+	arch := "arm64"
+	if runtime.GOARCH == arch {
+		arch = "amd64"
+	}
+	req := remoteRequest()
+	req.TargetPlatform = &requestV1.Platform{Os: "linux", Arch: arch}
+	req.Flags = &requestV1.BuildFlags{Cgo: true}
+
+	_, err := NewBuildServer(logger.Discard()).Build(context.Background(), connect.NewRequest(req))
+	if connect.CodeOf(err) != connect.CodeFailedPrecondition {
+		t.Fatalf("got %v, want FailedPrecondition", err)
+	}
+	if !strings.Contains(err.Error(), "cgo.toolchains.linux_"+arch) {
+		t.Fatalf("error should name the missing toolchain section: %v", err)
+	}
+}
+
+func TestBuild_Metrics(t *testing.T) {
+	s := NewBuildServer(logger.Discard())
+	s.lru.Add(hashOf(t, remoteRequest()), "/tmp/rr")
+
+	if _, err := s.Build(context.Background(), connect.NewRequest(remoteRequest())); err != nil {
+		t.Fatalf("cached build: %v", err)
+	}
+	bad := remoteRequest()
+	bad.GoVersion = "not-a-version"
+	if _, err := s.Build(context.Background(), connect.NewRequest(bad)); connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Fatalf("got %v, want InvalidArgument", err)
+	}
+
+	for outcome, want := range map[string]float64{"cached": 1, "invalid_argument": 1, "success": 0} {
+		if got := testutil.ToFloat64(s.metrics.builds.WithLabelValues(outcome)); got != want {
+			t.Fatalf("builds_total{outcome=%q} = %v, want %v", outcome, got, want)
+		}
+	}
+	if got := testutil.ToFloat64(s.metrics.queueDepth); got != 0 {
+		t.Fatalf("queue depth = %v after all requests returned, want 0", got)
+	}
+	if got := s.metrics.cacheHitRatio(); got != 1 {
+		t.Fatalf("cache hit ratio = %v, want 1 (the rejected request never reached the cache)", got)
+	}
+
+	rec := httptest.NewRecorder()
+	s.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, name := range []string{"velox_builds_total", "velox_build_queue_depth", "velox_binary_cache_hit_ratio"} {
+		if !strings.Contains(rec.Body.String(), name) {
+			t.Fatalf("/metrics lacks %s", name)
+		}
+	}
+}
+
+func TestParseDistList(t *testing.T) {
+	got := parseDistList("linux/amd64\nwindows/amd64\ndarwin/arm64\n\n")
+	if len(got) != 2 || got[0].GetOs() != "linux" || got[1].GetArch() != "arm64" {
+		t.Fatalf("parseDistList = %v, want linux/amd64 and darwin/arm64 without windows", got)
+	}
+}
+
+func TestInfo_RunningBuildsAndCache(t *testing.T) {
+	s := NewBuildServer(logger.Discard())
+	s.lru.Add("cached", "/tmp/rr")
+	s.metrics.cacheLookup(true)
+	s.metrics.cacheLookup(false)
+	now := time.Now()
+	s.currentlyProcessing.Add("newer", runningBuild{rrVersion: "v2025.1.1", plugins: 2, started: now})
+	s.currentlyProcessing.Add("older", runningBuild{rrVersion: "v2025.1.0", plugins: 3, started: now.Add(-time.Minute)})
+
+	resp, err := s.Info(context.Background(), connect.NewRequest(&requestV1.InfoRequest{}))
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	info := resp.Msg
+	if info.GetVeloxVersion() == "" || info.GetRuntimeVersion() != runtime.Version() {
+		t.Fatalf("versions: velox=%q runtime=%q", info.GetVeloxVersion(), info.GetRuntimeVersion())
+	}
+	if c := info.GetCache(); c.GetBinaryEntries() != 1 || c.GetHits() != 1 || c.GetMisses() != 1 {
+		t.Fatalf("cache stats = %v", c)
+	}
+	rb := info.GetRunningBuilds()
+	if len(rb) != 2 || rb[0].GetHash() != "older" || rb[0].GetPlugins() != 3 || rb[1].GetRrVersion() != "v2025.1.1" {
+		t.Fatalf("running builds = %v, want older then newer", rb)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	s := NewBuildServer(logger.Discard())
+	calls := 0
+	s.readiness = &readiness{checks: []readinessCheck{
+		{name: "tempdir", run: func(context.Context) error { calls++; return checkTempDir() }},
+		{name: "github", run: func(context.Context) error { return errors.New("dial tcp: no route to host") }},
+	}}
+
+	rec := httptest.NewRecorder()
+	s.ReadyzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz = %d, want 503", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "[+]tempdir ok") || !strings.Contains(body, "[-]github failed: dial tcp") {
+		t.Fatalf("/readyz body lacks the check results:\n%s", body)
+	}
+
+	resp, err := s.Check(context.Background(), &grpchealth.CheckRequest{})
+	if err != nil || resp.Status != grpchealth.StatusNotServing {
+		t.Fatalf("Check = %v, %v; want NOT_SERVING", resp, err)
+	}
+	if calls != 1 {
+		t.Fatalf("checks ran %d times, want 1 (results are cached for %s)", calls, readinessTTL)
+	}
+	if _, err := s.Check(context.Background(), &grpchealth.CheckRequest{Service: "other.Service"}); connect.CodeOf(err) != connect.CodeNotFound {
+		t.Fatalf("unknown service: got %v, want NotFound", err)
+	}
+
+	rec = httptest.NewRecorder()
+	HealthzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/healthz = %d, want 200", rec.Code)
+	}
+}
+
+// authConfig is a validated [server] section with one principal per
+// authentication method.
+func authConfig(t *testing.T, jwks string) *velox.Server {
+	t.Helper()
+	s := &velox.Server{
+		Auth: &velox.Auth{
+			Tokens:      map[string]*velox.AuthToken{"ci": {Token: "s3cret", Policy: "ci"}},
+			ClientCerts: map[string]*velox.AuthClientCert{"builder": {CommonName: "builder.internal", Policy: "ci"}},
+			JWT:         &velox.AuthJWT{JWKSFile: jwks, Issuer: "https://idp.example", Audience: "velox", DefaultPolicy: "ci"},
+		},
+		Policies: map[string]*velox.Policy{
+			"ci": {
+				RRVersions:     []string{"v2025.*"},
+				PluginPrefixes: []string{"github.com/roadrunner-server/"},
+				ReplaceTargets: []string{"github.com/acme/*"},
+				Platforms:      []string{"linux/*"},
+			},
+		},
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	return s
+}
+
+// signJWT returns a JWKS file with a fresh ES256 key and a signer for it.
+func signJWT(t *testing.T) (string, func(claims map[string]any) string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: "k1", Algorithm: "ES256", Use: "sig"}}})
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "k1"))
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	return path, func(claims map[string]any) string {
+		tok, err := jwt.Signed(signer).Claims(claims).Serialize()
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return tok
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	jwks, sign := signJWT(t)
+	a, err := newAuthenticator(authConfig(t, jwks))
+	if err != nil {
+		t.Fatalf("newAuthenticator: %v", err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	valid := map[string]any{"sub": "alice", "iss": "https://idp.example", "aud": "velox", "exp": exp}
+	withClaim := func(k string, v any) map[string]any {
+		c := maps.Clone(valid)
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+		return c
+	}
+	certCtx := func(cn string) context.Context {
+		return context.WithValue(context.Background(), peerCertKey{}, &x509.Certificate{Subject: pkix.Name{CommonName: cn}})
+	}
+
+	cases := []struct {
+		name          string
+		ctx           context.Context
+		authz         string
+		wantPrincipal string
+		wantMethod    string
+		wantErr       string
+	}{
+		{name: "static token", authz: "Bearer s3cret", wantPrincipal: "ci", wantMethod: "token"},
+		{name: "wrong token", authz: "Bearer nope", wantErr: "invalid token"},
+		{name: "basic auth", authz: "Basic Zm9vOmJhcg==", wantErr: "bearer"},
+		{name: "no credentials", wantErr: "credentials required"},
+		{name: "jwt", authz: "Bearer " + sign(valid), wantPrincipal: "alice", wantMethod: "jwt"},
+		{name: "jwt expired", authz: "Bearer " + sign(withClaim("exp", time.Now().Add(-time.Hour).Unix())), wantErr: "expired"},
+		{name: "jwt without exp", authz: "Bearer " + sign(withClaim("exp", nil)), wantErr: "exp claim is required"},
+		{name: "jwt wrong audience", authz: "Bearer " + sign(withClaim("aud", "other")), wantErr: "audience"},
+		{name: "jwt unknown policy", authz: "Bearer " + sign(withClaim("velox_policy", "admin")), wantErr: `unknown policy "admin"`},
+		{name: "mtls", ctx: certCtx("builder.internal"), wantPrincipal: "builder", wantMethod: "mtls"},
+		{name: "mtls unmapped", ctx: certCtx("laptop"), wantErr: "not mapped"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cmp.Or(tc.ctx, context.Background())
+			h := http.Header{}
+			if tc.authz != "" {
+				h.Set("Authorization", tc.authz)
+			}
+			p, err := a.authenticate(ctx, h)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authenticate: %v", err)
+			}
+			if p.Name != tc.wantPrincipal || p.Method != tc.wantMethod || p.PolicyName != "ci" {
+				t.Fatalf("principal = %+v, want %s via %s with policy ci", p, tc.wantPrincipal, tc.wantMethod)
+			}
+		})
+	}
+
+	a.auth.AnonymousPolicy = "ci"
+	if p, err := a.authenticate(context.Background(), http.Header{}); err != nil || p.Method != "anonymous" {
+		t.Fatalf("anonymous: %+v, %v", p, err)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	policy := authConfig(t, "unused").Policies["ci"]
+	ctx := context.WithValue(context.Background(), principalKey{}, &Principal{Name: "ci", PolicyName: "ci", Policy: policy})
+
+	allowed := sampleRequest()
+	allowed.Replaces = []*requestV1.Replace{{Old: "github.com/foo/bar", New: "github.com/acme/bar@v1.0.0"}}
+	if err := authorize(ctx, allowed); err != nil {
+		t.Fatalf("allowed request denied: %v", err)
+	}
+	if err := authorize(context.Background(), sampleRequest()); err != nil {
+		t.Fatalf("without auth every request is allowed: %v", err)
+	}
+	// replace_targets match the module path without its @version.
+	exact := *policy
+	exact.ReplaceTargets = []string{"github.com/acme/bar"}
+	exactCtx := context.WithValue(context.Background(), principalKey{}, &Principal{Name: "ci", PolicyName: "ci", Policy: &exact})
+	if err := authorize(exactCtx, allowed); err != nil {
+		t.Fatalf("versioned replace of an allowed module denied: %v", err)
+	}
+
+	cases := map[string]struct {
+		mutate func(r *requestV1.BuildRequest)
+		want   string
+	}{
+		"rr version": {func(r *requestV1.BuildRequest) { r.RrVersion = "v2024.3.0" }, `rr_version "v2024.3.0"`},
+		"platform":   {func(r *requestV1.BuildRequest) { r.TargetPlatform = &requestV1.Platform{Os: "darwin", Arch: "arm64"} }, `platform "darwin/arm64"`},
+		"plugin": {func(r *requestV1.BuildRequest) {
+			r.Plugins = append(r.Plugins, &requestV1.Plugin{ModuleName: "github.com/evil/plugin", Tag: "v1.0.0"})
+		}, `plugin "github.com/evil/plugin"`},
+		"local replace": {func(r *requestV1.BuildRequest) {
+			r.Replaces = []*requestV1.Replace{{Old: "github.com/foo/bar", New: "../bar"}}
+		}, "replace github.com/foo/bar => ../bar"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := sampleRequest()
+			req.Replaces = nil
+			tc.mutate(req)
+			err := authorize(ctx, req)
+			if connect.CodeOf(err) != connect.CodePermissionDenied || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("got %v, want PermissionDenied naming %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	jwks, _ := signJWT(t)
+	a, err := newAuthenticator(authConfig(t, jwks))
+	if err != nil {
+		t.Fatalf("newAuthenticator: %v", err)
+	}
+	var seen *Principal
+	next := connect.UnaryFunc(func(ctx context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		seen, _ = PrincipalFromContext(ctx)
+		return nil, nil
+	})
+	call := a.Interceptor().WrapUnary(next)
+
+	if _, err := call(context.Background(), connect.NewRequest(&requestV1.InfoRequest{})); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("got %v, want Unauthenticated", err)
+	}
+	req := connect.NewRequest(&requestV1.InfoRequest{})
+	req.Header().Set("Authorization", "Bearer s3cret")
+	if _, err := call(context.Background(), req); err != nil || seen == nil || seen.Name != "ci" {
+		t.Fatalf("authenticated call: principal %+v, err %v", seen, err)
+	}
+}
+
+// testCA issues certificates for the TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "velox test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for cn, valid for localhost.
+func (ca *testCA) issue(t *testing.T, cn string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeServerCert writes a server certificate for cn into dir.
+func writeServerCert(t *testing.T, ca *testCA, dir, cn string) *velox.ServerTLS {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, cn, x509.ExtKeyUsageServerAuth)
+	cfg := &velox.ServerTLS{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	}
+	for path, data := range map[string][]byte{cfg.CertFile: certPEM, cfg.KeyFile: keyPEM, cfg.ClientCAFile: ca.pem} {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	return cfg
+}
+
+func servedCN(t *testing.T, r *certReloader) string {
+	t.Helper()
+	cert, err := r.TLSConfig().GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("GetCertificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("parse served certificate: %v", err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	ca, dir := newTestCA(t), t.TempDir()
+	cfg := writeServerCert(t, ca, dir, "first")
+	r, err := newCertReloader(cfg, logger.Discard())
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+	if cn := servedCN(t, r); cn != "first" {
+		t.Fatalf("served %q, want first", cn)
+	}
+
+	writeServerCert(t, ca, dir, "second")
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if cn := servedCN(t, r); cn != "second" {
+		t.Fatalf("served %q after reload, want second", cn)
+	}
+
+	if err := os.WriteFile(cfg.KeyFile, []byte("half-written"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reload(); err == nil {
+		t.Fatalf("reload of a broken key succeeded")
+	}
+	if cn := servedCN(t, r); cn != "second" {
+		t.Fatalf("served %q after a failed reload, want the previous certificate", cn)
+	}
+}
+
+func TestCertReloader_WatchFiles(t *testing.T) {
+	ca, dir := newTestCA(t), t.TempDir()
+	r, err := newCertReloader(writeServerCert(t, ca, dir, "first"), logger.Discard())
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := r.watch(ctx); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+
+	writeServerCert(t, ca, dir, "rotated")
+	deadline := time.Now().Add(5 * time.Second)
+	for servedCN(t, r) != "rotated" {
+		if time.Now().After(deadline) {
+			t.Fatalf("certificate was not reloaded after the files changed")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestTLS_ClientCertReachesInterceptor(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := writeServerCert(t, ca, t.TempDir(), "server")
+	r, err := newCertReloader(cfg, logger.Discard())
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(withPeerCerts(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cn := "none"
+		if cert, ok := r.Context().Value(peerCertKey{}).(*x509.Certificate); ok {
+			cn = cert.Subject.CommonName
+		}
+		_, _ = w.Write([]byte(cn))
+	})))
+	srv.TLS = r.TLSConfig()
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs ...tls.Certificate) (string, error) {
+		c := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certs, MinVersion: tls.VersionTLS12},
+			ForceAttemptHTTP2: true,
+		}}
+		resp, err := c.Get(srv.URL)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		if resp.ProtoMajor != 2 {
+			t.Fatalf("negotiated %s, want HTTP/2", resp.Proto)
+		}
+		return string(body), err
+	}
+
+	certPEM, keyPEM := ca.issue(t, "builder.internal", x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair: %v", err)
+	}
+	if cn, err := get(clientCert); err != nil || cn != "builder.internal" {
+		t.Fatalf("with client cert: %q, %v", cn, err)
+	}
+	if cn, err := get(); err != nil || cn != "none" {
+		t.Fatalf("verify_if_given must accept callers without a cert: %q, %v", cn, err)
+	}
+}
+
+func TestMergeTLS(t *testing.T) {
+	cfg := &velox.Config{Server: &velox.Server{TLS: &velox.ServerTLS{CertFile: "cfg.crt", KeyFile: "cfg.key", MinVersion: "1.3"}}}
+	got := mergeTLS(cfg, velox.ServerTLS{CertFile: "flag.crt", KeyFile: "flag.key"})
+	if got.CertFile != "flag.crt" || got.KeyFile != "flag.key" || got.MinVersion != "1.3" {
+		t.Fatalf("mergeTLS = %+v, want flags over config", got)
+	}
+	if cfg.Server.TLS.CertFile != "cfg.crt" {
+		t.Fatalf("mergeTLS modified the config")
+	}
+	if mergeTLS(nil, velox.ServerTLS{}).Enabled() {
+		t.Fatalf("no cert configured: TLS must stay off (h2c)")
+	}
+}
+
+func TestCheckModules(t *testing.T) {
+	// Without [server.modules], only local-path replaces are refused.
+	if err := (&BuildServer{}).checkModules(sampleRequest()); connect.CodeOf(err) != connect.CodeInvalidArgument || !strings.Contains(err.Error(), `"../bar"`) {
+		t.Fatalf("local replace: got %v, want InvalidArgument naming ../bar", err)
+	}
+	if err := (&BuildServer{}).checkModules(remoteRequest()); err != nil {
+		t.Fatalf("no policy: %v", err)
+	}
+
+	s := &BuildServer{profiles: &velox.Config{Server: &velox.Server{Modules: &velox.ModulePolicy{
+		AllowLocalReplaces: true,
+		Plugins:            &velox.GlobList{Allow: []string{"github.com/roadrunner-server/*"}, Deny: []string{"*/rpc/*"}},
+		ReplaceTargets:     &velox.GlobList{Allow: []string{"github.com/acme/*"}},
+		Excludes:           &velox.GlobList{Deny: []string{"github.com/aaa/*"}},
+	}}}}
+	cases := []struct {
+		name   string
+		mutate func(*requestV1.BuildRequest)
+		code   connect.Code
+		entry  string
+	}{
+		{"plugin denied", func(*requestV1.BuildRequest) {}, connect.CodePermissionDenied, "github.com/roadrunner-server/rpc/v6"},
+		{"plugin not allowed", func(r *requestV1.BuildRequest) {
+			r.Plugins = []*requestV1.Plugin{{ModuleName: "github.com/evil/plugin", Tag: "v1.0.0"}}
+		}, connect.CodePermissionDenied, "github.com/evil/plugin"},
+		{"replace target", func(r *requestV1.BuildRequest) {
+			r.Plugins = r.Plugins[:2]
+			r.Replaces = []*requestV1.Replace{{Old: "github.com/foo/bar", New: "github.com/evil/bar@v1.0.0"}}
+		}, connect.CodePermissionDenied, "github.com/evil/bar@v1.0.0"},
+		{"exclude", func(r *requestV1.BuildRequest) {
+			r.Plugins = r.Plugins[:2]
+			r.Replaces = []*requestV1.Replace{{Old: "github.com/foo/bar", New: "github.com/acme/bar@v1.0.0"}}
+		}, connect.CodePermissionDenied, "github.com/aaa/bbb"},
+		{"allowed", func(r *requestV1.BuildRequest) {
+			r.Plugins = r.Plugins[:2]
+			r.Excludes = r.Excludes[:1]
+		}, 0, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := sampleRequest()
+			tc.mutate(req)
+			err := s.checkModules(req)
+			if tc.code == 0 {
+				if err != nil {
+					t.Fatalf("got %v, want nil", err)
+				}
+				return
+			}
+			if connect.CodeOf(err) != tc.code {
+				t.Fatalf("got %v, want %v", err, tc.code)
+			}
+			if !strings.Contains(err.Error(), tc.entry) {
+				t.Fatalf("error %q does not name %s", err, tc.entry)
+			}
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	l := newLimiter()
+	l.now = func() time.Time { return now }
+
+	retryAfter := func(err error) string {
+		t.Helper()
+		if connect.CodeOf(err) != connect.CodeResourceExhausted {
+			t.Fatalf("got %v, want ResourceExhausted", err)
+		}
+		ce, ok := errors.AsType[*connect.Error](err)
+		if !ok {
+			t.Fatalf("%v is not a *connect.Error", err)
+		}
+		return ce.Meta().Get("Retry-After")
+	}
+
+	// 6 requests per minute: one token every 10s, bursts of 2.
+	rate := &velox.Limits{RequestsPerMinute: 6, Burst: 2}
+	for i := range 2 {
+		if err := l.allow("ip 10.0.0.1", rate); err != nil {
+			t.Fatalf("request %d within burst: %v", i, err)
+		}
+	}
+	if got := retryAfter(l.allow("ip 10.0.0.1", rate)); got != "10" {
+		t.Fatalf("Retry-After = %q, want 10", got)
+	}
+	if err := l.allow("ip 10.0.0.2", rate); err != nil {
+		t.Fatalf("callers have separate buckets: %v", err)
+	}
+	now = now.Add(10 * time.Second)
+	if err := l.allow("ip 10.0.0.1", rate); err != nil {
+		t.Fatalf("bucket should have refilled one token: %v", err)
+	}
+
+	// Concurrency and the daily CPU quota.
+	quota := &velox.Limits{MaxConcurrentBuilds: 1, DailyCPUSeconds: 60}
+	release, err := l.acquire(`principal "ci"`, quota)
+	if err != nil {
+		t.Fatalf("first build: %v", err)
+	}
+	if got := retryAfter(func() error { _, err := l.acquire(`principal "ci"`, quota); return err }()); got != "30" {
+		t.Fatalf("concurrency Retry-After = %q, want 30", got)
+	}
+	release(2 * time.Minute)
+	_, err = l.acquire(`principal "ci"`, quota)
+	if got := retryAfter(err); got != "3590" {
+		t.Fatalf("quota Retry-After = %q, want the 3590s until UTC midnight", got)
+	}
+	if !strings.Contains(err.Error(), "daily CPU quota") {
+		t.Fatalf("error should name the quota: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	release, err = l.acquire(`principal "ci"`, quota)
+	if err != nil {
+		t.Fatalf("quota should reset at UTC midnight: %v", err)
+	}
+	release(0)
+	if _, ok := l.callers["ip 10.0.0.2"]; ok {
+		t.Fatalf("idle callers should be forgotten at UTC midnight")
+	}
+}
+
+func TestResourceExhausted_RetryInfo(t *testing.T) {
+	err := resourceExhausted(1500*time.Millisecond, errors.New("slow down"))
+	if got := err.Meta().Get("Retry-After"); got != "2" {
+		t.Fatalf("Retry-After = %q, want 2 (rounded up)", got)
+	}
+	if len(err.Details()) != 1 {
+		t.Fatalf("want one RetryInfo detail, got %d", len(err.Details()))
+	}
+	v, derr := err.Details()[0].Value()
+	info, ok := v.(*errdetails.RetryInfo)
+	if derr != nil || !ok || info.GetRetryDelay().AsDuration() != 2*time.Second {
+		t.Fatalf("detail = %v, %v; want RetryInfo of 2s", v, derr)
+	}
+}
+
+func TestBuild_RateLimited(t *testing.T) {
+	cfg := &velox.Config{Server: &velox.Server{Limits: &velox.Limits{RequestsPerMinute: 1, Burst: 1}}}
+	s := NewBuildServer(logger.Discard(), WithProfiles(cfg))
+	s.lru.Add(hashOf(t, remoteRequest()), "/tmp/rr")
+
+	if _, err := s.Build(context.Background(), connect.NewRequest(remoteRequest())); err != nil {
+		t.Fatalf("first build: %v", err)
+	}
+	_, err := s.Build(context.Background(), connect.NewRequest(remoteRequest()))
+	if connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("got %v, want ResourceExhausted", err)
+	}
+	if got := testutil.ToFloat64(s.metrics.builds.WithLabelValues("resource_exhausted")); got != 1 {
+		t.Fatalf("builds_total{outcome=resource_exhausted} = %v, want 1", got)
+	}
+
+	// A policy's limits replace the server's.
+	ctx := context.WithValue(context.Background(), principalKey{}, &Principal{
+		Name: "ci", Method: "token", PolicyName: "ci", Policy: &velox.Policy{Limits: &velox.Limits{RequestsPerMinute: 60, Burst: 5}},
+	})
+	if _, err := s.Build(ctx, connect.NewRequest(remoteRequest())); err != nil {
+		t.Fatalf("principal with its own limits: %v", err)
+	}
+}
+
+func TestBuild_RecordsHistory(t *testing.T) {
+	s := NewBuildServer(logger.Discard())
+	s.lru.Add(hashOf(t, remoteRequest()), "/tmp/rr")
+	ctx := context.WithValue(context.Background(), principalKey{}, &Principal{
+		Name: "ci", Method: "token", PolicyName: "ci", Policy: &velox.Policy{},
+	})
+
+	if _, err := s.Build(ctx, connect.NewRequest(remoteRequest())); err != nil {
+		t.Fatalf("cached build: %v", err)
+	}
+	bad := remoteRequest()
+	bad.GoVersion = "not-a-version"
+	bad.PgoProfile = []byte("profile")
+	bad.Flags = &requestV1.BuildFlags{LdflagsVars: []string{"main.token=s3cret"}}
+	if _, err := s.Build(ctx, connect.NewRequest(bad)); err == nil {
+		t.Fatal("want the invalid go_version rejected")
+	}
+
+	builds, _, err := s.history.List(history.Query{})
+	if err != nil || len(builds) != 2 {
+		t.Fatalf("history = %d builds, %v; want 2", len(builds), err)
+	}
+	rejected, cached := builds[0], builds[1]
+	if cached.GetOutcome() != "cached" || cached.GetHash() != hashOf(t, remoteRequest()) ||
+		cached.GetArtifact().GetPath() != "/tmp/rr" || cached.GetPrincipal() != "token:ci" || cached.GetAuthMethod() != "token" {
+		t.Fatalf("cached build recorded as %v", cached)
+	}
+	if rejected.GetOutcome() != "invalid_argument" || rejected.GetError() == "" || rejected.GetHash() != "" {
+		t.Fatalf("rejected build recorded as %v", rejected)
+	}
+	if got := rejected.GetRequest().GetFlags().GetLdflagsVars(); !slices.Equal(got, []string{"main.token=" + redacted}) {
+		t.Fatalf("ldflags_vars recorded as %v, want the value redacted", got)
+	}
+	if rejected.GetRequest().GetPgoProfile() != nil || len(rejected.GetPgoProfileSha256()) != 64 {
+		t.Fatalf("pgo profile recorded as %q / %q, want only its digest", rejected.GetRequest().GetPgoProfile(), rejected.GetPgoProfileSha256())
+	}
+	if bad.GetFlags().GetLdflagsVars()[0] != "main.token=s3cret" {
+		t.Fatal("redacting must not modify the request being built")
+	}
+}
+
+func TestListBuilds_Ownership(t *testing.T) {
+	s := NewBuildServer(logger.Discard())
+	start := time.Now()
+	for i, name := range []string{"token:ci", "token:dev", "token:ci"} {
+		rec := &responseV1.BuildRecord{Id: history.NewID(start.Add(time.Duration(i) * time.Second)), Principal: name, Outcome: "success"}
+		if err := s.history.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	as := func(name string, all bool) context.Context {
+		return context.WithValue(context.Background(), principalKey{}, &Principal{
+			Name: name, Method: "token", PolicyName: name, Policy: &velox.Policy{ViewAllBuilds: all},
+		})
+	}
+	list := func(ctx context.Context, req *requestV1.ListBuildsRequest) ([]*responseV1.BuildRecord, error) {
+		t.Helper()
+		resp, err := s.ListBuilds(ctx, connect.NewRequest(req))
+		if err != nil {
+			return nil, err
+		}
+		return resp.Msg.GetBuilds(), nil
+	}
+
+	if got, err := list(context.Background(), &requestV1.ListBuildsRequest{}); err != nil || len(got) != 3 {
+		t.Fatalf("without auth: %d builds, %v; want all 3", len(got), err)
+	}
+	if got, err := list(as("ci", false), &requestV1.ListBuildsRequest{}); err != nil || len(got) != 2 || got[0].GetPrincipal() != "token:ci" {
+		t.Fatalf("own builds: %v, %v", got, err)
+	}
+	if _, err := list(as("ci", false), &requestV1.ListBuildsRequest{Principal: "token:dev"}); connect.CodeOf(err) != connect.CodePermissionDenied {
+		t.Fatalf("listing another principal's builds: got %v, want PermissionDenied", err)
+	}
+	if got, err := list(as("admin", true), &requestV1.ListBuildsRequest{Principal: "token:dev"}); err != nil || len(got) != 1 {
+		t.Fatalf("view_all_builds filtering by principal: %v, %v", got, err)
+	}
+
+	resp, err := s.ListBuilds(as("admin", true), connect.NewRequest(&requestV1.ListBuildsRequest{PageSize: 2}))
+	if err != nil || len(resp.Msg.GetBuilds()) != 2 || resp.Msg.GetNextPageToken() == "" {
+		t.Fatalf("first page: %v, %v", resp, err)
+	}
+	next, err := list(as("admin", true), &requestV1.ListBuildsRequest{PageSize: 2, PageToken: resp.Msg.GetNextPageToken()})
+	if err != nil || len(next) != 1 || next[0].GetPrincipal() != "token:ci" {
+		t.Fatalf("second page: %v, %v", next, err)
+	}
+
+	devID := resp.Msg.GetBuilds()[1].GetId() // newest first: ci, dev, ci
+	if _, err := s.GetBuild(as("ci", false), connect.NewRequest(&requestV1.GetBuildRequest{Id: devID})); connect.CodeOf(err) != connect.CodeNotFound {
+		t.Fatalf("another principal's build: got %v, want NotFound", err)
+	}
+	if got, err := s.GetBuild(as("dev", false), connect.NewRequest(&requestV1.GetBuildRequest{Id: devID})); err != nil || got.Msg.GetPrincipal() != "token:dev" {
+		t.Fatalf("own build: %v, %v", got, err)
+	}
+	if _, err := s.GetBuild(context.Background(), connect.NewRequest(&requestV1.GetBuildRequest{Id: "missing"})); connect.CodeOf(err) != connect.CodeNotFound {
+		t.Fatalf("unknown id: got %v, want NotFound", err)
+	}
+	// A JWT whose subject matches a token principal's name is another caller.
+	jwtCI := context.WithValue(context.Background(), principalKey{}, &Principal{
+		Name: "ci", Method: "jwt", PolicyName: "ci", Policy: &velox.Policy{},
+	})
+	if got, err := list(jwtCI, &requestV1.ListBuildsRequest{}); err != nil || len(got) != 0 {
+		t.Fatalf("jwt:ci listed %v, %v; want none of token:ci's builds", got, err)
+	}
+	if _, err := s.GetBuild(jwtCI, connect.NewRequest(&requestV1.GetBuildRequest{Id: next[0].GetId()})); connect.CodeOf(err) != connect.CodeNotFound {
+		t.Fatalf("jwt:ci reading token:ci's build: got %v, want NotFound", err)
+	}
+}
+
+func TestListBuilds_Anonymous(t *testing.T) {
+	s := NewBuildServer(logger.Discard())
+	rec := &responseV1.BuildRecord{Id: history.NewID(time.Now()), Principal: "anonymous:anonymous", Outcome: "success"}
+	if err := s.history.Add(rec); err != nil {
+		t.Fatal(err)
+	}
+	anonymous := func(all bool) context.Context {
+		return context.WithValue(context.Background(), principalKey{}, &Principal{
+			Name: "anonymous", Method: "anonymous", PolicyName: "public", Policy: &velox.Policy{ViewAllBuilds: all},
+		})
+	}
+
+	// Every anonymous caller is the same principal, so they see no builds
+	// rather than each other's.
+	if _, err := s.ListBuilds(anonymous(false), connect.NewRequest(&requestV1.ListBuildsRequest{})); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("ListBuilds: got %v, want Unauthenticated", err)
+	}
+	if _, err := s.GetBuild(anonymous(false), connect.NewRequest(&requestV1.GetBuildRequest{Id: rec.GetId()})); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("GetBuild: got %v, want Unauthenticated", err)
+	}
+	if resp, err := s.ListBuilds(anonymous(true), connect.NewRequest(&requestV1.ListBuildsRequest{})); err != nil || len(resp.Msg.GetBuilds()) != 1 {
+		t.Fatalf("anonymous with view_all_builds: %v, %v", resp, err)
+	}
+}
+
+func TestBuildStream(t *testing.T) {
+	jwks, _ := signJWT(t)
+	a, err := newAuthenticator(authConfig(t, jwks))
+	if err != nil {
+		t.Fatalf("newAuthenticator: %v", err)
+	}
+	s := NewBuildServer(logger.Discard())
+	s.lru.Add(hashOf(t, remoteRequest()), "/tmp/rr")
+	mux := http.NewServeMux()
+	mux.Handle(serviceV1connect.NewBuildServiceHandler(s, connect.WithInterceptors(a.Interceptor())))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	// The web UI speaks the Connect protocol with JSON payloads.
+	client := serviceV1connect.NewBuildServiceClient(srv.Client(), srv.URL, connect.WithProtoJSON())
+
+	run := func(token string) ([]string, *responseV1.BuildResponse, error) {
+		req := connect.NewRequest(remoteRequest())
+		if token != "" {
+			req.Header().Set("Authorization", "Bearer "+token)
+		}
+		stream, err := client.BuildStream(context.Background(), req)
+		if err != nil {
+			return nil, nil, err
+		}
+		var logs []string
+		var result *responseV1.BuildResponse
+		for stream.Receive() {
+			if line, ok := stream.Msg().GetEvent().(*responseV1.BuildEvent_Log); ok {
+				logs = append(logs, line.Log)
+			} else {
+				result = stream.Msg().GetResult()
+			}
+		}
+		return logs, result, stream.Err()
+	}
+
+	if _, _, err := run(""); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("streaming without credentials: got %v, want Unauthenticated", err)
+	}
+	logs, result, err := run("s3cret")
+	if err != nil {
+		t.Fatalf("BuildStream: %v", err)
+	}
+	if !slices.ContainsFunc(logs, func(l string) bool { return strings.Contains(l, `msg="cache hit"`) }) {
+		t.Fatalf("streamed log %q lacks the cache hit", logs)
+	}
+	if result.GetPath() != "/tmp/rr" || result.GetBuildId() == "" {
+		t.Fatalf("result = %v, want the cached path and a build id", result)
+	}
+	if rec, err := s.history.Get(result.GetBuildId()); err != nil || rec.GetPrincipal() != "token:ci" {
+		t.Fatalf("history entry of the streamed build: %v, %v", rec, err)
+	}
+}
+
+func TestDownloadHandler(t *testing.T) {
+	jwks, _ := signJWT(t)
+	a, err := newAuthenticator(authConfig(t, jwks))
+	if err != nil {
+		t.Fatalf("newAuthenticator: %v", err)
+	}
+	s := NewBuildServer(logger.Discard())
+	bin := filepath.Join(t.TempDir(), "rr")
+	if err := os.WriteFile(bin, []byte("binary"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []*responseV1.BuildRecord{
+		{Id: "ci-build", Principal: "token:ci", Request: remoteRequest(), Artifact: &responseV1.Artifact{Path: bin}},
+		{Id: "dev-build", Principal: "token:dev", Request: remoteRequest(), Artifact: &responseV1.Artifact{Path: bin}},
+		{Id: "failed", Principal: "token:ci", Request: remoteRequest()},
+	} {
+		if err := s.history.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /download/{id}", s.downloadHandler(a))
+	get := func(id, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/download/"+id, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	resp := get("ci-build", "s3cret")
+	if resp.Code != http.StatusOK || resp.Body.String() != "binary" {
+		t.Fatalf("own build: %d %q", resp.Code, resp.Body)
+	}
+	if got := resp.Header().Get("Content-Disposition"); got != "attachment; filename=rr-v2025.1.0-linux-amd64" {
+		t.Fatalf("Content-Disposition = %q", got)
+	}
+	for _, tc := range []struct {
+		id, token string
+		want      int
+	}{
+		{"ci-build", "", http.StatusUnauthorized},
+		{"dev-build", "s3cret", http.StatusNotFound},
+		{"failed", "s3cret", http.StatusNotFound},
+		{"missing", "s3cret", http.StatusNotFound},
+	} {
+		if got := get(tc.id, tc.token).Code; got != tc.want {
+			t.Fatalf("GET /download/%s with token %q: %d, want %d", tc.id, tc.token, got, tc.want)
+		}
+	}
+	a.auth.AnonymousPolicy = "ci"
+	if got := get("ci-build", "").Code; got != http.StatusUnauthorized {
+		t.Fatalf("anonymous download: %d, want %d", got, http.StatusUnauthorized)
+	}
+	if err := os.Remove(bin); err != nil {
+		t.Fatal(err)
+	}
+	if got := get("ci-build", "s3cret").Code; got != http.StatusGone {
+		t.Fatalf("evicted binary: %d, want %d", got, http.StatusGone)
+	}
+}
+
+func TestUIHandler(t *testing.T) {
+	srv := httptest.NewServer(http.StripPrefix("/ui", UIHandler()))
+	defer srv.Close()
+	for path, want := range map[string]string{"/ui/": "text/html", "/ui/app.js": "text/javascript", "/ui/style.css": "text/css"} {
+		resp, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), want) {
+			t.Fatalf("GET %s: %d %s, want %s", path, resp.StatusCode, resp.Header.Get("Content-Type"), want)
+		}
+		if resp.Header.Get("Content-Security-Policy") == "" {
+			t.Fatalf("GET %s lacks a Content-Security-Policy", path)
+		}
+	}
+}
+
+func TestDefaultPlugins(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	// GitHub archives list their root directory first.
+	for _, f := range []struct{ name, body string }{
+		{"roadrunner-2025.1.2/", ""},
+		{"roadrunner-2025.1.2/go.mod", `module github.com/roadrunner-server/roadrunner/v2025
+
+require (
+	github.com/roadrunner-server/http/v5 v5.2.0
+	github.com/roadrunner-server/informer/v5 v5.1.0
+	github.com/roadrunner-server/logger/v5 v5.1.0
+)
+`},
+		{"roadrunner-2025.1.2/container/", ""},
+		{"roadrunner-2025.1.2/container/plugins.go", `package container
+
+import (
+	"github.com/roadrunner-server/logger/v5"
+	"github.com/roadrunner-server/http/v5"
+	"github.com/roadrunner-server/informer/v5"
+)
+`},
+	} {
+		h := &zip.FileHeader{Name: f.name}
+		h.SetMode(0o644)
+		w, err := zw.CreateHeader(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.WriteString(w, f.body)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ref defaults to the [roadrunner] ref of the server's config.
+	cfg := &velox.Config{Roadrunner: map[string]string{"ref": "v2025.1.2"}}
+	s := NewBuildServer(logger.Discard(), WithProfiles(cfg))
+	s.rrCache.Add("v2025.1.2", buf.Bytes())
+
+	resp, err := s.DefaultPlugins(context.Background(), connect.NewRequest(&requestV1.DefaultPluginsRequest{}))
+	if err != nil {
+		t.Fatalf("DefaultPlugins: %v", err)
+	}
+	got := make([]string, 0, len(resp.Msg.GetPlugins()))
+	for _, p := range resp.Msg.GetPlugins() {
+		got = append(got, p.GetModuleName()+"@"+p.GetTag())
+	}
+	want := []string{"github.com/roadrunner-server/http/v5@v5.2.0", "github.com/roadrunner-server/logger/v5@v5.1.0"}
+	if resp.Msg.GetRrVersion() != "v2025.1.2" || !slices.Equal(got, want) {
+		t.Fatalf("DefaultPlugins = %s %v, want v2025.1.2 %v (sorted, without the bundled informer)", resp.Msg.GetRrVersion(), got, want)
+	}
+}
+
+func TestDefaultPlugins_LimitsAndPolicy(t *testing.T) {
+	cfg := &velox.Config{Server: &velox.Server{Limits: &velox.Limits{RequestsPerMinute: 1, Burst: 1}}}
+	s := NewBuildServer(logger.Discard(), WithProfiles(cfg))
+	ctx := context.WithValue(context.Background(), principalKey{}, &Principal{
+		Name: "ci", Method: "token", PolicyName: "ci", Policy: &velox.Policy{RRVersions: []string{"v2025.*"}},
+	})
+	call := func(ref string) error {
+		_, err := s.DefaultPlugins(ctx, connect.NewRequest(&requestV1.DefaultPluginsRequest{RrVersion: ref}))
+		return err
+	}
+
+	if err := call("v2024.3.0"); connect.CodeOf(err) != connect.CodePermissionDenied {
+		t.Fatalf("got %v, want PermissionDenied for an rr_version outside the policy", err)
+	}
+	if err := call("v2025.1.2"); connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("got %v, want ResourceExhausted once the request rate is spent", err)
+	}
+
+	s = NewBuildServer(logger.Discard(), WithProfiles(&velox.Config{Roadrunner: map[string]string{"ref": "../../etc"}}))
+	if _, err := s.DefaultPlugins(context.Background(), connect.NewRequest(&requestV1.DefaultPluginsRequest{})); connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Fatalf("got %v, want InvalidArgument for a ref that is no rr_version", err)
+	}
+}
+
+func TestBuild_RejectsFileWritingGcflags(t *testing.T) {
+	req := remoteRequest()
+	req.RequestId = "0e3f7f6a-3b1c-4f2a-9d1e-1b2c3d4e5f60"
+	req.Flags = &requestV1.BuildFlags{Gcflags: []string{"-cpuprofile=/tmp/x"}}
+	if err := protovalidate.Validate(req); err == nil {
+		t.Fatal("the gcflags allowlist in the proto accepted -cpuprofile")
+	}
+	// A cached binary for the same request must not be served either.
+	s := NewBuildServer(logger.Discard())
+	s.lru.Add(hashOf(t, req), "/tmp/rr")
+	_, err := s.Build(context.Background(), connect.NewRequest(req))
+	if connect.CodeOf(err) != connect.CodeInvalidArgument || !strings.Contains(err.Error(), "-cpuprofile") {
+		t.Fatalf("got %v, want InvalidArgument naming the flag", err)
+	}
+
+	req.Flags.Gcflags = []string{"-N", "-l", "-d=checkptr"}
+	if err := protovalidate.Validate(req); err != nil {
+		t.Fatalf("allowed gcflags rejected: %v", err)
+	}
+}
+
+func TestBuild_RejectsPGOProfilePath(t *testing.T) {
+	req := remoteRequest()
+	req.Flags = &requestV1.BuildFlags{Pgo: "/etc/passwd"}
+	// build skips the proto validation the interceptor runs.
+	_, err := NewBuildServer(logger.Discard()).build(context.Background(), connect.NewRequest(req), logger.Discard())
+	if connect.CodeOf(err) != connect.CodeInvalidArgument || !strings.Contains(err.Error(), "/etc/passwd") {
+		t.Fatalf("got %v, want InvalidArgument for a server-side profile path", err)
+	}
+}
+
+func TestBuild_RejectsInvalidPGOProfileBeforeCache(t *testing.T) {
+	req := remoteRequest()
+	req.PgoProfile = []byte("not a pprof profile")
+	s := NewBuildServer(logger.Discard())
+	s.lru.Add(hashOf(t, req), "/tmp/rr")
+	if _, err := s.Build(context.Background(), connect.NewRequest(req)); connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Fatalf("got %v, want InvalidArgument instead of the cached binary", err)
+	}
+}
+
+func TestBuild_RejectsPGOProfileWithPGOOff(t *testing.T) {
+	req := remoteRequest()
+	req.PgoProfile = []byte("profile")
+	req.Flags = &requestV1.BuildFlags{Pgo: velox.PGOOff}
+	_, err := NewBuildServer(logger.Discard()).Build(context.Background(), connect.NewRequest(req))
+	if connect.CodeOf(err) != connect.CodeInvalidArgument || !strings.Contains(err.Error(), `flags.pgo = "off"`) {
+		t.Fatalf("got %v, want InvalidArgument for a profile that pgo = off would ignore", err)
+	}
+}
+
+func TestCallerKey_SeparatesAuthMethods(t *testing.T) {
+	key := func(method string) string {
+		return callerKey(context.WithValue(context.Background(), principalKey{}, &Principal{Name: "ci", Method: method}), connect.Peer{Addr: "10.0.0.1:1234"})
+	}
+	if key("jwt") == key("token") {
+		t.Fatalf("a JWT subject %q shares the rate and CPU buckets of the token principal of that name", "ci")
+	}
+	if got := key("anonymous"); got != "ip 10.0.0.1" {
+		t.Fatalf("anonymous caller key = %q, want its IP", got)
+	}
+}
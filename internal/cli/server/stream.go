@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"connectrpc.com/connect"
+
+	requestV1 "github.com/roadrunner-server/velox/v3/gen/go/api/request/v1"
+	responseV1 "github.com/roadrunner-server/velox/v3/gen/go/api/response/v1"
+)
+
+// BuildStream runs a Build and sends the caller its log, debug messages
+// included, as it is written; the server log still gets every message. The
+// last message is the result.
+func (b *BuildServer) BuildStream(ctx context.Context, req *connect.Request[requestV1.BuildRequest], stream *connect.ServerStream[responseV1.BuildEvent]) error {
+	logs := slog.NewTextHandler(&logStream{stream: stream}, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{} // the client sees the lines as they come
+			}
+			return a
+		},
+	})
+	resp, err := b.build(ctx, req, slog.New(slog.NewMultiHandler(b.log.Handler(), logs)))
+	if err != nil {
+		return err
+	}
+	return stream.Send(&responseV1.BuildEvent{Event: &responseV1.BuildEvent_Result{Result: resp}})
+}
+
+// logStream sends each line written to it as a BuildEvent log.
+type logStream struct {
+	mu     sync.Mutex
+	stream *connect.ServerStream[responseV1.BuildEvent]
+}
+
+func (l *logStream) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	line := strings.TrimSuffix(string(p), "\n")
+	if err := l.stream.Send(&responseV1.BuildEvent{Event: &responseV1.BuildEvent_Log{Log: line}}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
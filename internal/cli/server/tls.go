@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/roadrunner-server/velox/v3"
+)
+
+// reloadDebounce coalesces the burst of events a single certificate
+// rotation produces (write, chmod, or a Kubernetes secret's symlink swap).
+const reloadDebounce = 500 * time.Millisecond
+
+// certReloader serves the current certificate and client CA pool and
+// replaces them when the files change. A failed reload keeps the previous
+// pair, so a half-written rotation never takes the server down.
+type certReloader struct {
+	cfg *velox.ServerTLS
+	log *slog.Logger
+
+	cert      atomic.Pointer[tls.Certificate]
+	clientCAs atomic.Pointer[x509.CertPool]
+}
+
+// newCertReloader loads the configured files once; errors here are fatal.
+func newCertReloader(cfg *velox.ServerTLS, log *slog.Logger) (*certReloader, error) {
+	r := &certReloader{cfg: cfg, log: log}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload reads the certificate, key and client CA files and swaps them in
+// only when all of them parse.
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("server.tls: %w", err)
+	}
+	var pool *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("server.tls: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("server.tls: %s holds no PEM certificates", r.cfg.ClientCAFile)
+		}
+	}
+	r.cert.Store(&cert)
+	r.clientCAs.Store(pool)
+	return nil
+}
+
+// TLSConfig returns the server's tls.Config. Every handshake picks up the
+// certificate and client CAs loaded last.
+func (r *certReloader) TLSConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Set explicitly: configs returned by GetConfigForClient don't get
+		// the ALPN protocols http.Server adds to its own copy.
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.cert.Load(), nil
+		},
+	}
+	if r.cfg.MinVersion == "1.3" {
+		base.MinVersion = tls.VersionTLS13
+	}
+	if r.cfg.ClientCAFile == "" {
+		return base
+	}
+	clientAuth := tls.VerifyClientCertIfGiven
+	if r.cfg.ClientAuth == velox.ClientAuthRequire {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.GetConfigForClient = nil
+		c.ClientAuth = clientAuth
+		c.ClientCAs = r.clientCAs.Load()
+		return c, nil
+	}
+	return base
+}
+
+// watch reloads the files when they change and on SIGHUP until ctx is
+// done. The parent directories are watched rather than the files, so
+// rotations that replace a file (rename, symlink swap) are seen too.
+func (r *certReloader) watch(ctx context.Context) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	files := map[string]bool{}
+	for _, f := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if f == "" {
+			continue
+		}
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			_ = w.Close()
+			return err
+		}
+		files[abs] = true
+		if err := w.Add(filepath.Dir(abs)); err != nil {
+			_ = w.Close()
+			return fmt.Errorf("watching %s: %w", filepath.Dir(abs), err)
+		}
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer func() { _ = w.Close() }()
+		defer signal.Stop(hup)
+
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				r.reloadAndLog("SIGHUP")
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				// Kubernetes swaps a ..data symlink, so any change in a
+				// watched directory may concern our files.
+				if files[filepath.Clean(ev.Name)] || filepath.Base(ev.Name) == "..data" {
+					debounce = time.After(reloadDebounce)
+				}
+			case <-debounce:
+				debounce = nil
+				r.reloadAndLog("file change")
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				r.log.Error("watching TLS files", "error", err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *certReloader) reloadAndLog(reason string) {
+	if err := r.reload(); err != nil {
+		r.log.Error("TLS reload failed; keeping the previous certificate", "reason", reason, "error", err)
+		return
+	}
+	leaf := r.cert.Load().Leaf
+	if leaf == nil {
+		r.log.Info("TLS certificate reloaded", "reason", reason)
+		return
+	}
+	r.log.Info("TLS certificate reloaded", "reason", reason,
+		"subject", leaf.Subject.String(), "not_after", leaf.NotAfter.Format(time.RFC3339))
+}
+
+// errClientCertsWithoutCA rejects [server.auth.client_certs] that could
+// never match because the server does not verify client certificates.
+var errClientCertsWithoutCA = errors.New("server.auth.client_certs requires a client CA: set server.tls.client_ca_file or --tls-client-ca")
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// uiFiles is the web UI: a static page that composes a plugin set, follows
+// BuildStream and lists the history, all over the Connect JSON protocol.
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// UIHandler serves the embedded web UI. Mount it under /ui/ with the prefix
+// stripped; the page calls the BuildService and /download/{id} at the root.
+func UIHandler() http.Handler {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err) // the embedded tree is fixed at compile time
+	}
+	fileServer := http.FileServerFS(files)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,294 @@
+'use strict';
+
+// The UI talks to the BuildService over the Connect protocol with JSON
+// payloads: unary calls are plain POSTs, BuildStream uses enveloped
+// messages (a flags byte and a big-endian length before each JSON message).
+const service = '/api.service.v1.BuildService/';
+const endStreamFlag = 0x02;
+
+const $ = (id) => document.getElementById(id);
+
+class ConnectError extends Error {
+  constructor(code, message) {
+    super(message);
+    this.code = code;
+  }
+}
+
+function token() {
+  return sessionStorage.getItem('velox-token') || '';
+}
+
+function headers(contentType) {
+  const h = {'Content-Type': contentType, 'Connect-Protocol-Version': '1'};
+  if (token()) {
+    h.Authorization = 'Bearer ' + token();
+  }
+  return h;
+}
+
+async function errorOf(resp) {
+  const body = await resp.json().catch(() => ({}));
+  return new ConnectError(body.code || 'unknown', body.message || resp.statusText);
+}
+
+async function call(method, req) {
+  const resp = await fetch(service + method, {
+    method: 'POST',
+    headers: headers('application/json'),
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) {
+    throw await errorOf(resp);
+  }
+  return resp.json();
+}
+
+// stream calls a server-streaming method and hands each message to
+// onMessage. It resolves when the server ends the stream without an error.
+async function stream(method, req, onMessage) {
+  const payload = new TextEncoder().encode(JSON.stringify(req));
+  const body = new Uint8Array(5 + payload.length);
+  new DataView(body.buffer).setUint32(1, payload.length);
+  body.set(payload, 5);
+
+  const resp = await fetch(service + method, {
+    method: 'POST',
+    headers: headers('application/connect+json'),
+    body,
+  });
+  if (!resp.ok) {
+    throw await errorOf(resp);
+  }
+
+  const reader = resp.body.getReader();
+  const decoder = new TextDecoder();
+  let buf = new Uint8Array(0);
+  for (;;) {
+    while (buf.length >= 5) {
+      const size = new DataView(buf.buffer, buf.byteOffset).getUint32(1);
+      if (buf.length < 5 + size) {
+        break;
+      }
+      const flags = buf[0];
+      const msg = JSON.parse(decoder.decode(buf.subarray(5, 5 + size)));
+      buf = buf.subarray(5 + size);
+      if (flags & endStreamFlag) {
+        if (msg.error) {
+          throw new ConnectError(msg.error.code, msg.error.message || msg.error.code);
+        }
+        return;
+      }
+      onMessage(msg);
+    }
+    const {done, value} = await reader.read();
+    if (done) {
+      throw new ConnectError('unavailable', 'the stream ended unexpectedly');
+    }
+    const next = new Uint8Array(buf.length + value.length);
+    next.set(buf);
+    next.set(value, buf.length);
+    buf = next;
+  }
+}
+
+function showStatus(text, isError) {
+  const status = $('status');
+  status.replaceChildren(text);
+  status.classList.toggle('error', Boolean(isError));
+}
+
+function showError(err) {
+  const hint = err.code === 'unauthenticated' ? ' Enter an API token above.' : '';
+  showStatus(`${err.message}${hint}`, true);
+}
+
+// downloadLink links the binary of a build. With a token the binary is
+// fetched with it, since a plain link cannot carry the Authorization header.
+function downloadLink(id) {
+  const a = document.createElement('a');
+  a.href = '/download/' + encodeURIComponent(id);
+  a.textContent = 'Download';
+  a.addEventListener('click', async (event) => {
+    if (!token()) {
+      return;
+    }
+    event.preventDefault();
+    try {
+      const resp = await fetch(a.href, {headers: {Authorization: 'Bearer ' + token()}});
+      if (!resp.ok) {
+        throw new ConnectError(String(resp.status), (await resp.text()).trim());
+      }
+      const disposition = resp.headers.get('Content-Disposition') || '';
+      const name = /filename="?([^";]+)"?/.exec(disposition)?.[1] || 'rr';
+      const url = URL.createObjectURL(await resp.blob());
+      const save = document.createElement('a');
+      save.href = url;
+      save.download = name;
+      save.click();
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      showError(err);
+    }
+  });
+  return a;
+}
+
+// uuid returns a random (v4) UUID for request_id. crypto.randomUUID is
+// missing outside secure contexts, i.e. on a plaintext server.
+function uuid() {
+  if (crypto.randomUUID) {
+    return crypto.randomUUID();
+  }
+  const b = crypto.getRandomValues(new Uint8Array(16));
+  b[6] = (b[6] & 0x0f) | 0x40;
+  b[8] = (b[8] & 0x3f) | 0x80;
+  const hex = Array.from(b, (x) => x.toString(16).padStart(2, '0')).join('');
+  return `${hex.slice(0, 8)}-${hex.slice(8, 12)}-${hex.slice(12, 16)}-${hex.slice(16, 20)}-${hex.slice(20)}`;
+}
+
+function addPlugin(moduleName = '', tag = '') {
+  const row = $('plugins').tBodies[0].insertRow();
+  for (const [value, placeholder] of [[moduleName, 'github.com/roadrunner-server/http/v5'], [tag, 'v5.0.0']]) {
+    const input = document.createElement('input');
+    input.value = value;
+    input.placeholder = placeholder;
+    row.insertCell().append(input);
+  }
+  const remove = document.createElement('button');
+  remove.type = 'button';
+  remove.textContent = 'Remove';
+  remove.addEventListener('click', () => row.remove());
+  row.insertCell().append(remove);
+}
+
+function plugins() {
+  return Array.from($('plugins').tBodies[0].rows, (row) => {
+    const [moduleName, tag] = Array.from(row.querySelectorAll('input'), (input) => input.value.trim());
+    return {moduleName, tag};
+  }).filter((p) => p.moduleName !== '');
+}
+
+async function loadInfo() {
+  const info = await call('Info', {});
+  $('server-info').textContent = [`velox ${info.veloxVersion}`, info.goVersion].filter(Boolean).join(' · ');
+  const select = $('platform');
+  select.replaceChildren();
+  for (const p of info.platforms || []) {
+    const name = `${p.os}/${p.arch}`;
+    select.add(new Option(name, name, false, name === 'linux/amd64'));
+  }
+}
+
+async function loadDefaults() {
+  showStatus('Loading the default plugins…');
+  const defaults = await call('DefaultPlugins', {rrVersion: $('rr-version').value.trim()});
+  $('rr-version').value = defaults.rrVersion;
+  $('plugins').tBodies[0].replaceChildren();
+  for (const p of defaults.plugins || []) {
+    addPlugin(p.moduleName, p.tag);
+  }
+  showStatus(`${(defaults.plugins || []).length} plugins bundled with RoadRunner ${defaults.rrVersion}.`);
+}
+
+let nextPageToken = '';
+
+async function loadHistory(more) {
+  const page = await call('ListBuilds', {pageSize: 20, pageToken: more ? nextPageToken : ''});
+  const body = $('history').tBodies[0];
+  if (!more) {
+    body.replaceChildren();
+  }
+  for (const b of page.builds || []) {
+    const req = b.request || {};
+    const platform = req.targetPlatform ? `${req.targetPlatform.os}/${req.targetPlatform.arch}` : '';
+    const row = body.insertRow();
+    for (const text of [
+      new Date(b.startedAt).toLocaleString(),
+      b.principal || '',
+      req.rrVersion || req.profile || '',
+      platform,
+      String((req.plugins || []).length),
+    ]) {
+      row.insertCell().textContent = text;
+    }
+    const outcome = row.insertCell();
+    outcome.textContent = b.outcome;
+    outcome.className = 'outcome-' + b.outcome;
+    outcome.title = b.error || '';
+    row.insertCell().textContent = b.duration ? `${parseFloat(b.duration).toFixed(1)}s` : '';
+    const link = row.insertCell();
+    if (b.artifact?.path) {
+      link.append(downloadLink(b.id));
+    }
+  }
+  nextPageToken = page.nextPageToken || '';
+  $('older').hidden = nextPageToken === '';
+}
+
+async function build(event) {
+  event.preventDefault();
+  const [os, arch] = $('platform').value.split('/');
+  const req = {
+    requestId: uuid(),
+    rrVersion: $('rr-version').value.trim(),
+    targetPlatform: os ? {os, arch} : undefined,
+    plugins: plugins(),
+    forceRebuild: $('force-rebuild').checked,
+  };
+
+  const log = $('log');
+  log.hidden = false;
+  log.textContent = '';
+  $('build').disabled = true;
+  showStatus('Building…');
+  let result;
+  try {
+    await stream('BuildStream', req, (msg) => {
+      if (msg.log !== undefined) {
+        const atBottom = log.scrollTop + log.clientHeight >= log.scrollHeight - 4;
+        log.append(msg.log + '\n');
+        if (atBottom) {
+          log.scrollTop = log.scrollHeight;
+        }
+      } else if (msg.result) {
+        result = msg.result;
+      }
+    });
+    showStatus(result?.logs ? `Done (${result.logs}). ` : 'Done. ');
+    if (result?.buildId) {
+      $('status').append(downloadLink(result.buildId));
+    }
+  } catch (err) {
+    showError(err);
+  } finally {
+    $('build').disabled = false;
+    loadHistory(false).catch(showError);
+  }
+}
+
+function init() {
+  $('token').value = token();
+  $('token').addEventListener('change', () => {
+    sessionStorage.setItem('velox-token', $('token').value.trim());
+    start();
+  });
+  $('load-defaults').addEventListener('click', () => loadDefaults().catch(showError));
+  $('add-plugin').addEventListener('click', () => addPlugin());
+  $('build-form').addEventListener('submit', build);
+  $('refresh-history').addEventListener('click', () => loadHistory(false).catch(showError));
+  $('older').addEventListener('click', () => loadHistory(true).catch(showError));
+  start();
+}
+
+function start() {
+  const loads = [loadInfo(), loadHistory(false)];
+  // DefaultPlugins downloads from GitHub and counts against the request
+  // rate; a token change keeps the plugins already listed.
+  if ($('plugins').tBodies[0].rows.length === 0) {
+    loads.push(loadDefaults());
+  }
+  Promise.all(loads).catch(showError);
+}
+
+init();
@@ -0,0 +1,148 @@
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/roadrunner-server/velox/v3"
+	"github.com/roadrunner-server/velox/v3/builder"
+	"github.com/roadrunner-server/velox/v3/github"
+	"github.com/roadrunner-server/velox/v3/plugin"
+)
+
+const refKey = "ref"
+
+// BindCommand returns the cobra.Command for `vx validate`. It reads the file
+// named by --config itself (the root command's loader stops at the first
+// error) and exits non-zero when any error-level diagnostic is found.
+//
+// With --resolve, a config without errors is additionally run through the
+// tidy stage against its RR ref so replaces targeting modules outside the
+// resolved graph can be flagged.
+func BindCommand(configPath *string, rootLog *slog.Logger) *cobra.Command {
+	var (
+		asJSON  bool
+		resolve bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check velox.toml and report every problem with its line number",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			log := rootLog.With("component", "validate")
+			path := *configPath
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			ds, err := velox.CheckConfigData(data, path)
+			if err != nil {
+				return err
+			}
+
+			if resolve && !ds.HasErrors() {
+				extra, err := checkGraph(cmd.Context(), log, path)
+				if err != nil {
+					return err
+				}
+				ds = velox.LocateDiagnostics(append(ds, extra...), data, path)
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(ds); err != nil {
+					return err
+				}
+			} else if err := writeDiagnostics(cmd.OutOrStdout(), path, ds); err != nil {
+				return err
+			}
+
+			if ds.HasErrors() {
+				return fmt.Errorf("%s: validation failed", path)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&asJSON, "json", false, "Print diagnostics as JSON")
+	flags.BoolVar(&resolve, "resolve", false,
+		"Download the RR template and run go mod tidy to check replaces against the module graph")
+	return cmd
+}
+
+// checkGraph resolves the config's module graph and checks its replaces.
+func checkGraph(ctx context.Context, log *slog.Logger, path string) (velox.Diagnostics, error) {
+	cfg, err := velox.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Replaces) == 0 {
+		return nil, nil
+	}
+
+	plugins := make([]*plugin.Plugin, 0, len(cfg.Plugins))
+	for _, p := range cfg.Plugins {
+		plugins = append(plugins, plugin.NewPlugin(p.ModuleName, p.Tag))
+	}
+	token := ""
+	if cfg.GitHub.Token != nil {
+		token = cfg.GitHub.Token.Token
+	}
+
+	dlDir, err := os.MkdirTemp("", "velox-validate-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dlDir) }()
+
+	gh := github.NewClient(cfg.GitHub.BaseURL, token, github.NewLRUCache(0), log.With("component", "github"))
+	rrPath, err := gh.DownloadTemplate(ctx, dlDir, "", cfg.Roadrunner[refKey])
+	if err != nil {
+		return nil, fmt.Errorf("downloading template: %w", err)
+	}
+	graph, err := builder.NewBuilder(rrPath,
+		builder.WithLogger(log.With("component", "build")),
+		builder.WithPlugins(plugins...),
+		builder.WithReplaces(cfg.Replaces),
+		builder.WithExcludes(cfg.Excludes),
+		builder.WithGOOS(cfg.TargetPlatform.OS),
+		builder.WithGOARCH(cfg.TargetPlatform.Arch),
+		builder.WithGoVersion(cfg.GoVersion),
+		builder.WithGoToolchainDir(cfg.GoToolchainDir),
+	).Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(graph))
+	for _, m := range graph {
+		paths = append(paths, m.Path)
+	}
+	return cfg.CheckReplacesInGraph(paths), nil
+}
+
+func writeDiagnostics(w io.Writer, path string, ds velox.Diagnostics) error {
+	if len(ds) == 0 {
+		_, err := fmt.Fprintf(w, "%s: ok\n", path)
+		return err
+	}
+	for _, d := range ds {
+		loc := path
+		if d.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", path, d.Line)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s: %s\n", loc, d.Severity, d.Key, d.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+// Package validate implements the `vx validate` subcommand, which reports
+// every problem in a velox config with its source line.
+package validate
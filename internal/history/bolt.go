@@ -0,0 +1,127 @@
+package history
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+
+	responseV1 "github.com/roadrunner-server/velox/v3/gen/go/api/response/v1"
+)
+
+var buildsBucket = []byte("builds")
+
+// Bolt is a Store backed by a bbolt database file. Builds are keyed by id
+// and stored as marshaled BuildRecords.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens (creating it if needed) the database at path. A file
+// locked by another process fails after a second instead of blocking.
+func OpenBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening build history %s: %w", path, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(buildsBucket)
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("opening build history %s: %w", path, err)
+	}
+	return &Bolt{db: db}, nil
+}
+
+func (b *Bolt) Add(r *responseV1.BuildRecord) error {
+	data, err := proto.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(buildsBucket).Put([]byte(r.GetId()), data)
+	})
+}
+
+func (b *Bolt) Get(id string) (*responseV1.BuildRecord, error) {
+	var r *responseV1.BuildRecord
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(buildsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		var err error
+		r, err = unmarshal(data)
+		return err
+	})
+	return r, err
+}
+
+func (b *Bolt) List(q Query) ([]*responseV1.BuildRecord, string, error) {
+	var (
+		out  []*responseV1.BuildRecord
+		next string
+	)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var err error
+		out, next, err = page(q, func(yield func(*responseV1.BuildRecord, error) bool) {
+			c := tx.Bucket(buildsBucket).Cursor()
+			k, v := c.Last()
+			if q.PageToken != "" {
+				// Seek lands on the token or the first key after it; both
+				// were on an earlier page or don't exist.
+				k, v = c.Seek([]byte(q.PageToken))
+				if k == nil {
+					k, v = c.Last()
+				}
+				for k != nil && bytes.Compare(k, []byte(q.PageToken)) >= 0 {
+					k, v = c.Prev()
+				}
+			}
+			for ; k != nil; k, v = c.Prev() {
+				if !yield(unmarshal(v)) {
+					return
+				}
+			}
+		})
+		return err
+	})
+	return out, next, err
+}
+
+func (b *Bolt) Prune(r Retention, now time.Time) (int, error) {
+	deleted := 0
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(buildsBucket).Cursor()
+		kept := 0
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			rec, err := unmarshal(v)
+			if err != nil {
+				return err
+			}
+			if r.expired(rec, now) || (r.MaxRecords > 0 && kept >= r.MaxRecords) {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+				deleted++
+				continue
+			}
+			kept++
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+func (b *Bolt) Close() error { return b.db.Close() }
+
+func unmarshal(data []byte) (*responseV1.BuildRecord, error) {
+	r := &responseV1.BuildRecord{}
+	if err := proto.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("decoding build record: %w", err)
+	}
+	return r, nil
+}
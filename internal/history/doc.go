@@ -0,0 +1,4 @@
+// Package history keeps the build history of the build server: one
+// BuildRecord per Build request, in memory or in a bbolt database file, with
+// filtering, pagination and a retention policy.
+package history
@@ -0,0 +1,101 @@
+package history
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"time"
+
+	responseV1 "github.com/roadrunner-server/velox/v3/gen/go/api/response/v1"
+)
+
+// DefaultPageSize is the page size of a Query that sets none.
+const DefaultPageSize = 50
+
+// ErrNotFound is returned by Get for an unknown id.
+var ErrNotFound = errors.New("build not found")
+
+// Store is where build records are kept. Implementations are safe for
+// concurrent use.
+type Store interface {
+	// Add records a build. Its id must be set (see NewID).
+	Add(r *responseV1.BuildRecord) error
+	// Get returns the build with id, or ErrNotFound.
+	Get(id string) (*responseV1.BuildRecord, error)
+	// List returns a page of the builds matching q, newest first, and the
+	// token of the next page, "" after the last one.
+	List(q Query) ([]*responseV1.BuildRecord, string, error)
+	// Prune deletes the builds r no longer retains as of now and returns
+	// how many it deleted.
+	Prune(r Retention, now time.Time) (int, error)
+	Close() error
+}
+
+// Query selects builds. Zero fields match every build.
+type Query struct {
+	PageSize  int
+	PageToken string
+
+	Principal string
+	Outcome   string
+	RRVersion string
+	Hash      string
+	// Since and Until bound the start time, both inclusive.
+	Since, Until time.Time
+}
+
+// Matches reports whether r passes the filters of q.
+func (q Query) Matches(r *responseV1.BuildRecord) bool {
+	started := r.GetStartedAt().AsTime()
+	switch {
+	case q.Principal != "" && r.GetPrincipal() != q.Principal,
+		q.Outcome != "" && r.GetOutcome() != q.Outcome,
+		q.RRVersion != "" && r.GetRequest().GetRrVersion() != q.RRVersion,
+		q.Hash != "" && r.GetHash() != q.Hash,
+		!q.Since.IsZero() && started.Before(q.Since),
+		!q.Until.IsZero() && started.After(q.Until):
+		return false
+	}
+	return true
+}
+
+// Retention bounds the history. Zero fields retain everything.
+type Retention struct {
+	// MaxAge drops builds that started longer ago.
+	MaxAge time.Duration
+	// MaxRecords keeps only the newest builds.
+	MaxRecords int
+}
+
+// NewID returns a build id for a build started at t. Ids sort by start
+// time, which is the order List pages through.
+func NewID(t time.Time) string {
+	return fmt.Sprintf("%016x%08x", uint64(t.UnixNano()), rand.Uint32()) //nolint:gosec // uniqueness, not secrecy
+}
+
+// page applies q to records walked newest first, starting after
+// q.PageToken.
+func page(q Query, records iter.Seq2[*responseV1.BuildRecord, error]) ([]*responseV1.BuildRecord, string, error) {
+	size := cmp.Or(q.PageSize, DefaultPageSize)
+	var out []*responseV1.BuildRecord
+	for r, err := range records {
+		if err != nil {
+			return nil, "", err
+		}
+		if !q.Matches(r) {
+			continue
+		}
+		if len(out) == size {
+			return out, out[size-1].GetId(), nil
+		}
+		out = append(out, r)
+	}
+	return out, "", nil
+}
+
+// expired reports whether r is older than the retention's MaxAge.
+func (r Retention) expired(rec *responseV1.BuildRecord, now time.Time) bool {
+	return r.MaxAge > 0 && rec.GetStartedAt().AsTime().Before(now.Add(-r.MaxAge))
+}
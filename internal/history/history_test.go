@@ -0,0 +1,130 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	requestV1 "github.com/roadrunner-server/velox/v3/gen/go/api/request/v1"
+	responseV1 "github.com/roadrunner-server/velox/v3/gen/go/api/response/v1"
+)
+
+var epoch = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// seed adds n builds started a minute apart; every third one is by "ci"
+// and failed.
+func seed(t *testing.T, s Store, n int) []string {
+	t.Helper()
+	ids := make([]string, n)
+	for i := range n {
+		started := epoch.Add(time.Duration(i) * time.Minute)
+		r := &responseV1.BuildRecord{
+			Id:        NewID(started),
+			Request:   &requestV1.BuildRequest{RrVersion: "v2025.1.0"},
+			Principal: "dev",
+			Outcome:   "success",
+			StartedAt: timestamppb.New(started),
+		}
+		if i%3 == 0 {
+			r.Principal, r.Outcome = "ci", "internal"
+		}
+		require.NoError(t, s.Add(r))
+		ids[i] = r.GetId()
+	}
+	return ids
+}
+
+func stores(t *testing.T) map[string]func() Store {
+	return map[string]func() Store{
+		"memory": func() Store { return NewMemory() },
+		"bolt": func() Store {
+			s, err := OpenBolt(filepath.Join(t.TempDir(), "history.db"))
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = s.Close() })
+			return s
+		},
+	}
+}
+
+func TestStore_GetAndList(t *testing.T) {
+	for name, open := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := open()
+			ids := seed(t, s, 10)
+
+			got, err := s.Get(ids[4])
+			require.NoError(t, err)
+			assert.Equal(t, "v2025.1.0", got.GetRequest().GetRrVersion())
+			_, err = s.Get("nope")
+			require.ErrorIs(t, err, ErrNotFound)
+
+			// Pages of 4, newest first, until the token runs out.
+			var seen []string
+			token := ""
+			for range 3 {
+				page, next, err := s.List(Query{PageSize: 4, PageToken: token})
+				require.NoError(t, err)
+				for _, r := range page {
+					seen = append(seen, r.GetId())
+				}
+				token = next
+			}
+			assert.Empty(t, token)
+			require.Len(t, seen, 10)
+			for i, id := range seen {
+				assert.Equal(t, ids[9-i], id)
+			}
+
+			failed, next, err := s.List(Query{Principal: "ci", Outcome: "internal"})
+			require.NoError(t, err)
+			assert.Empty(t, next)
+			assert.Len(t, failed, 4)
+
+			window, _, err := s.List(Query{Since: epoch.Add(2 * time.Minute), Until: epoch.Add(5 * time.Minute)})
+			require.NoError(t, err)
+			require.Len(t, window, 4)
+			assert.Equal(t, ids[5], window[0].GetId())
+		})
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	for name, open := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			s := open()
+			ids := seed(t, s, 10)
+
+			n, err := s.Prune(Retention{MaxAge: 5 * time.Minute}, epoch.Add(9*time.Minute))
+			require.NoError(t, err)
+			assert.Equal(t, 4, n, "builds started before minute 4 are dropped")
+
+			n, err = s.Prune(Retention{MaxRecords: 3}, epoch.Add(9*time.Minute))
+			require.NoError(t, err)
+			assert.Equal(t, 3, n)
+
+			left, _, err := s.List(Query{})
+			require.NoError(t, err)
+			require.Len(t, left, 3)
+			assert.Equal(t, []string{ids[9], ids[8], ids[7]}, []string{left[0].GetId(), left[1].GetId(), left[2].GetId()})
+		})
+	}
+}
+
+func TestBolt_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	s, err := OpenBolt(path)
+	require.NoError(t, err)
+	ids := seed(t, s, 2)
+	require.NoError(t, s.Close())
+
+	s, err = OpenBolt(path)
+	require.NoError(t, err)
+	defer func() { _ = s.Close() }()
+	got, err := s.Get(ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, ids[1], got.GetId())
+}
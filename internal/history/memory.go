@@ -0,0 +1,81 @@
+package history
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	responseV1 "github.com/roadrunner-server/velox/v3/gen/go/api/response/v1"
+)
+
+// Memory is a Store that keeps builds in memory; they are lost on restart.
+type Memory struct {
+	mu sync.RWMutex
+	// records are sorted by id, oldest first.
+	records []*responseV1.BuildRecord
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory { return &Memory{} }
+
+func (m *Memory) Add(r *responseV1.BuildRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i, found := m.search(r.GetId())
+	r = proto.CloneOf(r)
+	if found {
+		m.records[i] = r
+		return nil
+	}
+	m.records = slices.Insert(m.records, i, r)
+	return nil
+}
+
+func (m *Memory) Get(id string) (*responseV1.BuildRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, found := m.search(id)
+	if !found {
+		return nil, ErrNotFound
+	}
+	return proto.CloneOf(m.records[i]), nil
+}
+
+func (m *Memory) List(q Query) ([]*responseV1.BuildRecord, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	end := len(m.records)
+	if q.PageToken != "" {
+		end, _ = m.search(q.PageToken)
+	}
+	return page(q, func(yield func(*responseV1.BuildRecord, error) bool) {
+		for i := end - 1; i >= 0; i-- {
+			if !yield(proto.CloneOf(m.records[i]), nil) {
+				return
+			}
+		}
+	})
+}
+
+func (m *Memory) Prune(r Retention, now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	before := len(m.records)
+	m.records = slices.DeleteFunc(m.records, func(rec *responseV1.BuildRecord) bool { return r.expired(rec, now) })
+	if r.MaxRecords > 0 && len(m.records) > r.MaxRecords {
+		m.records = slices.Delete(m.records, 0, len(m.records)-r.MaxRecords)
+	}
+	return before - len(m.records), nil
+}
+
+func (m *Memory) Close() error { return nil }
+
+// search finds id; m.mu must be held.
+func (m *Memory) search(id string) (int, bool) {
+	return slices.BinarySearchFunc(m.records, id, func(r *responseV1.BuildRecord, id string) int {
+		return strings.Compare(r.GetId(), id)
+	})
+}
@@ -0,0 +1,3 @@
+// Package rrbin reads the module graph and plugin set compiled into an
+// existing RoadRunner binary.
+package rrbin
@@ -0,0 +1,179 @@
+package rrbin
+
+import (
+	"debug/elf"
+	"debug/gosym"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// image is the executable-format-neutral view of a binary that rrbin needs:
+// the pclntab, the symbol table (absent in stripped binaries) and a way to
+// read memory at a virtual address. Only ELF and Mach-O are handled because
+// velox v3 does not build Windows targets.
+type image struct {
+	order    binary.ByteOrder
+	ptrSize  int
+	pclntab  []byte
+	textAddr uint64
+	// symbols maps symbol names to their virtual addresses.
+	symbols  map[string]uint64
+	sections []section
+	// closer releases the underlying file; section data is read lazily.
+	closer func() error
+}
+
+type section struct {
+	addr uint64
+	size uint64
+	data func() ([]byte, error)
+}
+
+// openImage opens the binary at path. Callers must Close the image.
+func openImage(path string) (*image, error) {
+	if f, err := elf.Open(path); err == nil {
+		img, err := elfImage(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		img.closer = f.Close
+		return img, nil
+	}
+	if f, err := macho.Open(path); err == nil {
+		img, err := machoImage(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		img.closer = f.Close
+		return img, nil
+	}
+	return nil, fmt.Errorf("%s is neither an ELF nor a Mach-O binary", path)
+}
+
+func (img *image) Close() error { return img.closer() }
+
+func elfImage(f *elf.File) (*image, error) {
+	pcln, text := f.Section(".gopclntab"), f.Section(".text")
+	if pcln == nil || text == nil {
+		return nil, errors.New("ELF binary has no .gopclntab section")
+	}
+	data, err := pcln.Data()
+	if err != nil {
+		return nil, err
+	}
+	img := &image{
+		order:    f.ByteOrder,
+		ptrSize:  4,
+		pclntab:  data,
+		textAddr: text.Addr,
+		symbols:  map[string]uint64{},
+	}
+	if f.Class == elf.ELFCLASS64 {
+		img.ptrSize = 8
+	}
+	// Stripped binaries return elf.ErrNoSymbols; that just leaves the map empty.
+	syms, _ := f.Symbols()
+	for _, s := range syms {
+		img.symbols[s.Name] = s.Value
+	}
+	for _, s := range f.Sections {
+		if s.Type == elf.SHT_NOBITS || s.Addr == 0 {
+			continue
+		}
+		img.sections = append(img.sections, section{addr: s.Addr, size: s.Size, data: s.Data})
+	}
+	return img, nil
+}
+
+func machoImage(f *macho.File) (*image, error) {
+	pcln, text := f.Section("__gopclntab"), f.Section("__text")
+	if pcln == nil || text == nil {
+		return nil, errors.New("Mach-O binary has no __gopclntab section")
+	}
+	data, err := pcln.Data()
+	if err != nil {
+		return nil, err
+	}
+	img := &image{
+		order:    f.ByteOrder,
+		ptrSize:  4,
+		pclntab:  data,
+		textAddr: text.Addr,
+		symbols:  map[string]uint64{},
+	}
+	if f.Magic == macho.Magic64 {
+		img.ptrSize = 8
+	}
+	if f.Symtab != nil {
+		for _, s := range f.Symtab.Syms {
+			img.symbols[s.Name] = s.Value
+		}
+	}
+	for _, s := range f.Sections {
+		if s.Addr == 0 || s.Flags&0xff == 0x1 { // S_ZEROFILL has no file data
+			continue
+		}
+		img.sections = append(img.sections, section{addr: s.Addr, size: s.Size, data: s.Data})
+	}
+	return img, nil
+}
+
+// funcNames returns the names of every function in the pclntab. The pclntab
+// survives `-s -w`, unlike the symbol table.
+func (img *image) funcNames() ([]string, error) {
+	table, err := gosym.NewTable(nil, gosym.NewLineTable(img.pclntab, img.textAddr))
+	if err != nil {
+		return nil, fmt.Errorf("parse pclntab: %w", err)
+	}
+	out := make([]string, 0, len(table.Funcs))
+	for _, fn := range table.Funcs {
+		out = append(out, fn.Name)
+	}
+	return out, nil
+}
+
+// read returns n bytes at virtual address addr.
+func (img *image) read(addr, n uint64) ([]byte, error) {
+	for _, s := range img.sections {
+		if addr < s.addr || addr+n > s.addr+s.size {
+			continue
+		}
+		data, err := s.data()
+		if err != nil {
+			return nil, err
+		}
+		off := addr - s.addr
+		if off+n > uint64(len(data)) {
+			break
+		}
+		return data[off : off+n], nil
+	}
+	return nil, fmt.Errorf("address %#x (+%d) is not backed by file data", addr, n)
+}
+
+// stringVar reads the Go string variable at addr: a {ptr, len} header
+// followed by an indirection to the bytes.
+func (img *image) stringVar(addr uint64) (string, error) {
+	hdr, err := img.read(addr, uint64(2*img.ptrSize)) //nolint:gosec // ptrSize is 4 or 8
+	if err != nil {
+		return "", err
+	}
+	var ptr, n uint64
+	if img.ptrSize == 8 {
+		ptr, n = img.order.Uint64(hdr), img.order.Uint64(hdr[8:])
+	} else {
+		ptr, n = uint64(img.order.Uint32(hdr)), uint64(img.order.Uint32(hdr[4:]))
+	}
+	if n == 0 {
+		return "", nil
+	}
+	b, err := img.read(ptr, n)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,182 @@
+package rrbin
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/roadrunner-server/velox/v3/builder/templates"
+)
+
+// Module is a single entry of the module graph recorded in a Go binary.
+type Module struct {
+	Path    string
+	Version string
+	// Replace is the replacement module when a go.mod replace directive was
+	// in effect at build time. Local-path replacements carry no Version.
+	Replace *Module
+}
+
+// Plugin is a RoadRunner plugin package found in a binary together with the
+// module that provided it.
+type Plugin struct {
+	// Package is the import path of the package declaring the Plugin type.
+	Package string
+	Module  Module
+}
+
+// Binary describes what a RoadRunner binary was built from.
+type Binary struct {
+	GoVersion string
+	Main      Module
+	Deps      []Module
+	// Settings holds the build settings (-tags, -trimpath, GOOS, GOARCH, ...)
+	// recorded by the Go toolchain.
+	Settings map[string]string
+	// MetaVersion / MetaBuildTime are the values velox injects into RR's
+	// internal/meta package via -X. They can only be recovered from binaries
+	// that still carry a symbol table (debug builds); Stripped reports
+	// whether that was the case.
+	MetaVersion   string
+	MetaBuildTime string
+	Stripped      bool
+	// pluginPackages is the sorted list of packages declaring a RoadRunner
+	// plugin type, taken from the binary's function table.
+	pluginPackages []string
+}
+
+// Read parses the build info, function table and (when present) symbol
+// table of the binary at path. The function table survives `-s -w`, so
+// stripped release binaries produced by velox are supported.
+func Read(path string) (*Binary, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read build info from %s: %w", path, err)
+	}
+
+	b := &Binary{
+		GoVersion: info.GoVersion,
+		Main:      Module{Path: info.Main.Path, Version: info.Main.Version},
+		Deps:      make([]Module, 0, len(info.Deps)),
+		Settings:  make(map[string]string, len(info.Settings)),
+	}
+	for _, d := range info.Deps {
+		m := Module{Path: d.Path, Version: d.Version}
+		if d.Replace != nil {
+			m.Replace = &Module{Path: d.Replace.Path, Version: d.Replace.Version}
+		}
+		b.Deps = append(b.Deps, m)
+	}
+	for _, s := range info.Settings {
+		b.Settings[s.Key] = s.Value
+	}
+
+	img, err := openImage(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = img.Close() }()
+	funcs, err := img.funcNames()
+	if err != nil {
+		return nil, fmt.Errorf("read function table from %s: %w", path, err)
+	}
+	b.pluginPackages = pluginPackages(funcs)
+
+	b.Stripped = len(img.symbols) == 0
+	if addr, ok := metaSymbol(img.symbols, "version"); ok {
+		b.MetaVersion, _ = img.stringVar(addr)
+	}
+	if addr, ok := metaSymbol(img.symbols, "buildTime"); ok {
+		b.MetaBuildTime, _ = img.stringVar(addr)
+	}
+	return b, nil
+}
+
+// metaSymbol finds RR's internal/meta.<name> variable regardless of the RR
+// major version in the module path (v2025, v3, ...).
+func metaSymbol(symbols map[string]uint64, name string) (uint64, bool) {
+	const rrModulePrefix = "github.com/roadrunner-server/roadrunner/"
+	suffix := "/internal/meta." + name
+	for sym, addr := range symbols {
+		if strings.HasPrefix(sym, rrModulePrefix) && strings.HasSuffix(sym, suffix) {
+			return addr, true
+		}
+	}
+	return 0, false
+}
+
+// Setting returns the build setting key, or "" when it was not recorded.
+func (b *Binary) Setting(key string) string { return b.Settings[key] }
+
+// Plugins returns the user plugins compiled into the binary, sorted by
+// package path. The informer/resetter plugins bundled by the velox template
+// and any plugin living inside the main RoadRunner module are skipped.
+func (b *Binary) Plugins() []Plugin {
+	out := make([]Plugin, 0, len(b.pluginPackages))
+	for _, pkg := range b.pluginPackages {
+		mod, ok := b.ModuleFor(pkg)
+		if !ok || templates.IsBundledModule(mod.Path) {
+			continue
+		}
+		out = append(out, Plugin{Package: pkg, Module: mod})
+	}
+	return out
+}
+
+// ModuleFor returns the dependency providing pkg: the module with the longest
+// path that is pkg itself or a prefix of it at a path-element boundary.
+func (b *Binary) ModuleFor(pkg string) (Module, bool) {
+	var (
+		best  Module
+		found bool
+	)
+	for _, m := range b.Deps {
+		if pkg != m.Path && !strings.HasPrefix(pkg, m.Path+"/") {
+			continue
+		}
+		if !found || len(m.Path) > len(best.Path) {
+			best, found = m, true
+		}
+	}
+	return best, found
+}
+
+// Replaces returns the dependencies that were replaced at build time.
+func (b *Binary) Replaces() []Module {
+	var out []Module
+	for _, m := range b.Deps {
+		if m.Replace != nil {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// pluginPackages picks out the packages that declare both (*Plugin).Init and
+// (*Plugin).Name — the pair endure requires from every RoadRunner plugin.
+// Requiring both keeps unrelated libraries that happen to export a Plugin
+// type with an Init method out of the result.
+func pluginPackages(funcs []string) []string {
+	const (
+		initSuffix = ".(*Plugin).Init"
+		nameSuffix = ".(*Plugin).Name"
+	)
+	var inits, names []string
+	for _, fn := range funcs {
+		switch {
+		case strings.HasSuffix(fn, initSuffix):
+			inits = append(inits, strings.TrimSuffix(fn, initSuffix))
+		case strings.HasSuffix(fn, nameSuffix):
+			names = append(names, strings.TrimSuffix(fn, nameSuffix))
+		}
+	}
+	out := make([]string, 0, len(inits))
+	for _, pkg := range inits {
+		if slices.Contains(names, pkg) && !slices.Contains(out, pkg) {
+			out = append(out, pkg)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
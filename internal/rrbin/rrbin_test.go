@@ -0,0 +1,60 @@
+package rrbin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginPackages(t *testing.T) {
+	funcs := []string{
+		"github.com/roadrunner-server/http/v5.(*Plugin).Init",
+		"github.com/roadrunner-server/http/v5.(*Plugin).Name",
+		"github.com/roadrunner-server/http/v5.(*Plugin).Serve",
+		"github.com/roadrunner-server/informer/v5.(*Plugin).Init",
+		"github.com/roadrunner-server/informer/v5.(*Plugin).Name",
+		// Init without Name: not a RoadRunner plugin.
+		"github.com/some/lib.(*Plugin).Init",
+		"main.main",
+	}
+	require.Equal(t, []string{
+		"github.com/roadrunner-server/http/v5",
+		"github.com/roadrunner-server/informer/v5",
+	}, pluginPackages(funcs))
+}
+
+func TestPlugins_SkipsBundledAndMapsModules(t *testing.T) {
+	b := &Binary{
+		Deps: []Module{
+			{Path: "github.com/roadrunner-server/informer/v5", Version: "v5.1.0"},
+			{Path: "github.com/temporalio/roadrunner-temporal/v5", Version: "v5.2.0"},
+			{Path: "github.com/roadrunner-server/http/v5", Version: "v5.0.3"},
+		},
+		pluginPackages: []string{
+			"github.com/roadrunner-server/http/v5",
+			"github.com/roadrunner-server/informer/v5",
+			"github.com/temporalio/roadrunner-temporal/v5/plugin",
+		},
+	}
+	got := b.Plugins()
+	require.Len(t, got, 2)
+	require.Equal(t, "github.com/roadrunner-server/http/v5", got[0].Module.Path)
+	require.Equal(t, "v5.0.3", got[0].Module.Version)
+	require.Equal(t, "github.com/temporalio/roadrunner-temporal/v5/plugin", got[1].Package)
+	require.Equal(t, "github.com/temporalio/roadrunner-temporal/v5", got[1].Module.Path)
+}
+
+func TestModuleFor_LongestPrefix(t *testing.T) {
+	b := &Binary{Deps: []Module{
+		{Path: "github.com/foo/bar", Version: "v1.0.0"},
+		{Path: "github.com/foo/bar/v2", Version: "v2.3.0"},
+		{Path: "github.com/foo/barbaz", Version: "v0.1.0"},
+	}}
+
+	m, ok := b.ModuleFor("github.com/foo/bar/v2/sub")
+	require.True(t, ok)
+	require.Equal(t, "v2.3.0", m.Version)
+
+	_, ok = b.ModuleFor("github.com/foo/ba")
+	require.False(t, ok)
+}
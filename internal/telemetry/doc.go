@@ -0,0 +1,5 @@
+// Package telemetry configures OpenTelemetry tracing for the vx CLI and the
+// build server. Spans are exported over OTLP/HTTP when the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT (or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT)
+// variable is set; otherwise the global no-op tracer stays in place.
+package telemetry
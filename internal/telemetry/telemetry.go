@@ -0,0 +1,72 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "velox"
+
+// Span attribute keys shared by the builder, the GitHub client and the server.
+const (
+	AttrRRRef        = attribute.Key("velox.rr.ref")
+	AttrPluginCount  = attribute.Key("velox.plugins.count")
+	AttrCacheHit     = attribute.Key("velox.cache.hit")
+	AttrTargetOS     = attribute.Key("velox.target.os")
+	AttrTargetArch   = attribute.Key("velox.target.arch")
+	AttrBuildHash    = attribute.Key("velox.build.hash")
+	AttrArchiveBytes = attribute.Key("velox.archive.bytes")
+	AttrPrincipal    = attribute.Key("velox.principal")
+)
+
+// End records err, if any, on span, marks the span failed, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Enabled reports whether an OTLP endpoint is configured and traces are not
+// switched off with OTEL_TRACES_EXPORTER=none.
+func Enabled() bool {
+	if os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs the global tracer provider and W3C trace context
+// propagator when Enabled. The returned shutdown flushes pending spans; it
+// is safe to call when tracing is disabled. The exporter itself reads the
+// remaining OTEL_EXPORTER_OTLP_* variables (headers, timeout, insecure...).
+func Setup(ctx context.Context, version string) (func(context.Context) error, error) {
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+	exp, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("service.version", version),
+	))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return tp.Shutdown, nil
+}
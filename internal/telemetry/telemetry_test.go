@@ -0,0 +1,115 @@
+package telemetry
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is a minimal OTLP/HTTP trace receiver.
+type collector struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req collectortrace.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			c.spans = append(c.spans, ss.GetSpans()...)
+		}
+	}
+	c.mu.Unlock()
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(nil)
+}
+
+func TestEnabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	assert.False(t, Enabled())
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://localhost:4318/v1/traces")
+	assert.True(t, Enabled())
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	assert.False(t, Enabled())
+}
+
+func TestSetup_ExportsSpans(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+
+	shutdown, err := Setup(t.Context(), "v-test")
+	require.NoError(t, err)
+
+	ctx, parent := otel.Tracer("test").Start(t.Context(), "Build")
+	parent.SetAttributes(AttrRRRef.String("v2025.1.0"), AttrCacheHit.Bool(false))
+	_, child := otel.Tracer("test").Start(ctx, "compile")
+	End(child, errors.New("exit status 1"))
+	End(parent, nil)
+	require.NoError(t, shutdown(t.Context()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	byName := map[string]*tracepb.Span{}
+	for _, s := range c.spans {
+		byName[s.GetName()] = s
+	}
+	require.Contains(t, byName, "Build")
+	require.Contains(t, byName, "compile")
+
+	build, compile := byName["Build"], byName["compile"]
+	assert.Equal(t, build.GetSpanId(), compile.GetParentSpanId())
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, compile.GetStatus().GetCode())
+	assert.Equal(t, "exit status 1", compile.GetStatus().GetMessage())
+	assert.NotEqual(t, tracepb.Status_STATUS_CODE_ERROR, build.GetStatus().GetCode())
+
+	attrs := map[string]string{}
+	for _, kv := range build.GetAttributes() {
+		attrs[kv.GetKey()] = kv.GetValue().String()
+	}
+	assert.Contains(t, attrs[string(AttrRRRef)], "v2025.1.0")
+	assert.Contains(t, attrs, string(AttrCacheHit))
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	shutdown, err := Setup(t.Context(), "v-test")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(t.Context()))
+}
@@ -0,0 +1,96 @@
+package velox
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// tomlKeyLines maps every key and table header in a TOML document to its
+// 1-based line number. Keys use the same dotted form as Diagnostic.Key and
+// are lowercased, because viper lowercases keys on read: plugins.http.tag,
+// replaces[1].new, github.token.token. Tables map to their header line.
+//
+// This is a line scanner, not a TOML parser: it understands [table],
+// [[array]] and key = value lines, which is all velox.toml uses. Multi-line
+// values only record their first line.
+func tomlKeyLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	arrays := map[string]int{}
+	prefix := ""
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[["):
+			name := tomlKey(strings.TrimSuffix(strings.TrimPrefix(line, "[["), "]]"))
+			prefix = name + "[" + strconv.Itoa(arrays[name]) + "]"
+			arrays[name]++
+			lines[prefix] = n
+		case strings.HasPrefix(line, "["):
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			prefix = tomlKey(line[1:end])
+			if _, seen := lines[prefix]; !seen {
+				lines[prefix] = n
+			}
+		default:
+			key, _, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			full := tomlKey(key)
+			if prefix != "" {
+				full = prefix + "." + full
+			}
+			lines[full] = n
+		}
+	}
+	return lines
+}
+
+// tomlKey normalizes a (possibly dotted, possibly quoted) TOML key.
+func tomlKey(raw string) string {
+	parts := strings.Split(strings.TrimSpace(raw), ".")
+	for i, p := range parts {
+		parts[i] = strings.ToLower(strings.Trim(strings.TrimSpace(p), `"'`))
+	}
+	return strings.Join(parts, ".")
+}
+
+// lineFor returns the line of key. A key that only exists through its
+// children (e.g. "plugin" for a [plugin.http] typo) maps to its first child;
+// a missing key falls back to its closest enclosing table so that it still
+// points at the right section.
+func lineFor(lines map[string]int, key string) int {
+	key = strings.ToLower(key)
+	first := 0
+	for k, n := range lines {
+		if strings.HasPrefix(k, key+".") || strings.HasPrefix(k, key+"[") {
+			if first == 0 || n < first {
+				first = n
+			}
+		}
+	}
+	if _, exact := lines[key]; !exact && first > 0 {
+		return first
+	}
+
+	for k := key; k != ""; {
+		if n, ok := lines[k]; ok {
+			return n
+		}
+		i := strings.LastIndexAny(k, ".[")
+		if i < 0 {
+			return 0
+		}
+		k = k[:i]
+	}
+	return 0
+}
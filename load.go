@@ -0,0 +1,108 @@
+package velox
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// LoadOption configures LoadConfig / ParseConfig.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	strict    bool
+	envPrefix string
+	sets      []override
+}
+
+// WithStrict rejects keys that do not map to a Config field (e.g. a
+// `[plugin.http]` or `modul_name` typo) instead of silently dropping them.
+func WithStrict(strict bool) LoadOption {
+	return func(o *loadOptions) { o.strict = strict }
+}
+
+// LoadConfig reads the velox config at path, unmarshals it and runs
+// Validate. The format is inferred from the file extension (.toml, .yaml,
+// .yml or .json). Files named by `extends` are merged in first; see
+// mergeSettings for the merge rules.
+func LoadConfig(path string, opts ...LoadOption) (*Config, error) {
+	cfg, err := ReadConfig(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReadConfig is LoadConfig without Validate: the merged config exactly as
+// written, with no defaults applied and ${ENV} references left unexpanded.
+func ReadConfig(path string, opts ...LoadOption) (*Config, error) {
+	settings, err := readLayered(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decode(settings, opts)
+}
+
+// ParseConfig is LoadConfig for in-memory data, e.g. a config read from a git
+// revision. name infers the format from its extension; relative `extends`
+// paths are resolved against its directory on disk.
+func ParseConfig(data []byte, name string, opts ...LoadOption) (*Config, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := mergeLayers(data, name, []string{abs})
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := decode(settings, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decode unmarshals merged settings into a Config. It goes through viper so
+// the decoder configuration (weak typing, hooks) matches a plain read.
+func decode(settings map[string]any, opts []LoadOption) (*Config, error) {
+	o := &loadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+	if err := applyOverrides(v, o); err != nil {
+		return nil, err
+	}
+
+	// Unknown keys are collected through Metadata rather than ErrorUnused so
+	// the error can name every offending key in velox.toml's dotted form
+	// (plugins.http.modul_name) instead of mapstructure's plugins[http].
+	var md mapstructure.Metadata
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg, func(dc *mapstructure.DecoderConfig) { dc.Metadata = &md }); err != nil {
+		return nil, err
+	}
+	if o.strict && len(md.Unused) > 0 {
+		keys := make([]string, 0, len(md.Unused))
+		for _, k := range md.Unused {
+			keys = append(keys, normalizeKey(k))
+		}
+		slices.Sort(keys)
+		return nil, fmt.Errorf("unknown config keys: %s", strings.Join(keys, ", "))
+	}
+	return cfg, nil
+}
@@ -0,0 +1,241 @@
+package velox
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// Overlay directives. They are consumed while merging and never reach Config.
+const (
+	// extendsKey names the base file(s) a config inherits from, relative to
+	// the file that declares them: `extends = "base.toml"` or a list.
+	extendsKey = "extends"
+	// removeKey holds a [remove] table deleting inherited entries.
+	removeKey = "remove"
+)
+
+// configFormats are the file extensions LoadConfig accepts.
+var configFormats = []string{"toml", "yaml", "yml", "json"}
+
+// removals is the [remove] table of an overlay:
+//
+//	[remove]
+//	plugins  = ["amqp"]                        # by plugin name
+//	replaces = ["github.com/foo/bar"]          # by replace old
+//	excludes = ["github.com/foo/baz@v1.2.3"]   # module@version, or module for every version
+type removals struct {
+	Plugins  []string `mapstructure:"plugins"`
+	Replaces []string `mapstructure:"replaces"`
+	Excludes []string `mapstructure:"excludes"`
+}
+
+// configFormat returns the viper config type for name, rejecting extensions
+// velox does not support.
+func configFormat(name string) (string, error) {
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	if !slices.Contains(configFormats, format) {
+		return "", fmt.Errorf("%s: unsupported config format %q (want .toml, .yaml, .yml or .json)", name, format)
+	}
+	return format, nil
+}
+
+// readLayered reads the config file at path together with everything it
+// extends and returns the merged raw settings.
+func readLayered(path string, stack []string) (map[string]any, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(stack, abs) {
+		return nil, fmt.Errorf("extends cycle: %s -> %s", strings.Join(stack, " -> "), abs)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return mergeLayers(data, path, append(stack, abs))
+}
+
+// mergeLayers parses one config document and folds it over its bases. The
+// order is fixed: bases are merged left to right, then this document's
+// [remove] table is applied, then its own settings are merged on top.
+// Relative extends paths resolve against the directory of name.
+func mergeLayers(data []byte, name string, stack []string) (map[string]any, error) {
+	format, err := configFormat(name)
+	if err != nil {
+		return nil, err
+	}
+	v := viper.New()
+	v.SetConfigType(format)
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	layer := v.AllSettings()
+
+	var parents []string
+	if err := mapstructure.WeakDecode(layer[extendsKey], &parents); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", name, extendsKey, err)
+	}
+	var rm removals
+	if raw, ok := layer[removeKey]; ok {
+		if err := decodeStrict(raw, &rm); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", name, removeKey, err)
+		}
+	}
+	delete(layer, extendsKey)
+	delete(layer, removeKey)
+
+	merged := map[string]any{}
+	for _, parent := range parents {
+		if !filepath.IsAbs(parent) {
+			parent = filepath.Join(filepath.Dir(name), parent)
+		}
+		base, err := readLayered(parent, stack)
+		if err != nil {
+			return nil, fmt.Errorf("%s: extends: %w", name, err)
+		}
+		mergeSettings(merged, base)
+	}
+	if err := rm.apply(merged); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", name, removeKey, err)
+	}
+	mergeSettings(merged, layer)
+	return merged, nil
+}
+
+// mergeSettings merges src into dst:
+//   - tables (including each [plugins.<name>] entry) merge key by key, so an
+//     overlay can bump only a plugin's tag;
+//   - replaces are keyed by old: a matching entry is overridden in place,
+//     new ones are appended;
+//   - excludes are a set keyed by module@version, appended in order;
+//   - everything else in src overrides dst.
+func mergeSettings(dst, src map[string]any) {
+	for k, sv := range src {
+		switch k {
+		case "replaces":
+			dst[k] = mergeList(asList(dst[k]), asList(sv), func(m map[string]any) string {
+				return fmt.Sprint(m["old"])
+			})
+			continue
+		case "excludes":
+			dst[k] = mergeList(asList(dst[k]), asList(sv), func(m map[string]any) string {
+				return fmt.Sprintf("%v@%v", m["module"], m["version"])
+			})
+			continue
+		}
+
+		sm, srcIsMap := sv.(map[string]any)
+		dm, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			merged := make(map[string]any, len(dm))
+			mergeSettings(merged, dm)
+			mergeSettings(merged, sm)
+			dst[k] = merged
+			continue
+		}
+		dst[k] = sv
+	}
+}
+
+// mergeList overrides entries of dst whose key matches one in src and
+// appends the rest, preserving the order of both lists.
+func mergeList(dst, src []any, key func(map[string]any) string) []any {
+	out := slices.Clone(dst)
+	index := map[string]int{}
+	for i, e := range out {
+		if m, ok := e.(map[string]any); ok {
+			index[key(m)] = i
+		}
+	}
+	for _, e := range src {
+		m, ok := e.(map[string]any)
+		if !ok {
+			out = append(out, e)
+			continue
+		}
+		if i, dup := index[key(m)]; dup {
+			out[i] = m
+			continue
+		}
+		index[key(m)] = len(out)
+		out = append(out, m)
+	}
+	return out
+}
+
+// apply deletes the listed entries from the merged base settings. Removing
+// something the base does not define is an error: it is almost always a
+// typo, and silently keeping the entry would defeat the overlay.
+func (r removals) apply(settings map[string]any) error {
+	plugins, _ := settings["plugins"].(map[string]any)
+	for _, name := range r.Plugins {
+		name = strings.ToLower(name)
+		if _, ok := plugins[name]; !ok {
+			return fmt.Errorf("plugin %q is not defined by the base config", name)
+		}
+		delete(plugins, name)
+	}
+
+	replaces, err := removeFromList(asList(settings["replaces"]), r.Replaces, "replace", func(m map[string]any, old string) bool {
+		return m["old"] == old
+	})
+	if err != nil {
+		return err
+	}
+	excludes, err := removeFromList(asList(settings["excludes"]), r.Excludes, "exclude", func(m map[string]any, target string) bool {
+		mod, ver, hasVersion := strings.Cut(target, "@")
+		return m["module"] == mod && (!hasVersion || m["version"] == ver)
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, list := range map[string][]any{"replaces": replaces, "excludes": excludes} {
+		if len(list) == 0 {
+			delete(settings, k)
+		} else {
+			settings[k] = list
+		}
+	}
+	return nil
+}
+
+func removeFromList(list []any, targets []string, what string, match func(map[string]any, string) bool) ([]any, error) {
+	for _, target := range targets {
+		n := len(list)
+		list = slices.DeleteFunc(list, func(e any) bool {
+			m, ok := e.(map[string]any)
+			return ok && match(m, target)
+		})
+		if len(list) == n {
+			return nil, fmt.Errorf("%s %q is not defined by the base config", what, target)
+		}
+	}
+	return list, nil
+}
+
+func asList(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+// decodeStrict decodes raw into out, rejecting unknown keys.
+func decodeStrict(raw any, out any) error {
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+	return dec.Decode(raw)
+}
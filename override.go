@@ -0,0 +1,214 @@
+package velox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Overrides are applied on top of the merged config files. Precedence, from
+// lowest to highest:
+//
+//  1. the config file and everything it extends;
+//  2. environment variables with the WithEnvPrefix prefix, e.g.
+//     VELOX_ROADRUNNER_REF, VELOX_DEBUG_ENABLED, VELOX_PLUGINS_HTTP_TAG;
+//  3. WithSet assignments (`--set key=value`, then `--plugin`), later ones
+//     winning.
+//
+// ${ENV} references in string values are expanded afterwards, by Validate.
+// Replaces and excludes are lists and cannot be overridden this way; use an
+// extends overlay instead.
+
+// WithEnvPrefix binds every config key to an environment variable named
+// PREFIX_KEY, with dots replaced by underscores and upper-cased.
+func WithEnvPrefix(prefix string) LoadOption {
+	return func(o *loadOptions) { o.envPrefix = strings.ToUpper(prefix) }
+}
+
+// WithSet overrides a single key, e.g. WithSet("plugins.http.tag", "v5.2.0").
+// Plugins that do not exist yet are created.
+func WithSet(key, value string) LoadOption {
+	return func(o *loadOptions) { o.sets = append(o.sets, override{key: strings.ToLower(key), value: value}) }
+}
+
+type override struct {
+	key   string
+	value string
+}
+
+// ParsePluginSpec parses a `name=module@tag` plugin override. The tag may be
+// omitted, in which case it is "latest".
+func ParsePluginSpec(spec string) (name string, p *Plugin, err error) {
+	name, mod, ok := strings.Cut(spec, "=")
+	if !ok || name == "" || mod == "" {
+		return "", nil, fmt.Errorf("plugin %q: want name=module@tag", spec)
+	}
+	path, tag, hasTag := strings.Cut(mod, "@")
+	if !hasTag {
+		tag = latestTag
+	}
+	if path == "" || tag == "" {
+		return "", nil, fmt.Errorf("plugin %q: want name=module@tag", spec)
+	}
+	return strings.ToLower(name), &Plugin{ModuleName: path, Tag: tag}, nil
+}
+
+// applyOverrides binds environment variables and applies WithSet values to v.
+func applyOverrides(v *viper.Viper, o *loadOptions) error {
+	if o.envPrefix != "" {
+		prefix := o.envPrefix + "_"
+		for _, kv := range os.Environ() {
+			name, _, _ := strings.Cut(kv, "=")
+			rest, ok := strings.CutPrefix(name, prefix)
+			if !ok {
+				continue
+			}
+			key, ok := envKey(reflect.TypeFor[Config](), rest)
+			if !ok {
+				if o.strict {
+					return fmt.Errorf("environment variable %s does not match any config key", name)
+				}
+				continue
+			}
+			if err := v.BindEnv(key, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, s := range o.sets {
+		if err := settable(reflect.TypeFor[Config](), s.key); err != nil {
+			return fmt.Errorf("set %s: %w", s.key, err)
+		}
+		v.Set(s.key, s.value)
+	}
+	return nil
+}
+
+// settable checks that key names a scalar reachable from t: a struct field,
+// or any key of a map.
+func settable(t reflect.Type, key string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	head, rest, more := strings.Cut(key, ".")
+	switch t.Kind() { //nolint:exhaustive // scalars handled by default
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if k := fieldKey(t.Field(i)); k != "" && k == head {
+				if !more {
+					return isScalar(t.Field(i).Type)
+				}
+				return settable(t.Field(i).Type, rest)
+			}
+		}
+		return fmt.Errorf("unknown key %q", head)
+	case reflect.Map:
+		if head == "" {
+			return errors.New("empty key")
+		}
+		if !more {
+			return isScalar(t.Elem())
+		}
+		return settable(t.Elem(), rest)
+	case reflect.Slice, reflect.Array:
+		return errors.New("lists cannot be overridden; use an extends overlay")
+	default:
+		return fmt.Errorf("%q is not a table", head)
+	}
+}
+
+func isScalar(t reflect.Type) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() { //nolint:exhaustive // scalars handled by default
+	case reflect.Struct, reflect.Map:
+		return errors.New("not a scalar; set one of its keys")
+	case reflect.Slice, reflect.Array:
+		return errors.New("lists cannot be overridden; use an extends overlay")
+	}
+	return nil
+}
+
+// envKey maps the part of an environment variable name after the prefix to a
+// dotted config key, e.g. TARGET_PLATFORM_OS -> target_platform.os and
+// PLUGINS_HTTP_PROXY_TAG -> plugins.http_proxy.tag. Field names may contain
+// underscores themselves, so the name is matched against the Config type
+// rather than split blindly.
+func envKey(t reflect.Type, rest string) (string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() { //nolint:exhaustive // scalars handled by default
+	case reflect.Struct:
+		for i := range t.NumField() {
+			k := fieldKey(t.Field(i))
+			if k == "" {
+				continue
+			}
+			up := strings.ToUpper(k)
+			if rest == up && isScalar(t.Field(i).Type) == nil {
+				return k, true
+			}
+			if tail, ok := strings.CutPrefix(rest, up+"_"); ok {
+				if sub, ok := envKey(t.Field(i).Type, tail); ok {
+					return k + "." + sub, true
+				}
+			}
+		}
+	case reflect.Map:
+		if isScalar(t.Elem()) == nil {
+			return strings.ToLower(rest), rest != ""
+		}
+		// Shortest map key first: PLUGINS_HTTP_PROXY_TAG tries "http" before
+		// "http_proxy".
+		for i := range len(rest) {
+			if rest[i] != '_' || i == 0 {
+				continue
+			}
+			if sub, ok := envKey(t.Elem(), rest[i+1:]); ok {
+				return strings.ToLower(rest[:i]) + "." + sub, true
+			}
+		}
+	}
+	return "", false
+}
+
+// expandEnv runs os.ExpandEnv over every string reachable from v: struct
+// fields, map values and list elements.
+func expandEnv(v reflect.Value) {
+	switch v.Kind() { //nolint:exhaustive // other kinds hold no strings
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			expandEnv(v.Elem())
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				expandEnv(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			expandEnv(v.Index(i))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			e := v.MapIndex(k)
+			if e.Kind() == reflect.String {
+				v.SetMapIndex(k, reflect.ValueOf(os.ExpandEnv(e.String())).Convert(e.Type()))
+				continue
+			}
+			expandEnv(e)
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(os.ExpandEnv(v.String()))
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package velox
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Profile is a named variant of the build described by a config, selected
+// with `vx build --profile <name>` or BuildRequest.profile. Plugins are
+// defined once under [plugins]; a profile only refers to them by name.
+//
+//	[profiles.minimal]
+//	plugins = ["logger", "server", "http"]
+//
+//	[profiles.full]
+//	disable = ["xdebug"]
+//	race = true
+//	tags = ["otel"]
+type Profile struct {
+	// Plugins, when set, restricts the build to these plugin names.
+	Plugins []string `mapstructure:"plugins"`
+	// Disable drops these plugin names from the build.
+	Disable []string `mapstructure:"disable"`
+	// Debug and Race override the config's build flags when set.
+	Debug *bool `mapstructure:"debug"`
+	Race  *bool `mapstructure:"race"`
+	// TargetPlatform overrides the config's target platform when set.
+	TargetPlatform *TargetPlatform `mapstructure:"target_platform"`
+	// Tags are extra build tags, added to those of the [build] section.
+	Tags []string `mapstructure:"tags"`
+}
+
+// Selection is the part of a Config a single build needs once a profile has
+// been applied.
+type Selection struct {
+	Plugins        map[string]*Plugin
+	Debug          bool
+	Race           bool
+	TargetPlatform TargetPlatform
+	// Tags are the [build] tags followed by the profile's own.
+	Tags []string
+}
+
+// Select applies the named profile to c, which must have been validated. An
+// empty name selects every plugin with the config's own settings.
+func (c *Config) Select(name string) (*Selection, error) {
+	s := &Selection{
+		Plugins: maps.Clone(c.Plugins),
+		Debug:   c.Debug != nil && c.Debug.Enabled,
+	}
+	if c.Build != nil {
+		s.Tags = slices.Clone(c.Build.Tags)
+	}
+	if c.TargetPlatform != nil {
+		s.TargetPlatform = *c.TargetPlatform
+	}
+	if name == "" {
+		return s, nil
+	}
+
+	p, ok := c.Profiles[strings.ToLower(name)]
+	if !ok || p == nil {
+		known := slices.Sorted(maps.Keys(c.Profiles))
+		return nil, fmt.Errorf("unknown profile %q (defined: %s)", name, strings.Join(known, ", "))
+	}
+
+	if len(p.Plugins) > 0 {
+		s.Plugins = make(map[string]*Plugin, len(p.Plugins))
+		for _, n := range p.Plugins {
+			s.Plugins[strings.ToLower(n)] = c.Plugins[strings.ToLower(n)]
+		}
+	}
+	for _, n := range p.Disable {
+		delete(s.Plugins, strings.ToLower(n))
+	}
+	if len(s.Plugins) == 0 {
+		return nil, fmt.Errorf("profile %q selects no plugins", name)
+	}
+
+	if p.Debug != nil {
+		s.Debug = *p.Debug
+	}
+	if p.Race != nil {
+		s.Race = *p.Race
+	}
+	if p.TargetPlatform != nil {
+		if p.TargetPlatform.OS != "" {
+			s.TargetPlatform.OS = p.TargetPlatform.OS
+		}
+		if p.TargetPlatform.Arch != "" {
+			s.TargetPlatform.Arch = p.TargetPlatform.Arch
+		}
+	}
+	for _, t := range p.Tags {
+		if !slices.Contains(s.Tags, t) {
+			s.Tags = append(s.Tags, t)
+		}
+	}
+	return s, nil
+}
+
+// validateProfiles checks that every profile refers to defined plugins only.
+func (c *Config) validateProfiles() error {
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		p := c.Profiles[name]
+		if p == nil {
+			continue
+		}
+		for _, n := range slices.Concat(p.Plugins, p.Disable) {
+			if _, ok := c.Plugins[strings.ToLower(n)]; !ok {
+				return fmt.Errorf("profile %q: plugin %q is not defined under [plugins]", name, n)
+			}
+		}
+		if p.TargetPlatform != nil && strings.EqualFold(p.TargetPlatform.OS, "windows") {
+			return fmt.Errorf("profile %q: %w", name, errWindows)
+		}
+		for _, tag := range p.Tags {
+			if err := ValidateBuildTag(tag); err != nil {
+				return fmt.Errorf("profile %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package velox
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+// Sandbox is the [sandbox] section. When enabled, the go commands of a
+// build run in new Linux user, mount and pid namespaces that see the file
+// system read-only except for the module being built, a private GOCACHE and
+// temp dir, and — for the commands that download — the module cache.
+// `go build` and the smoke test of the built binary also get a network
+// namespace of their own. Where namespaces are not permitted the commands
+// run unsandboxed with a warning, unless Required is set; the smoke test is
+// then skipped. Sandboxed commands inherit only the environment variables
+// the go command and the C toolchain use, not the server's credentials.
+//
+//	[sandbox]
+//	enabled = true
+//	memory_mb = 4096
+//	cpu_seconds = 900
+//	disk_mb = 4096
+type Sandbox struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Required fails builds the sandbox cannot be set up for instead of
+	// running them unsandboxed.
+	Required bool `mapstructure:"required"`
+	// CPUSeconds caps the CPU time of each process (RLIMIT_CPU).
+	CPUSeconds int `mapstructure:"cpu_seconds"`
+	// CPUs caps the cores a command's process tree may use (cgroup cpu.max).
+	CPUs int `mapstructure:"cpus"`
+	// MemoryMB caps memory: the cgroup's memory.max, or each process's
+	// address space (RLIMIT_AS) without a cgroup.
+	MemoryMB int `mapstructure:"memory_mb"`
+	// DiskMB caps the size of every file written (RLIMIT_FSIZE) and of the
+	// tmpfs holding the private GOCACHE and temp dir.
+	DiskMB int `mapstructure:"disk_mb"`
+	// Processes caps the processes of a command (cgroup pids.max).
+	Processes int `mapstructure:"processes"`
+	// CgroupParent is a cgroup v2 directory delegated to velox, e.g.
+	// /sys/fs/cgroup/velox.slice; each command gets a child cgroup there.
+	// Without it, CPUs and Processes are not enforced.
+	CgroupParent string `mapstructure:"cgroup_parent"`
+}
+
+// Validate checks that the limits are not negative.
+func (s *Sandbox) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.CPUSeconds < 0 || s.CPUs < 0 || s.MemoryMB < 0 || s.DiskMB < 0 || s.Processes < 0 {
+		return errors.New("sandbox: limits must not be negative")
+	}
+	if s.CgroupParent != "" && !filepath.IsAbs(s.CgroupParent) {
+		return errors.New("sandbox.cgroup_parent must be an absolute path")
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package velox
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing velox.toml,
+// generated from the Config struct and its mapstructure tags. Editors such
+// as Taplo / Even Better TOML pick it up through a `#:schema` comment at the
+// top of the file. Unknown keys are rejected (additionalProperties: false),
+// matching the strict loader.
+func JSONSchema() ([]byte, error) {
+	root := schemaFor(reflect.TypeFor[Config](), "")
+	root["$schema"] = schemaDraft
+	root["title"] = "velox configuration"
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaFor builds the schema for t. path is the dotted config path of the
+// value, used to attach descriptions and hand-written constraints.
+func schemaFor(t reflect.Type, path string) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var s map[string]any
+	switch t.Kind() { //nolint:exhaustive // only the kinds Config uses
+	case reflect.Struct:
+		props := map[string]any{}
+		for i := range t.NumField() {
+			key := fieldKey(t.Field(i))
+			if key == "" {
+				continue
+			}
+			props[key] = schemaFor(t.Field(i).Type, join(path, key))
+		}
+		s = map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+		if req := requiredKeys(t); len(req) > 0 {
+			s["required"] = req
+		}
+	case reflect.Map:
+		s = map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), join(path, "*"))}
+	case reflect.Slice, reflect.Array:
+		s = map[string]any{"type": "array", "items": schemaFor(t.Elem(), path+"[]")}
+	case reflect.Bool:
+		s = map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = map[string]any{"type": "integer"}
+	default:
+		s = map[string]any{"type": "string"}
+	}
+
+	decorate(path, s)
+	return s
+}
+
+// requiredKeys lists the keys Validate insists on for each struct type.
+func requiredKeys(t reflect.Type) []string {
+	switch t {
+	case reflect.TypeFor[Plugin]():
+		return []string{"module_name", "tag"}
+	case reflect.TypeFor[Replace]():
+		return []string{"new", "old"}
+	case reflect.TypeFor[Exclude]():
+		return []string{"module", "version"}
+	}
+	return nil
+}
+
+// decorate adds descriptions and constraints that reflection cannot see.
+func decorate(path string, s map[string]any) {
+	switch path {
+	case "":
+		props := s["properties"].(map[string]any)
+		props[extendsKey] = map[string]any{
+			"description": "Base config file(s) to inherit from, relative to this file.",
+			"oneOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+		}
+		list := func(desc string) map[string]any {
+			return map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": desc}
+		}
+		props[removeKey] = map[string]any{
+			"type":                 "object",
+			"description":          "Entries inherited through extends to drop.",
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"plugins":  list("Plugin names."),
+				"replaces": list("Replace old module paths."),
+				"excludes": list("module@version, or module to drop every version."),
+			},
+		}
+	case "roadrunner":
+		s["description"] = "RoadRunner source to build from."
+		s["properties"] = map[string]any{
+			ref: map[string]any{"type": "string", "description": "Tag, branch or commit SHA. Defaults to master."},
+		}
+	case "debug":
+		s["description"] = "Debug build: no optimization or inlining, debug tag."
+	case "log":
+		s["description"] = "Logger used by velox itself."
+		s["properties"] = map[string]any{
+			LogLevelKey: map[string]any{"type": "string", "enum": []string{"debug", "info", "warn", "error"}},
+			LogModeKey: map[string]any{
+				"type": "string",
+				"enum": []string{"development", "production", "raw", "none", "off"},
+			},
+		}
+	case "target_platform":
+		s["description"] = "GOOS/GOARCH to cross-compile for. Defaults to the host."
+	case "target_platform.os":
+		s["not"] = map[string]any{"const": "windows"}
+	case "github":
+		s["description"] = "GitHub (or GitHub Enterprise) access used to download RoadRunner."
+	case "github.token.token":
+		s["description"] = "Access token; ${ENV} references are expanded."
+	case "plugins":
+		s["description"] = "User plugins compiled into the binary, keyed by a free-form name."
+		s["minProperties"] = 1
+	case "plugins.*.module_name":
+		s["description"] = "Go module path of the plugin, e.g. github.com/roadrunner-server/http/v5."
+	case "plugins.*.tag":
+		s["description"] = `Semantic version, pseudo-version or "latest".`
+		s["pattern"] = `^(latest|v\d+\.\d+\.\d+.*)$`
+	case "replaces":
+		s["description"] = "go.mod replace directives applied before go mod tidy."
+	case "replaces[].new":
+		s["description"] = "module@version, or a local path (./, ../, /abs) without @version."
+	case "excludes":
+		s["description"] = "go.mod exclude directives applied before go mod tidy."
+	case "build":
+		s["description"] = "Extra go build flags. -ldflags and -gcflags are merged into velox's own values."
+	case "build.vars":
+		s["description"] = "-X importpath.name=value assignments; values without whitespace or quotes."
+	case "build.gcflags":
+		s["description"] = "Compiler flags applied to all packages, e.g. [\"-B\"]."
+	case "build.pgo":
+		s["description"] = `"auto", "off" or the path of a CPU profile.`
+	case "build.buildmode":
+		s["enum"] = []string{"exe", "default", "pie"}
+	case "build.trimpath":
+		s["description"] = "Remove file system paths from the binary. Defaults to true."
+	case "cgo":
+		s["description"] = "cgo support. Builds use CGO_ENABLED=0 unless enabled here or race is on."
+	case "cgo.static":
+		s["description"] = "Link statically (-linkmode external -extldflags -static). Linux targets only."
+	case "cgo.toolchains":
+		s["description"] = "C toolchains keyed by <goos>_<goarch>, e.g. linux_arm64. Required for cross-compiling with cgo."
+		s["propertyNames"] = map[string]any{"pattern": "^[a-z0-9]+_[a-z0-9]+$"}
+	case "smoke.exec_wrappers":
+		s["description"] = "Commands that run foreign-arch linux binaries for the smoke test, keyed by <goos>_<goarch>. " +
+			"{binary} marks the binary's position; otherwise it is appended."
+		s["propertyNames"] = map[string]any{"pattern": "^linux_[a-z0-9]+$"}
+	case "go_version":
+		s["description"] = "Go release used for builds, e.g. 1.26.1. Selected through GOTOOLCHAIN unless go_toolchain_dir provides it."
+		s["pattern"] = `^(go)?1\.[0-9]+(\.[0-9]+|(rc|beta)[0-9]+)?$`
+	case "go_toolchain_dir":
+		s["description"] = "Directory of installed Go toolchains laid out as <dir>/go<version>/bin/go."
+	case "server":
+		s["description"] = "Settings of `vx server`; ignored by the other commands."
+	case "server.tls":
+		s["description"] = "HTTPS for the server. Files are reloaded on change and on SIGHUP. Without a certificate the server speaks h2c."
+	case "server.tls.client_auth":
+		s["enum"] = []string{ClientAuthVerifyIfGiven, ClientAuthRequire}
+	case "server.tls.min_version":
+		s["enum"] = []string{"1.2", "1.3"}
+	case "server.modules":
+		s["description"] = "Module sources allowed for every caller. Local-path replaces are rejected unless allow_local_replaces is set."
+	case "server.modules.plugins", "server.modules.replace_targets", "server.modules.excludes":
+		s["description"] = "Module path globs (* matches anything). deny wins; a non-empty allow admits only what it matches."
+	case "server.limits", "server.policies.*.limits":
+		s["description"] = "Per-caller throttling, keyed by principal or by remote IP for anonymous callers. " +
+			"Over-limit requests fail with resource_exhausted and a Retry-After hint; 0 leaves a limit off."
+	case "server.limits.daily_cpu_seconds", "server.policies.*.limits.daily_cpu_seconds":
+		s["description"] = "CPU time of build subprocesses allowed per caller and UTC day."
+	case "server.history":
+		s["description"] = "Build history served by ListBuilds/GetBuild. Without path it is kept in memory."
+	case "server.history.path":
+		s["description"] = "bbolt database file holding the build history."
+	case "server.auth":
+		s["description"] = "Authentication. Without it the server accepts every caller."
+	case "server.auth.tokens":
+		s["description"] = "Static bearer tokens keyed by principal name; ${ENV} references are expanded."
+	case "server.auth.client_certs":
+		s["description"] = "Verified TLS client certificates, matched by subject common name, keyed by principal name."
+	case "server.auth.jwt.jwks_file":
+		s["description"] = "Local JWKS file with the keys bearer JWTs are verified against."
+	case "server.auth.anonymous_policy":
+		s["description"] = "Policy of requests without credentials; empty rejects them."
+	case "server.policies":
+		s["description"] = "Per-principal restrictions. Lists are allow-lists of globs (* matches anything); " +
+			"an omitted list allows everything, except replace_targets, without which replaces are rejected."
+	case "sandbox":
+		s["description"] = "Run go commands in Linux namespaces with a read-only view of the host and resource limits. " +
+			"Falls back to running unsandboxed, with a warning, where namespaces are not permitted unless required is set."
+	case "sandbox.cgroup_parent":
+		s["description"] = "Delegated cgroup v2 directory for per-command cgroups; needed for cpus and processes."
+	case "profiles":
+		s["description"] = "Named build variants selected with `vx build --profile`."
+	case "profiles.*.plugins":
+		s["description"] = "Restrict the build to these plugin names from [plugins]."
+	case "profiles.*.disable":
+		s["description"] = "Drop these plugin names from the build."
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,400 @@
+package velox
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Server is the [server] section, read by `vx server` only.
+//
+//	[server.auth.tokens.ci]
+//	token = "${CI_BUILD_TOKEN}"
+//	policy = "ci"
+//
+//	[server.policies.ci]
+//	rr_versions = ["v2025.*"]
+//	plugin_prefixes = ["github.com/roadrunner-server/"]
+//	platforms = ["linux/*"]
+type Server struct {
+	// TLS serves HTTPS instead of h2c.
+	TLS *ServerTLS `mapstructure:"tls"`
+	// Auth enables authentication; without it every caller is accepted.
+	Auth *Auth `mapstructure:"auth"`
+	// Policies are named sets of restrictions principals are bound to.
+	Policies map[string]*Policy `mapstructure:"policies"`
+	// Modules restricts module sources for every caller, authenticated or
+	// not. Without it, local-path replaces are still rejected.
+	Modules *ModulePolicy `mapstructure:"modules"`
+	// Limits throttles each caller; a policy's own limits take precedence.
+	Limits *Limits `mapstructure:"limits"`
+	// History configures the build history served by ListBuilds/GetBuild.
+	History *History `mapstructure:"history"`
+}
+
+// History is where the build server records its builds, and for how long.
+//
+//	[server.history]
+//	path = "/var/lib/velox/history.db"
+//	max_age_days = 90
+type History struct {
+	// Path is a bbolt database file. Without it the history is kept in
+	// memory, lost on restart, and capped at DefaultHistoryRecords builds
+	// unless MaxRecords says otherwise.
+	Path string `mapstructure:"path"`
+	// MaxAgeDays drops builds older than this many days.
+	MaxAgeDays int `mapstructure:"max_age_days"`
+	// MaxRecords keeps only the newest builds.
+	MaxRecords int `mapstructure:"max_records"`
+}
+
+// DefaultHistoryRecords caps an in-memory build history.
+const DefaultHistoryRecords = 10000
+
+func (h *History) validate() error {
+	if h != nil && (h.MaxAgeDays < 0 || h.MaxRecords < 0) {
+		return errors.New("server.history: max_age_days and max_records must not be negative")
+	}
+	return nil
+}
+
+// Limits throttles the Build requests of one caller: a principal, or the
+// remote IP when the caller is anonymous. Zero leaves a limit off.
+//
+//	[server.limits]
+//	requests_per_minute = 30
+//	burst = 10
+//	max_concurrent_builds = 2
+//	daily_cpu_seconds = 7200
+type Limits struct {
+	// RequestsPerMinute refills a token bucket of Burst requests.
+	RequestsPerMinute int `mapstructure:"requests_per_minute"`
+	// Burst defaults to RequestsPerMinute.
+	Burst int `mapstructure:"burst"`
+	// MaxConcurrentBuilds caps the builds running at once; cache hits
+	// don't count.
+	MaxConcurrentBuilds int `mapstructure:"max_concurrent_builds"`
+	// DailyCPUSeconds is the CPU time the caller's build subprocesses may
+	// use per UTC day. A build that starts under quota runs to completion.
+	DailyCPUSeconds int `mapstructure:"daily_cpu_seconds"`
+}
+
+func (l *Limits) validate(key string) error {
+	if l == nil {
+		return nil
+	}
+	switch {
+	case l.RequestsPerMinute < 0, l.Burst < 0, l.MaxConcurrentBuilds < 0, l.DailyCPUSeconds < 0:
+		return fmt.Errorf("%s: limits must not be negative", key)
+	case l.Burst > 0 && l.RequestsPerMinute == 0:
+		return fmt.Errorf("%s: burst requires requests_per_minute", key)
+	}
+	if l.Burst == 0 {
+		l.Burst = l.RequestsPerMinute
+	}
+	return nil
+}
+
+// ModulePolicy restricts the modules any build request may use.
+//
+//	[server.modules]
+//	allow_local_replaces = false
+//
+//	[server.modules.plugins]
+//	allow = ["github.com/roadrunner-server/*", "github.com/acme/*"]
+//
+//	[server.modules.replace_targets]
+//	deny = ["github.com/evil/*"]
+type ModulePolicy struct {
+	// AllowLocalReplaces permits replaces pointing at a directory on the
+	// server. Off by default: it lets callers compile whatever is on disk.
+	AllowLocalReplaces bool `mapstructure:"allow_local_replaces"`
+	// Plugins match plugin module paths.
+	Plugins *GlobList `mapstructure:"plugins"`
+	// ReplaceTargets match the module path (without @version) a replace
+	// points at; local paths are governed by AllowLocalReplaces alone.
+	ReplaceTargets *GlobList `mapstructure:"replace_targets"`
+	// Excludes match the module paths of exclude directives.
+	Excludes *GlobList `mapstructure:"excludes"`
+}
+
+// GlobList is an allow/deny pair of glob lists (see MatchGlob). Deny wins;
+// a non-empty Allow admits only what it matches.
+type GlobList struct {
+	Allow []string `mapstructure:"allow"`
+	Deny  []string `mapstructure:"deny"`
+}
+
+// Permits reports whether s passes the list. A nil list permits anything.
+func (g *GlobList) Permits(s string) bool {
+	if g == nil {
+		return true
+	}
+	if MatchAnyGlob(g.Deny, s) {
+		return false
+	}
+	return len(g.Allow) == 0 || MatchAnyGlob(g.Allow, s)
+}
+
+func (m *ModulePolicy) validate() error {
+	if m == nil {
+		return nil
+	}
+	for _, l := range []struct {
+		key string
+		g   *GlobList
+	}{{"plugins", m.Plugins}, {"replace_targets", m.ReplaceTargets}, {"excludes", m.Excludes}} {
+		if l.g != nil && (slices.Contains(l.g.Allow, "") || slices.Contains(l.g.Deny, "")) {
+			return fmt.Errorf("server.modules.%s: empty glob", l.key)
+		}
+	}
+	return nil
+}
+
+// ServerTLS configures HTTPS for `vx server`. The certificate, key and
+// client CA files are reloaded when they change or on SIGHUP.
+//
+//	[server.tls]
+//	cert_file = "/etc/velox/tls.crt"
+//	key_file = "/etc/velox/tls.key"
+//	client_ca_file = "/etc/velox/clients-ca.crt"
+type ServerTLS struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ClientCAFile enables mTLS: client certificates signed by these CAs
+	// are verified and can authenticate through [server.auth.client_certs].
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// ClientAuth is "verify_if_given" (default: callers may still use
+	// tokens) or "require" (every connection needs a client certificate).
+	ClientAuth string `mapstructure:"client_auth"`
+	// MinVersion is "1.2" (default) or "1.3".
+	MinVersion string `mapstructure:"min_version"`
+}
+
+// TLS client auth modes of ServerTLS.ClientAuth.
+const (
+	ClientAuthVerifyIfGiven = "verify_if_given"
+	ClientAuthRequire       = "require"
+)
+
+// Enabled reports whether a certificate is configured.
+func (t *ServerTLS) Enabled() bool { return t != nil && t.CertFile != "" }
+
+// Validate checks that the settings are complete and known.
+func (t *ServerTLS) Validate() error {
+	if t == nil {
+		return nil
+	}
+	switch {
+	case (t.CertFile == "") != (t.KeyFile == ""):
+		return errors.New("server.tls: cert_file and key_file must be set together")
+	case t.ClientCAFile != "" && t.CertFile == "":
+		return errors.New("server.tls: client_ca_file requires cert_file and key_file")
+	case t.ClientAuth != "" && t.ClientCAFile == "":
+		return errors.New("server.tls: client_auth requires client_ca_file to verify client certificates against")
+	}
+	switch t.ClientAuth {
+	case "", ClientAuthVerifyIfGiven, ClientAuthRequire:
+	default:
+		return fmt.Errorf("server.tls.client_auth: %q is not %s or %s", t.ClientAuth, ClientAuthVerifyIfGiven, ClientAuthRequire)
+	}
+	switch t.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		return fmt.Errorf("server.tls.min_version: %q is not 1.2 or 1.3", t.MinVersion)
+	}
+	return nil
+}
+
+// Auth configures how the build server identifies callers. A request is
+// matched, in order, by its bearer token (a static token, then a JWT), by
+// its verified TLS client certificate, and finally as anonymous.
+type Auth struct {
+	// Tokens are static bearer tokens keyed by principal name.
+	Tokens map[string]*AuthToken `mapstructure:"tokens"`
+	// ClientCerts map TLS client certificates to principals, keyed by
+	// principal name. They only apply when the server verifies client
+	// certificates.
+	ClientCerts map[string]*AuthClientCert `mapstructure:"client_certs"`
+	// JWT enables bearer JWTs signed by a key from a local JWKS file.
+	JWT *AuthJWT `mapstructure:"jwt"`
+	// AnonymousPolicy is the policy of requests without credentials; when
+	// empty they are rejected.
+	AnonymousPolicy string `mapstructure:"anonymous_policy"`
+}
+
+// AuthToken is a static bearer token.
+type AuthToken struct {
+	Token  string `mapstructure:"token"`
+	Policy string `mapstructure:"policy"`
+}
+
+// AuthClientCert matches a verified client certificate by subject common
+// name.
+type AuthClientCert struct {
+	CommonName string `mapstructure:"common_name"`
+	Policy     string `mapstructure:"policy"`
+}
+
+// AuthJWT verifies bearer JWTs against a local JWKS file.
+type AuthJWT struct {
+	JWKSFile string `mapstructure:"jwks_file"`
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+	// PrincipalClaim names the claim identifying the caller; default "sub".
+	PrincipalClaim string `mapstructure:"principal_claim"`
+	// PolicyClaim names the claim selecting the caller's policy; default
+	// "velox_policy". Tokens without it get DefaultPolicy.
+	PolicyClaim   string `mapstructure:"policy_claim"`
+	DefaultPolicy string `mapstructure:"default_policy"`
+}
+
+// Policy restricts what a principal may build. Every list is an allow-list
+// of globs (see MatchGlob); an omitted list places no restriction, except
+// ReplaceTargets: a replace can point a build at arbitrary code, so without
+// it replaces are rejected. An empty TOML table is dropped when the config
+// is loaded, so an otherwise unrestricted policy is written as
+// rr_versions = ["*"].
+type Policy struct {
+	// RRVersions match BuildRequest.rr_version, e.g. "v2025.*".
+	RRVersions []string `mapstructure:"rr_versions"`
+	// PluginPrefixes are module path prefixes plugins must start with.
+	PluginPrefixes []string `mapstructure:"plugin_prefixes"`
+	// ReplaceTargets match the `new` side of replaces, e.g.
+	// "github.com/acme/*". Local paths are matched as written.
+	ReplaceTargets []string `mapstructure:"replace_targets"`
+	// Platforms match "<goos>/<goarch>", e.g. "linux/*".
+	Platforms []string `mapstructure:"platforms"`
+	// Limits replace [server.limits] for principals bound to the policy.
+	Limits *Limits `mapstructure:"limits"`
+	// ViewAllBuilds lets ListBuilds and GetBuild return every caller's
+	// builds instead of only the principal's own. Anonymous callers
+	// cannot tell their builds apart and see none without it.
+	ViewAllBuilds bool `mapstructure:"view_all_builds"`
+}
+
+// Validate checks the TLS settings and limits, that every principal refers
+// to a defined policy, and that credentials are complete and unambiguous.
+func (s *Server) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if err := s.TLS.Validate(); err != nil {
+		return err
+	}
+	if err := s.Modules.validate(); err != nil {
+		return err
+	}
+	if err := s.Limits.validate("server.limits"); err != nil {
+		return err
+	}
+	if err := s.History.validate(); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Policies)) {
+		if s.Policies[name] == nil {
+			s.Policies[name] = &Policy{}
+		}
+		if err := s.Policies[name].Limits.validate("server.policies." + name + ".limits"); err != nil {
+			return err
+		}
+	}
+	return s.Auth.validate(s.Policies)
+}
+
+func (a *Auth) validate(policies map[string]*Policy) error {
+	if a == nil {
+		return nil
+	}
+	policy := func(key, name string) error {
+		if _, ok := policies[name]; !ok {
+			return fmt.Errorf("%s: unknown policy %q", key, name)
+		}
+		return nil
+	}
+	if a.AnonymousPolicy != "" {
+		if err := policy("server.auth.anonymous_policy", a.AnonymousPolicy); err != nil {
+			return err
+		}
+	}
+
+	tokens := make(map[string]string, len(a.Tokens))
+	for _, name := range slices.Sorted(maps.Keys(a.Tokens)) {
+		key := "server.auth.tokens." + name
+		t := a.Tokens[name]
+		switch {
+		case t == nil || t.Token == "":
+			return fmt.Errorf("%s: token is required", key)
+		case tokens[t.Token] != "":
+			return fmt.Errorf("%s: same token as server.auth.tokens.%s", key, tokens[t.Token])
+		}
+		tokens[t.Token] = name
+		if err := policy(key, t.Policy); err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]string, len(a.ClientCerts))
+	for _, name := range slices.Sorted(maps.Keys(a.ClientCerts)) {
+		key := "server.auth.client_certs." + name
+		c := a.ClientCerts[name]
+		switch {
+		case c == nil || c.CommonName == "":
+			return fmt.Errorf("%s: common_name is required", key)
+		case names[c.CommonName] != "":
+			return fmt.Errorf("%s: same common_name as server.auth.client_certs.%s", key, names[c.CommonName])
+		}
+		names[c.CommonName] = name
+		if err := policy(key, c.Policy); err != nil {
+			return err
+		}
+	}
+
+	if j := a.JWT; j != nil {
+		if j.JWKSFile == "" {
+			return errors.New("server.auth.jwt: jwks_file is required")
+		}
+		if j.PrincipalClaim == "" {
+			j.PrincipalClaim = "sub"
+		}
+		if j.PolicyClaim == "" {
+			j.PolicyClaim = "velox_policy"
+		}
+		if j.DefaultPolicy != "" {
+			if err := policy("server.auth.jwt.default_policy", j.DefaultPolicy); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MatchGlob reports whether s matches pattern, in which "*" matches any
+// run of characters, "/" included, and everything else matches itself.
+func MatchGlob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, p)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(p):]
+	}
+	return strings.HasSuffix(s, last)
+}
+
+// MatchAnyGlob reports whether s matches one of patterns.
+func MatchAnyGlob(patterns []string, s string) bool {
+	return slices.ContainsFunc(patterns, func(p string) bool { return MatchGlob(p, s) })
+}
@@ -0,0 +1,48 @@
+package velox
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// BinaryPlaceholder marks where an exec wrapper template takes the path of
+// the binary under test; without it the path is appended.
+const BinaryPlaceholder = "{binary}"
+
+// Smoke is the [smoke] section: how the post-build smoke test runs binaries
+// built for a foreign architecture.
+//
+//	[smoke.exec_wrappers]
+//	linux_arm64 = "qemu-aarch64-static -L /usr/aarch64-linux-gnu"
+type Smoke struct {
+	// ExecWrappers maps "<goos>_<goarch>" to the command that runs such a
+	// binary on the build host. Only Linux targets can be wrapped. Without a
+	// wrapper, a binfmt_misc handler registered for the target is used.
+	ExecWrappers map[string]string `mapstructure:"exec_wrappers"`
+}
+
+// ExecWrapper returns the wrapper template configured for goos/goarch, or "".
+func (s *Smoke) ExecWrapper(goos, goarch string) string {
+	if s == nil {
+		return ""
+	}
+	return s.ExecWrappers[PlatformKey(goos, goarch)]
+}
+
+// Validate checks the configured wrappers.
+func (s *Smoke) Validate() error {
+	if s == nil {
+		return nil
+	}
+	for _, key := range slices.Sorted(maps.Keys(s.ExecWrappers)) {
+		switch {
+		case !strings.HasPrefix(key, "linux_"):
+			return fmt.Errorf("smoke.exec_wrappers.%s: only linux targets can be run through a wrapper", key)
+		case strings.TrimSpace(s.ExecWrappers[key]) == "":
+			return fmt.Errorf("smoke.exec_wrappers.%s: empty command", key)
+		}
+	}
+	return nil
+}